@@ -195,6 +195,12 @@ func translateAndroidModule(ctx SingletonContext, w io.Writer, mod blueprint.Mod
 
 	data := provider.AndroidMk()
 
+	// The variants of an overridable module created for override modules are known to Make by
+	// the names of the override modules.
+	if o, ok := mod.(OverridableModule); ok && o.GetOverriddenBy() != "" {
+		name = o.GetOverriddenBy()
+	}
+
 	if data.Include == "" {
 		data.Include = "$(BUILD_PREBUILT)"
 	}
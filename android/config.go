@@ -112,6 +112,10 @@ type config struct {
 
 	stopBefore bootstrap.StopBefore
 
+	moduleGraphFile string // the path to write the resolved module graph to, see module_graph.go
+
+	neverallowPolicyList string // the path to the list of neverallow.bp files, see neverallow_policy.go
+
 	OncePer
 }
 
@@ -362,6 +366,27 @@ func (c *config) SetStopBefore(stopBefore bootstrap.StopBefore) {
 
 var _ bootstrap.ConfigStopBefore = (*config)(nil)
 
+func (c *config) ModuleGraphFile() string {
+	return c.moduleGraphFile
+}
+
+// SetModuleGraphFile enables recording the dependencies of each module so that the resolved module
+// graph can be written to moduleGraphFile by WriteModuleGraph after the build actions have been
+// generated.
+func (c *config) SetModuleGraphFile(moduleGraphFile string) {
+	c.moduleGraphFile = moduleGraphFile
+}
+
+func (c *config) NeverallowPolicyList() string {
+	return c.neverallowPolicyList
+}
+
+// SetNeverallowPolicyList sets the path to the list of neverallow.bp policy files written by
+// soong_ui, which are read by the neverallow mutator.
+func (c *config) SetNeverallowPolicyList(neverallowPolicyList string) {
+	c.neverallowPolicyList = neverallowPolicyList
+}
+
 func (c *config) BlueprintToolLocation() string {
 	return filepath.Join(c.buildDir, "host", c.PrebuiltOS(), "bin")
 }
@@ -691,6 +716,12 @@ func (c *config) UseGoma() bool {
 	return Bool(c.productVariables.UseGoma)
 }
 
+// UseRemoteExec returns true if RuleBuilder rules and genrules should be run through the remote
+// execution client, see remote_exec.go.
+func (c *config) UseRemoteExec() bool {
+	return c.IsEnvTrue("USE_REMOTE_EXEC")
+}
+
 func (c *config) RunErrorProne() bool {
 	return c.IsEnvTrue("RUN_ERROR_PRONE")
 }
@@ -878,6 +909,12 @@ func (c *deviceConfig) PgoAdditionalProfileDirs() []string {
 	return c.config.productVariables.PgoAdditionalProfileDirs
 }
 
+// LinkerConfigExtraLinks returns the JSON files that add links between linker namespaces to the
+// generated linker configuration.
+func (c *deviceConfig) LinkerConfigExtraLinks() []string {
+	return c.config.productVariables.LinkerConfigExtraLinks
+}
+
 func (c *deviceConfig) VendorSepolicyDirs() []string {
 	return c.config.productVariables.BoardVendorSepolicyDirs
 }
@@ -148,6 +148,18 @@ func defaultsMutator(ctx TopDownMutatorContext) {
 			}
 			return false
 		})
+
+		// A module's own visibility replaces the visibility of its defaults rather than being
+		// combined with it, so that e.g. a module can be made private when its defaults are not.
+		var visibility []string
+		if m, ok := ctx.Module().(Module); ok {
+			visibility = m.base().commonProperties.Visibility
+		}
+
 		defaultable.applyDefaults(ctx, defaultsList)
+
+		if visibility != nil {
+			ctx.Module().(Module).base().commonProperties.Visibility = visibility
+		}
 	}
 }
@@ -15,8 +15,11 @@
 package android
 
 import (
+	"strings"
+
 	"github.com/google/blueprint"
 	_ "github.com/google/blueprint/bootstrap"
+	"github.com/google/blueprint/proptools"
 )
 
 var (
@@ -101,6 +104,14 @@ var (
 	localPool = blueprint.NewBuiltinPool("local_pool")
 )
 
+// WriteFileContent escapes content for the "content" argument of the WriteFile rule, which passes it
+// through ninja, a single quoted shell string and echo -e.  Newlines are escaped for echo -e, as ninja
+// variables can't contain them.
+func WriteFileContent(content string) string {
+	content = strings.NewReplacer(`\`, `\\`, `'`, `'\''`, "\n", `\n`).Replace(content)
+	return proptools.NinjaEscape(content)
+}
+
 func init() {
 	pctx.Import("github.com/google/blueprint/bootstrap")
 }
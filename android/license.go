@@ -0,0 +1,72 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+func init() {
+	RegisterModuleType("license", LicenseFactory)
+}
+
+type licenseProperties struct {
+	// The kinds of the license, names of license_kind modules.
+	License_kinds []string
+
+	// The copyright notice of the code the license applies to.
+	Copyright_notice *string
+
+	// The name of the package the license applies to, used to group the license in NOTICE files.
+	Package_name *string
+
+	// The files containing the full text of the license.
+	License_text []string `android:"path"`
+}
+
+type licenseModule struct {
+	ModuleBase
+
+	properties licenseProperties
+
+	licenseTexts Paths
+}
+
+func (m *licenseModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	for _, kind := range m.properties.License_kinds {
+		if _, ok := lookupLicenseModule(ctx.Config(), kind).(*licenseKindModule); !ok {
+			ctx.PropertyErrorf("license_kinds", "%q is not a license_kind module", kind)
+		}
+	}
+
+	m.licenseTexts = ctx.ExpandSources(m.properties.License_text, nil)
+}
+
+// conditions returns the conditions of all the kinds of the license.
+func (m *licenseModule) conditions(config Config) []string {
+	var conditions []string
+	for _, kind := range m.properties.License_kinds {
+		if k, ok := lookupLicenseModule(config, kind).(*licenseKindModule); ok {
+			conditions = append(conditions, k.properties.Conditions...)
+		}
+	}
+	return FirstUniqueStrings(conditions)
+}
+
+// license modules describe the license of the code in a package: its license_kinds, copyright
+// notice and full text.  Other modules reference them by name in their licenses property, see
+// ../README.md#Licenses.
+func LicenseFactory() Module {
+	module := &licenseModule{}
+	module.AddProperties(&module.properties)
+	InitAndroidModule(module)
+	return module
+}
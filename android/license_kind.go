@@ -0,0 +1,48 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+func init() {
+	RegisterModuleType("license_kind", LicenseKindFactory)
+}
+
+type licenseKindProperties struct {
+	// The conditions that apply to code distributed under this kind of license, e.g. "notice",
+	// "reciprocal" or "restricted".
+	Conditions []string
+
+	// A URL where the text of the license can be found.
+	Url *string
+}
+
+type licenseKindModule struct {
+	ModuleBase
+
+	properties licenseKindProperties
+}
+
+func (m *licenseKindModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	// Nothing to do, license kinds only carry metadata for the license modules that use them.
+}
+
+// license_kind modules describe a kind of license, e.g. SPDX-license-identifier-Apache-2.0, and the
+// conditions that apply to code distributed under it.  They are referenced by name from the
+// license_kinds property of license modules.
+func LicenseKindFactory() Module {
+	module := &licenseKindModule{}
+	module.AddProperties(&module.properties)
+	InitAndroidModule(module)
+	return module
+}
@@ -0,0 +1,204 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint"
+)
+
+func init() {
+	RegisterSingletonType("license_metadata", licenseMetadataSingletonFactory)
+}
+
+// licenseMetadataList writes a whitespace separated list of its inputs to its output, for lists
+// that may be too long to put on a command line.
+var licenseMetadataList = pctx.AndroidStaticRule("licenseMetadataList",
+	blueprint.RuleParams{
+		Command:        `cp -f $out.rsp $out`,
+		Rspfile:        "$out.rsp",
+		RspfileContent: "$in",
+	})
+
+// licenseMetadata is the machine readable description of the licenses of an installed file, written
+// as JSON to license_metadata/<path of the installed file>.json in the output directory.
+type licenseMetadata struct {
+	// The name of the module that installs the file.
+	ModuleName string `json:"module_name"`
+
+	// The path of the file, relative to the root of its partition for files installed on the
+	// device, e.g. /system/bin/foo, or relative to the output directory for host files.
+	Installed string `json:"installed"`
+
+	// The license modules that apply to the file, with their kinds, conditions and metadata.
+	Licenses          []string `json:"licenses"`
+	LicenseKinds      []string `json:"license_kinds"`
+	LicenseConditions []string `json:"license_conditions"`
+	LicenseTexts      []string `json:"license_texts"`
+	PackageNames      []string `json:"package_names"`
+	CopyrightNotices  []string `json:"copyright_notices"`
+}
+
+func licenseMetadataSingletonFactory() Singleton {
+	return &licenseMetadataSingleton{}
+}
+
+// licenseMetadataSingleton writes a licenseMetadata file for every file installed by a module,
+// and aggregates the licenses of the files installed on each partition of the device into
+// NOTICE.html and NOTICE.xml files in out/soong/notice/<partition>.  The NOTICE files are built
+// by "m notice_files".
+type licenseMetadataSingleton struct {
+	noticeFiles Paths
+}
+
+func (s *licenseMetadataSingleton) GenerateBuildActions(ctx SingletonContext) {
+	licenses := make(map[string]*licenseModule)
+	ctx.VisitAllModules(func(m Module) {
+		if l, ok := m.(*licenseModule); ok && l.Enabled() {
+			licenses[ctx.ModuleName(l)] = l
+		}
+	})
+
+	productOut := PathForOutput(ctx, "target", "product", ctx.Config().DeviceName())
+	partitionMetadata := make(map[string]Paths)
+	partitionLicenseTexts := make(map[string]Paths)
+	written := make(map[string]bool)
+
+	ctx.VisitAllModules(func(m Module) {
+		if !m.Enabled() || len(m.base().installedFiles) == 0 {
+			return
+		}
+
+		metadata := licenseMetadata{
+			ModuleName: ctx.ModuleName(m),
+		}
+		var licenseTexts Paths
+		for _, name := range m.base().effectiveLicenses {
+			l, ok := licenses[name]
+			if !ok {
+				continue
+			}
+			metadata.Licenses = append(metadata.Licenses, name)
+			metadata.LicenseKinds = append(metadata.LicenseKinds, l.properties.License_kinds...)
+			metadata.LicenseConditions = append(metadata.LicenseConditions, l.conditions(ctx.Config())...)
+			licenseTexts = append(licenseTexts, l.licenseTexts...)
+			if l.properties.Package_name != nil {
+				metadata.PackageNames = append(metadata.PackageNames, *l.properties.Package_name)
+			}
+			if l.properties.Copyright_notice != nil {
+				metadata.CopyrightNotices = append(metadata.CopyrightNotices, *l.properties.Copyright_notice)
+			}
+		}
+		metadata.LicenseKinds = FirstUniqueStrings(metadata.LicenseKinds)
+		metadata.LicenseConditions = FirstUniqueStrings(metadata.LicenseConditions)
+		metadata.LicenseTexts = FirstUniqueStrings(licenseTexts.Strings())
+		metadata.PackageNames = FirstUniqueStrings(metadata.PackageNames)
+		metadata.CopyrightNotices = FirstUniqueStrings(metadata.CopyrightNotices)
+
+		for _, installed := range m.base().installedFiles {
+			partition := ""
+			if rel, isRel := MaybeRel(ctx, productOut.String(), installed.String()); isRel {
+				metadata.Installed = "/" + rel
+				partition = strings.SplitN(rel, "/", 2)[0]
+			} else {
+				metadata.Installed = Rel(ctx, PathForOutput(ctx).String(), installed.String())
+			}
+
+			metadataFile := PathForOutput(ctx, "license_metadata",
+				Rel(ctx, PathForOutput(ctx).String(), installed.String())+".json")
+			// Several variants of a module may install the same file, only write its metadata once.
+			if written[metadataFile.String()] {
+				continue
+			}
+			written[metadataFile.String()] = true
+			writeLicenseMetadata(ctx, metadataFile, metadata)
+
+			if partition != "" && len(licenseTexts) > 0 {
+				partitionMetadata[partition] = append(partitionMetadata[partition], metadataFile)
+				partitionLicenseTexts[partition] = append(partitionLicenseTexts[partition], licenseTexts...)
+			}
+		}
+	})
+
+	s.noticeFiles = nil
+	if len(partitionMetadata) == 0 {
+		return
+	}
+
+	var partitions []string
+	for partition := range partitionMetadata {
+		partitions = append(partitions, partition)
+	}
+	sort.Strings(partitions)
+
+	for _, partition := range partitions {
+		metadataFiles := partitionMetadata[partition]
+		sort.Slice(metadataFiles, func(i, j int) bool { return metadataFiles[i].String() < metadataFiles[j].String() })
+
+		list := PathForOutput(ctx, "notice", partition, "license_metadata.list")
+		ctx.Build(pctx, BuildParams{
+			Rule:        licenseMetadataList,
+			Description: "license metadata list " + partition,
+			Inputs:      metadataFiles,
+			Output:      list,
+		})
+
+		html := PathForOutput(ctx, "notice", partition, "NOTICE.html")
+		xml := PathForOutput(ctx, "notice", partition, "NOTICE.xml")
+
+		rule := NewRuleBuilder()
+		rule.Command().
+			Tool(PathForSource(ctx, "build/soong/scripts/generate_notice_files.py")).
+			FlagWithInput("--metadata_list ", list).
+			FlagWithOutput("--html_out ", html).
+			FlagWithOutput("--xml_out ", xml).
+			Implicits(metadataFiles).
+			Implicits(FirstUniquePaths(partitionLicenseTexts[partition]))
+		rule.Build(pctx, ctx, "notice_"+partition, "generate NOTICE files for "+partition)
+
+		s.noticeFiles = append(s.noticeFiles, html, xml)
+	}
+
+	ctx.Build(pctx, BuildParams{
+		Rule:      blueprint.Phony,
+		Output:    PathForPhony(ctx, "notice_files"),
+		Implicits: s.noticeFiles,
+	})
+}
+
+func (s *licenseMetadataSingleton) MakeVars(ctx MakeVarsContext) {
+	ctx.Strict("SOONG_NOTICE_FILES", strings.Join(s.noticeFiles.Strings(), " "))
+}
+
+// writeLicenseMetadata writes the metadata as JSON to the file.
+func writeLicenseMetadata(ctx SingletonContext, file WritablePath, metadata licenseMetadata) {
+	content, err := json.Marshal(metadata)
+	if err != nil {
+		ctx.Errorf("failed to write license metadata %s: %s", file, err)
+		return
+	}
+
+	ctx.Build(pctx, BuildParams{
+		Rule:        WriteFile,
+		Description: "license metadata " + file.Base(),
+		Output:      file,
+		Args: map[string]string{
+			"content": WriteFileContent(string(content)),
+		},
+	})
+}
@@ -0,0 +1,344 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/blueprint"
+)
+
+var licensesTests = []struct {
+	name              string
+	fs                map[string][]byte
+	expectedErrors    []string
+	effectiveLicenses map[string][]string
+}{
+	{
+		name: "unknown license kind",
+		fs: map[string][]byte{
+			"top/Blueprints": []byte(`
+				license {
+					name: "top_license",
+					license_kinds: ["unknown_kind"],
+				}`),
+		},
+		expectedErrors: []string{`license_kinds: "unknown_kind" is not a license_kind module`},
+	},
+	{
+		name: "licenses must be license modules",
+		fs: map[string][]byte{
+			"top/Blueprints": []byte(`
+				license_kind {
+					name: "top_kind",
+				}
+				mock_licensed {
+					name: "libexample",
+					licenses: ["top_kind", "unknown_license"],
+				}`),
+		},
+		expectedErrors: []string{
+			`licenses: "top_kind" is not a license module`,
+			`licenses: "unknown_license" is not a license module`,
+		},
+	},
+	{
+		name: "licenses must be visible",
+		fs: map[string][]byte{
+			"top/Blueprints": []byte(`
+				license {
+					name: "top_license",
+					visibility: ["//visibility:private"],
+				}`),
+			"other/Blueprints": []byte(`
+				mock_licensed {
+					name: "libother",
+					licenses: ["top_license"],
+				}`),
+		},
+		expectedErrors: []string{
+			`//other:libother uses license //top:top_license which is not visible to this module`,
+		},
+	},
+	{
+		name: "licenses inherited from defaults and static dependencies",
+		fs: map[string][]byte{
+			"top/Blueprints": []byte(`
+				license_kind {
+					name: "top_kind",
+					conditions: ["notice"],
+				}
+				license {
+					name: "top_license",
+					license_kinds: ["top_kind"],
+				}
+				license {
+					name: "other_license",
+				}
+				mock_defaults {
+					name: "top_defaults",
+					licenses: ["top_license"],
+				}
+				mock_licensed {
+					name: "libexample",
+					defaults: ["top_defaults"],
+					static_deps: ["libstatic"],
+					deps: ["libshared"],
+				}
+				mock_licensed {
+					name: "libstatic",
+					licenses: ["other_license"],
+				}
+				mock_licensed {
+					name: "libshared",
+					licenses: ["other_license"],
+				}
+				mock_licensed {
+					name: "libunlicensed",
+				}`),
+		},
+		effectiveLicenses: map[string][]string{
+			"libexample":    []string{"other_license", "top_license"},
+			"libstatic":     []string{"other_license"},
+			"libshared":     []string{"other_license"},
+			"libunlicensed": nil,
+		},
+	},
+	{
+		name: "licenses from package",
+		fs: map[string][]byte{
+			"top/Blueprints": []byte(`
+				package {
+					default_applicable_licenses: ["top_license"],
+					apply_to_subpackages: true,
+				}
+				license {
+					name: "top_license",
+				}
+				license {
+					name: "other_license",
+				}
+				mock_licensed {
+					name: "libexample",
+				}
+				mock_licensed {
+					name: "libother",
+					licenses: ["other_license"],
+				}`),
+			"top/nested/Blueprints": []byte(`
+				mock_licensed {
+					name: "libnested",
+				}`),
+		},
+		effectiveLicenses: map[string][]string{
+			"libexample": []string{"top_license"},
+			"libother":   []string{"other_license"},
+			"libnested":  []string{"top_license"},
+		},
+	},
+	{
+		name: "invalid licenses reported once on package",
+		fs: map[string][]byte{
+			"top/Blueprints": []byte(`
+				package {
+					default_applicable_licenses: ["unknown_license"],
+				}
+				mock_licensed {
+					name: "libexample",
+				}
+				mock_licensed {
+					name: "libexample2",
+				}`),
+		},
+		expectedErrors: []string{
+			`default_applicable_licenses: "unknown_license" is not a license module`,
+		},
+	},
+}
+
+func TestLicenses(t *testing.T) {
+	buildDir, err := ioutil.TempDir("", "soong_licenses_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	for _, test := range licensesTests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, errs := testLicenses(buildDir, test.fs)
+
+			expectedErrors := test.expectedErrors
+			if expectedErrors == nil {
+				FailIfErrored(t, errs)
+			} else {
+				for _, expectedError := range expectedErrors {
+					FailIfNoMatchingErrors(t, expectedError, errs)
+				}
+				if len(errs) > len(expectedErrors) {
+					t.Errorf("additional errors found, expected %d, found %d", len(expectedErrors), len(errs))
+					for i, err := range errs {
+						t.Errorf("errs[%d] = %s", i, err)
+					}
+				}
+				return
+			}
+
+			for name, expected := range test.effectiveLicenses {
+				m := ctx.ModuleForTests(name, "android_common").Module()
+				if actual := m.base().effectiveLicenses; !reflect.DeepEqual(actual, expected) {
+					t.Errorf("expected licenses of %s to be %q, got %q", name, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestLicenseMetadata(t *testing.T) {
+	buildDir, err := ioutil.TempDir("", "soong_licenses_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	ctx, errs := testLicenses(buildDir, map[string][]byte{
+		"build/soong/scripts/generate_notice_files.py": nil,
+		"top/LICENSE": nil,
+		"top/Blueprints": []byte(`
+			license_kind {
+				name: "top_kind",
+				conditions: ["notice"],
+			}
+			license {
+				name: "top_license",
+				license_kinds: ["top_kind"],
+				license_text: ["LICENSE"],
+				package_name: "Top",
+			}
+			mock_licensed {
+				name: "libexample",
+				licenses: ["top_license"],
+			}
+			mock_licensed {
+				name: "libunlicensed",
+			}`),
+	})
+	FailIfErrored(t, errs)
+
+	singleton := ctx.SingletonForTests("license_metadata")
+
+	metadata := singleton.Output("license_metadata/target/product/test_device/system/lib/libexample.json")
+	for _, want := range []string{
+		`"module_name":"libexample"`,
+		`"installed":"/system/lib/libexample"`,
+		`"licenses":["top_license"]`,
+		`"license_kinds":["top_kind"]`,
+		`"license_conditions":["notice"]`,
+		`"license_texts":["top/LICENSE"]`,
+		`"package_names":["Top"]`,
+	} {
+		if content := metadata.Args["content"]; !strings.Contains(content, want) {
+			t.Errorf("expected license metadata of libexample to contain %s, got %s", want, content)
+		}
+	}
+
+	// Files without licenses still get metadata, but are not listed in the NOTICE files.
+	unlicensed := singleton.Output("license_metadata/target/product/test_device/system/lib/libunlicensed.json")
+
+	notice := singleton.Output("notice/system/NOTICE.html")
+	if !InList(metadata.Output.String(), notice.Implicits.Strings()) {
+		t.Errorf("expected NOTICE files to depend on %s, got %q", metadata.Output, notice.Implicits)
+	}
+	if InList(unlicensed.Output.String(), notice.Implicits.Strings()) {
+		t.Errorf("expected NOTICE files not to depend on %s, got %q", unlicensed.Output, notice.Implicits)
+	}
+	if !InList("top/LICENSE", notice.Implicits.Strings()) {
+		t.Errorf("expected NOTICE files to depend on top/LICENSE, got %q", notice.Implicits)
+	}
+}
+
+func testLicenses(buildDir string, fs map[string][]byte) (*TestContext, []error) {
+
+	// Create a new config per test as license information is stored in the config.
+	config := TestArchConfig(buildDir, nil)
+
+	ctx := NewTestArchContext()
+	ctx.RegisterModuleType("license", ModuleFactoryAdaptor(LicenseFactory))
+	ctx.RegisterModuleType("license_kind", ModuleFactoryAdaptor(LicenseKindFactory))
+	ctx.RegisterModuleType("mock_licensed", ModuleFactoryAdaptor(newMockLicensedModule))
+	ctx.RegisterModuleType("mock_defaults", ModuleFactoryAdaptor(defaultsFactory))
+	ctx.RegisterModuleType("package", ModuleFactoryAdaptor(PackageFactory))
+	ctx.PreArchMutators(RegisterDefaultsPreArchMutators)
+	ctx.PreArchMutators(registerVisibilityRuleGatherer)
+	ctx.PreArchMutators(registerLicensesGatherer)
+	ctx.PostDepsMutators(registerLicensesPropagator)
+	ctx.RegisterSingletonType("license_metadata", SingletonFactoryAdaptor(licenseMetadataSingletonFactory))
+	ctx.Register()
+
+	ctx.MockFileSystem(fs)
+
+	_, errs := ctx.ParseBlueprintsFiles(".")
+	if len(errs) > 0 {
+		return ctx, errs
+	}
+
+	_, errs = ctx.PrepareBuildActions(config)
+	return ctx, errs
+}
+
+type mockLicensedProperties struct {
+	Deps        []string
+	Static_deps []string
+}
+
+type mockLicensedModule struct {
+	ModuleBase
+	DefaultableModuleBase
+	properties mockLicensedProperties
+}
+
+func newMockLicensedModule() Module {
+	m := &mockLicensedModule{}
+	m.AddProperties(&m.properties)
+	InitAndroidArchModule(m, DeviceSupported, MultilibCommon)
+	InitDefaultableModule(m)
+	return m
+}
+
+type licensesTestDependencyTag struct {
+	blueprint.BaseDependencyTag
+	static bool
+}
+
+func (t licensesTestDependencyTag) PropagatesLicenses() bool {
+	return t.static
+}
+
+func (m *mockLicensedModule) DepsMutator(ctx BottomUpMutatorContext) {
+	ctx.AddVariationDependencies(nil, licensesTestDependencyTag{}, m.properties.Deps...)
+	ctx.AddVariationDependencies(nil, licensesTestDependencyTag{static: true}, m.properties.Static_deps...)
+}
+
+func (m *mockLicensedModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	out := PathForModuleOut(ctx, ctx.ModuleName())
+	ctx.Build(pctx, BuildParams{
+		Rule:   Touch,
+		Output: out,
+	})
+	ctx.InstallFile(PathForModuleInstall(ctx, "lib"), ctx.ModuleName(), out)
+}
@@ -0,0 +1,172 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/google/blueprint"
+)
+
+// Tracks the licenses that apply to each module.
+//
+// Two stage process:
+// * First stage works bottom up before the arch mutator and records the license and license_kind
+//   modules in a map keyed by module name. As with visibility the map is stored in the config
+//   rather than a global variable so that tests can run in parallel.
+//
+// * Second stage works top down after the deps have been resolved. It checks that the licenses
+//   property of each module names license modules that are visible to it, then walks the
+//   dependencies whose tag implements LicensePropagatingDependencyTag, e.g. static libraries linked
+//   into the module or files packaged into it, and adds the licenses of those dependencies. The
+//   result is stored in the module and used by licenseMetadataSingleton. A module that does not
+//   set licenses uses the default_applicable_licenses of its package module, which are checked
+//   on the package module.
+//
+// Licenses are not dependencies of the modules that use them, a license module does not produce
+// anything that is built into another module.
+
+// LicensePropagatingDependencyTag is implemented by the dependency tags of modules that can link or
+// package the dependency into their output, e.g. static libraries or the contents of an APEX.
+type LicensePropagatingDependencyTag interface {
+	blueprint.DependencyTag
+
+	// PropagatesLicenses returns true if the licenses of the dependency also apply to the module
+	// that depends on it.
+	PropagatesLicenses() bool
+}
+
+var licenseModulesMap = NewOnceKey("licenseModulesMap")
+
+// The map from module name to license and license_kind modules.
+func licenseModules(config Config) *sync.Map {
+	return config.Once(licenseModulesMap, func() interface{} {
+		return &sync.Map{}
+	}).(*sync.Map)
+}
+
+// lookupLicenseModule returns the license or license_kind module with the name, or nil.
+func lookupLicenseModule(config Config, name string) Module {
+	if m, ok := licenseModules(config).Load(name); ok {
+		return m.(Module)
+	}
+	return nil
+}
+
+// Licenses are not dependent on arch so this must be registered before the arch phase to avoid
+// having to process multiple variants for each module.
+func registerLicensesGatherer(ctx RegisterMutatorsContext) {
+	ctx.BottomUp("licensesGatherer", licensesGatherer).Parallel()
+}
+
+// This must be registered after the deps have been resolved.
+func registerLicensesPropagator(ctx RegisterMutatorsContext) {
+	ctx.TopDown("licensesPropagator", licensesPropagator).Parallel()
+}
+
+func licensesGatherer(ctx BottomUpMutatorContext) {
+	switch m := ctx.Module().(type) {
+	case *licenseModule, *licenseKindModule:
+		licenseModules(ctx.Config()).Store(ctx.ModuleName(), m)
+	}
+}
+
+func licensesPropagator(ctx TopDownMutatorContext) {
+	m := ctx.Module()
+	switch m := m.(type) {
+	case Defaults, *licenseModule, *licenseKindModule:
+		// Defaults only carry licenses on behalf of the modules that use them.
+		return
+	case *packageModule:
+		// Check the licenses of the package once rather than in every module that uses them.
+		resolveLicenses(ctx, m, "default_applicable_licenses", m.properties.Default_applicable_licenses)
+		return
+	}
+
+	var licenses []string
+	if m.base().commonProperties.Licenses != nil {
+		licenses = resolveLicenses(ctx, m, "licenses", m.base().commonProperties.Licenses)
+	} else {
+		licenses = filterLicenses(ctx.Config(), applicableLicenses(m))
+	}
+
+	ctx.WalkDeps(func(child, parent Module) bool {
+		tag, ok := ctx.OtherModuleDependencyTag(child).(LicensePropagatingDependencyTag)
+		if !ok || !tag.PropagatesLicenses() {
+			return false
+		}
+		licenses = append(licenses, filterLicenses(ctx.Config(), applicableLicenses(child))...)
+		return true
+	})
+
+	licenses = FirstUniqueStrings(licenses)
+	sort.Strings(licenses)
+	m.base().effectiveLicenses = licenses
+}
+
+// applicableLicenses returns the names of the licenses declared by the module, in its licenses
+// property or, if that is not set, in the default_applicable_licenses property of its package.
+func applicableLicenses(m Module) []string {
+	if licenses := m.base().commonProperties.Licenses; licenses != nil {
+		return licenses
+	}
+	if p := m.base().packageModule(); p != nil {
+		return p.properties.Default_applicable_licenses
+	}
+	return nil
+}
+
+// filterLicenses returns the names that are license modules.
+func filterLicenses(config Config, names []string) []string {
+	var licenses []string
+	for _, name := range names {
+		if _, ok := lookupLicenseModule(config, name).(*licenseModule); ok {
+			licenses = append(licenses, name)
+		}
+	}
+	return licenses
+}
+
+// resolveLicenses returns the names of the licenses in the property of the module, reporting an
+// error for any name that is not a license module visible to it.
+func resolveLicenses(ctx TopDownMutatorContext, m Module, property string, names []string) []string {
+	qualified := m.base().qualifiedModuleName(ctx.ModuleName())
+
+	var licenses []string
+	for _, name := range names {
+		license, ok := lookupLicenseModule(ctx.Config(), name).(*licenseModule)
+		if !ok {
+			if ctx.Config().AllowMissingDependencies() {
+				continue
+			}
+			ctx.PropertyErrorf(property, "%q is not a license module", name)
+			continue
+		}
+
+		licenseQualified := license.base().qualifiedModuleName(name)
+		if licenseQualified.pkg != qualified.pkg {
+			if rule, ok := moduleToVisibilityRuleMap(ctx).Load(licenseQualified); ok &&
+				!rule.(compositeRule).matches(qualified) {
+				ctx.PropertyErrorf(property, "%s uses license %s which is not visible to this module; "+
+					"%s is only visible to %s", qualified, licenseQualified, licenseQualified, rule)
+				continue
+			}
+		}
+
+		licenses = append(licenses, name)
+	}
+	return licenses
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/blueprint"
+
+	"android/soong/ui/metrics/metrics_proto"
+)
+
+// mutatorTiming records the wall time of a mutator, from when it started on the first module to
+// when it finished on the last one.  Blueprint runs each mutator on every module before starting
+// the next mutator, so the time of a parallel mutator is not inflated by the number of modules it
+// visited concurrently.
+type mutatorTiming struct {
+	lock       sync.Mutex
+	start, end time.Time
+}
+
+// record extends the time of the mutator to cover a single run of it that started at start and
+// has just finished.
+func (t *mutatorTiming) record(start time.Time) {
+	end := time.Now()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.start.IsZero() || start.Before(t.start) {
+		t.start = start
+	}
+	if end.After(t.end) {
+		t.end = end
+	}
+}
+
+type moduleKey struct {
+	dir, name string
+}
+
+// WriteMetrics writes the soong_build metrics to filename, as a metrics_proto.MetricsBase with only
+// the Soong module types and the SoongBuildMetrics set, so that soong_ui can merge it into the
+// metrics of the build.  It must be called after the build actions have been generated.
+func WriteMetrics(ctx *Context, filename string) error {
+	soongMetrics := &metrics_proto.SoongBuildMetrics{}
+
+	modules := make(map[moduleKey]bool)
+	moduleTypes := make(map[string]uint32)
+	variants := uint32(0)
+	ctx.VisitAllModules(func(m blueprint.Module) {
+		variants++
+
+		key := moduleKey{ctx.ModuleDir(m), ctx.ModuleName(m)}
+		if !modules[key] {
+			modules[key] = true
+			moduleTypes[ctx.ModuleType(m)]++
+		}
+	})
+	soongMetrics.Modules = proto.Uint32(uint32(len(modules)))
+	soongMetrics.Variants = proto.Uint32(variants)
+
+	for _, mutator := range ctx.mutators {
+		if mutator.timing.start.IsZero() {
+			// The mutator did not run on any modules.
+			continue
+		}
+		soongMetrics.Mutators = append(soongMetrics.Mutators, &metrics_proto.PerfInfo{
+			Desc:      proto.String(mutator.name),
+			Name:      proto.String("mutator"),
+			StartTime: proto.Uint64(uint64(mutator.timing.start.UnixNano())),
+			RealTime:  proto.Uint64(uint64(mutator.timing.end.Sub(mutator.timing.start).Nanoseconds())),
+		})
+	}
+
+	var rusage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &rusage); err == nil {
+		maxRss := uint64(rusage.Maxrss)
+		if runtime.GOOS == "darwin" {
+			// Darwin reports the max resident set size in bytes instead of kB.
+			maxRss /= 1024
+		}
+		soongMetrics.MaxRssKb = proto.Uint64(maxRss)
+	}
+
+	metrics := &metrics_proto.MetricsBase{
+		SoongBuildMetrics: soongMetrics,
+	}
+
+	var types []string
+	for t := range moduleTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		metrics.ModuleTypes = append(metrics.ModuleTypes, &metrics_proto.ModuleTypeInfo{
+			BuildSystem:  metrics_proto.ModuleTypeInfo_SOONG.Enum(),
+			ModuleType:   proto.String(t),
+			NumOfModules: proto.Uint32(moduleTypes[t]),
+		})
+	}
+
+	data, err := proto.Marshal(metrics)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename it, so that soong_ui never reads a partial file.
+	tempFile := filename + ".tmp"
+	if err := ioutil.WriteFile(tempFile, data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tempFile, filename)
+}
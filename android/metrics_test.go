@@ -0,0 +1,113 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"android/soong/ui/metrics/metrics_proto"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	buildDir, err := ioutil.TempDir("", "soong_metrics_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	config := TestArchConfig(buildDir, nil)
+
+	ctx := NewTestArchContext()
+	ctx.RegisterModuleType("mock_library", ModuleFactoryAdaptor(newMockLibraryModule))
+	ctx.RegisterModuleType("filegroup", ModuleFactoryAdaptor(FileGroupFactory))
+	ctx.Register()
+
+	ctx.MockFileSystem(map[string][]byte{
+		"Android.bp": []byte(`
+			mock_library {
+				name: "libfoo",
+				deps: ["libbar"],
+			}
+
+			mock_library {
+				name: "libbar",
+			}
+
+			filegroup {
+				name: "srcs",
+			}
+		`),
+	})
+
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	FailIfErrored(t, errs)
+	_, errs = ctx.PrepareBuildActions(config)
+	FailIfErrored(t, errs)
+
+	metricsFile := filepath.Join(buildDir, "soong_build_metrics.pb")
+	if err := WriteMetrics(ctx.Context, metricsFile); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(metricsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := &metrics_proto.MetricsBase{}
+	if err := proto.Unmarshal(data, metrics); err != nil {
+		t.Fatal(err)
+	}
+
+	moduleTypes := make(map[string]uint32)
+	for _, info := range metrics.ModuleTypes {
+		if info.GetBuildSystem() != metrics_proto.ModuleTypeInfo_SOONG {
+			t.Errorf("expected build system SOONG for %q, got %s", info.GetModuleType(), info.GetBuildSystem())
+		}
+		moduleTypes[info.GetModuleType()] = info.GetNumOfModules()
+	}
+	if g, w := moduleTypes["mock_library"], uint32(2); g != w {
+		t.Errorf("expected %d mock_library modules, got %d", w, g)
+	}
+	if g, w := moduleTypes["filegroup"], uint32(1); g != w {
+		t.Errorf("expected %d filegroup modules, got %d", w, g)
+	}
+
+	soongMetrics := metrics.SoongBuildMetrics
+	if soongMetrics == nil {
+		t.Fatal("missing soong_build metrics")
+	}
+	if g, w := soongMetrics.GetModules(), uint32(3); g != w {
+		t.Errorf("expected %d modules, got %d", w, g)
+	}
+	if soongMetrics.GetVariants() < soongMetrics.GetModules() {
+		t.Errorf("expected at least %d variants, got %d", soongMetrics.GetModules(), soongMetrics.GetVariants())
+	}
+	if soongMetrics.GetMaxRssKb() == 0 {
+		t.Errorf("expected max rss to be set")
+	}
+
+	var mutators []string
+	for _, perf := range soongMetrics.Mutators {
+		mutators = append(mutators, perf.GetDesc())
+	}
+	if !InList("arch", mutators) || !InList("deps", mutators) {
+		t.Errorf("expected arch and deps mutators to be recorded, got %q", mutators)
+	}
+}
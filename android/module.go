@@ -189,6 +189,7 @@ type Module interface {
 	InstallInRecovery() bool
 	SkipInstall()
 	ExportedToMake() bool
+	FilesToInstall() Paths
 
 	AddProperties(props ...interface{})
 	GetProperties() []interface{}
@@ -207,6 +208,45 @@ type commonProperties struct {
 	// emit build rules for this module
 	Enabled *bool `android:"arch_variant"`
 
+	// Controls the visibility of this module to other modules. Allowable values are one or more of
+	// these formats:
+	//
+	//  ["//visibility:public"]: Anyone can use this module.
+	//  ["//visibility:private"]: Only rules in the module's package (not its subpackages) can use
+	//      this module.
+	//  ["//visibility:namespace"]: Only modules in the same soong_namespace as this module can use
+	//      this module.
+	//  ["//some/package:__pkg__", "//other/package:__pkg__"]: Only modules in some/package and
+	//      other/package (defined in some/package/*.bp and other/package/*.bp) have access to
+	//      this module. Note that sub-packages do not have access to the rule; for example,
+	//      //some/package/foo:bar or //other/package/testing:bla wouldn't have access. __pkg__
+	//      is a special module and must be used verbatim. It represents all of the modules in the
+	//      package.
+	//  ["//project:__subpackages__", "//other:__subpackages__"]: Only modules in packages project
+	//      or other or in one of their sub-packages have access to this module. For example,
+	//      //project:rule, //project/library:lib or //other/testing/internal:munge are allowed
+	//      to depend on this rule (but not //independent:evil)
+	//  ["//project"]: This is shorthand for ["//project:__pkg__"]
+	//  [":__subpackages__"]: This is shorthand for ["//project:__subpackages__"] where
+	//      //project is the module's package. e.g. using [":__subpackages__"] in
+	//      packages/apps/Settings/Android.bp is equivalent to
+	//      //packages/apps/Settings:__subpackages__.
+	//
+	// If a module does not specify the `visibility` property then it uses the
+	// `visibility` property of its `*_defaults` modules, if any, or else the
+	// `default_visibility` property of its `package` module, and is otherwise public.
+	//
+	// See https://docs.bazel.build/versions/master/be/common-definitions.html#common.visibility for
+	// more details.
+	Visibility []string
+
+	// Names of the license modules that describe the licenses of this module.  If a module does not
+	// specify the `licenses` property then it uses the `licenses` property of its `*_defaults`
+	// modules, if any, or else the `default_applicable_licenses` property of its `package` module.
+	// The licenses of static libraries and other dependencies that are linked or
+	// packaged into the module also apply to it.  See ../README.md#Licenses for more details.
+	Licenses []string
+
 	// control whether this module compiles for 32-bit, 64-bit, or both.  Possible values
 	// are "32" (compile for 32-bit only), "64" (compile for 64-bit only), "both" (compile for both
 	// architectures), or "first" (compile for 64-bit on a 64-bit platform, and 32-bit on a 32-bit
@@ -301,6 +341,11 @@ type commonProperties struct {
 	SkipInstall bool `blueprint:"mutated"`
 
 	NamespaceExportedToMake bool `blueprint:"mutated"`
+
+	// Set by NameResolver.NewModule to the directory containing the module's Android.bp file and
+	// the path of the namespace it was added to.
+	ModuleDir     string `blueprint:"mutated"`
+	NamespacePath string `blueprint:"mutated"`
 }
 
 type hostAndDeviceProperties struct {
@@ -467,6 +512,15 @@ type ModuleBase struct {
 	checkbuildFiles    Paths
 	noticeFile         Path
 
+	// The files the module installs, whether they are installed by Soong or by Make
+	installedFiles Paths
+
+	// The names of the license modules that apply to the module, set by licensesPropagator
+	effectiveLicenses []string
+
+	// The NameResolver that registered the module, used to find its package
+	nameResolver *NameResolver
+
 	// Used by buildTargetSingleton to create checkbuild and per-directory build targets
 	// Only set on the final variant of each module
 	installTarget    WritablePath
@@ -477,6 +531,9 @@ type ModuleBase struct {
 
 	registerProps []interface{}
 
+	// The direct dependencies of the module, only recorded when writing the module graph
+	moduleGraphDeps []moduleGraphDep
+
 	// For tests
 	buildParams []BuildParams
 	ruleParams  map[blueprint.Rule]blueprint.RuleParams
@@ -646,6 +703,12 @@ func (a *ModuleBase) filesToInstall() Paths {
 	return a.installFiles
 }
 
+// FilesToInstall returns the files installed by the module, not including the files installed by its
+// dependencies.
+func (a *ModuleBase) FilesToInstall() Paths {
+	return a.installFiles
+}
+
 func (p *ModuleBase) NoAddressSanitizer() bool {
 	return p.noAddressSanitizer
 }
@@ -806,6 +869,10 @@ func (a *ModuleBase) GenerateBuildActions(blueprintCtx blueprint.ModuleContext)
 		ctx.ruleParams = make(map[blueprint.Rule]blueprint.RuleParams)
 	}
 
+	if ctx.config.moduleGraphFile != "" {
+		a.recordModuleGraphDeps(blueprintCtx)
+	}
+
 	desc := "//" + ctx.ModuleDir() + ":" + ctx.ModuleName() + " "
 	var suffix []string
 	if ctx.Os().Class != Device && ctx.Os().Class != Generic {
@@ -849,6 +916,7 @@ func (a *ModuleBase) GenerateBuildActions(blueprintCtx blueprint.ModuleContext)
 		}
 
 		a.installFiles = append(a.installFiles, ctx.installFiles...)
+		a.installedFiles = append(a.installedFiles, ctx.installedFiles...)
 		a.checkbuildFiles = append(a.checkbuildFiles, ctx.checkbuildFiles...)
 
 		if a.commonProperties.Notice != nil {
@@ -883,6 +951,7 @@ type androidModuleContext struct {
 	androidBaseContextImpl
 	installDeps     Paths
 	installFiles    Paths
+	installedFiles  Paths
 	checkbuildFiles Paths
 	missingDeps     []string
 	module          Module
@@ -1219,14 +1288,7 @@ func (a *androidModuleContext) InstallInRecovery() bool {
 }
 
 func (a *androidModuleContext) skipInstall(fullInstallPath OutputPath) bool {
-	if a.module.base().commonProperties.SkipInstall {
-		return true
-	}
-
-	// We'll need a solution for choosing which of modules with the same name in different
-	// namespaces to install.  For now, reuse the list of namespaces exported to Make as the
-	// list of namespaces to install in a Soong-only build.
-	if !a.module.base().commonProperties.NamespaceExportedToMake {
+	if a.notInstalled() {
 		return true
 	}
 
@@ -1243,6 +1305,22 @@ func (a *androidModuleContext) skipInstall(fullInstallPath OutputPath) bool {
 	return false
 }
 
+// notInstalled returns true if the module is not installed at all, neither by Soong nor by Make.
+func (a *androidModuleContext) notInstalled() bool {
+	if a.module.base().commonProperties.SkipInstall {
+		return true
+	}
+
+	// We'll need a solution for choosing which of modules with the same name in different
+	// namespaces to install.  For now, reuse the list of namespaces exported to Make as the
+	// list of namespaces to install in a Soong-only build.
+	if !a.module.base().commonProperties.NamespaceExportedToMake {
+		return true
+	}
+
+	return false
+}
+
 func (a *androidModuleContext) InstallFile(installPath OutputPath, name string, srcPath Path,
 	deps ...Path) OutputPath {
 	return a.installFile(installPath, name, srcPath, Cp, deps)
@@ -1285,6 +1363,9 @@ func (a *androidModuleContext) installFile(installPath OutputPath, name string,
 
 		a.installFiles = append(a.installFiles, fullInstallPath)
 	}
+	if !a.notInstalled() {
+		a.installedFiles = append(a.installedFiles, fullInstallPath)
+	}
 	a.checkbuildFiles = append(a.checkbuildFiles, srcPath)
 	return fullInstallPath
 }
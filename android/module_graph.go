@@ -0,0 +1,241 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/parser"
+)
+
+// The module graph is a JSON description of every module variant after all the mutators have run,
+// written by soong_build when passed --module_graph_file.  It is a list of objects of the form:
+//
+//     {
+//         "name": "libfoo",
+//         "type": "cc_library",
+//         "namespace": ".",
+//         "blueprint": "external/foo/Android.bp",
+//         "line": 12,
+//         "variant": "android_arm64_armv8-a_core_shared",
+//         "os": "android",
+//         "arch": "arm64",
+//         "deps": [
+//             {
+//                 "name": "libc",
+//                 "namespace": ".",
+//                 "variant": "android_arm64_armv8-a_core_shared",
+//                 "tag": "cc.dependencyTag"
+//             }
+//         ],
+//         "properties": [
+//             {"type": "cc.BaseProperties", "values": {...}},
+//             ...
+//         ]
+//     }
+//
+// The properties are the final values of the property structs returned by GetProperties(), after
+// defaults, load hooks and arch specific properties have been applied.  The line is omitted for
+// modules that were not defined directly in a Blueprints file, e.g. modules created by other
+// modules.
+
+type moduleGraphModule struct {
+	Name       string                `json:"name"`
+	Type       string                `json:"type"`
+	Namespace  string                `json:"namespace"`
+	Blueprint  string                `json:"blueprint"`
+	Line       int                   `json:"line,omitempty"`
+	Variant    string                `json:"variant"`
+	Os         string                `json:"os"`
+	Arch       string                `json:"arch"`
+	Deps       []moduleGraphDepJSON  `json:"deps"`
+	Properties []moduleGraphProperty `json:"properties"`
+}
+
+type moduleGraphDepJSON struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Variant   string `json:"variant"`
+	Tag       string `json:"tag"`
+}
+
+type moduleGraphProperty struct {
+	Type   string      `json:"type,omitempty"`
+	Values interface{} `json:"values"`
+}
+
+// A moduleGraphDep is a direct dependency recorded by recordModuleGraphDeps.  The variant of the
+// dependency is looked up when the graph is written.
+type moduleGraphDep struct {
+	module blueprint.Module
+	name   string
+	tag    blueprint.DependencyTag
+}
+
+// recordModuleGraphDeps records the direct dependencies of the module and their dependency tags.
+// It is called from GenerateBuildActions, after all the dependencies have been added, as the
+// dependency tags are not available outside of module contexts.
+func (a *ModuleBase) recordModuleGraphDeps(ctx blueprint.ModuleContext) {
+	ctx.VisitDirectDeps(func(dep blueprint.Module) {
+		a.moduleGraphDeps = append(a.moduleGraphDeps, moduleGraphDep{
+			module: dep,
+			name:   ctx.OtherModuleName(dep),
+			tag:    ctx.OtherModuleDependencyTag(dep),
+		})
+	})
+}
+
+// WriteModuleGraph writes the module graph to the file set with Config.SetModuleGraphFile.  It must
+// be called after the build actions have been generated.
+func WriteModuleGraph(ctx *Context, config Config) error {
+	filename := config.ModuleGraphFile()
+	if filename == "" {
+		return nil
+	}
+
+	lines := &moduleLines{files: make(map[string]map[string]int)}
+
+	var modules []moduleGraphModule
+	ctx.VisitAllModules(func(m blueprint.Module) {
+		module, ok := m.(Module)
+		if !ok {
+			return
+		}
+		base := module.base()
+
+		name := ctx.ModuleName(m)
+		blueprintFile := ctx.BlueprintFile(m)
+
+		graphModule := moduleGraphModule{
+			Name:      name,
+			Type:      ctx.ModuleType(m),
+			Namespace: base.commonProperties.NamespacePath,
+			Blueprint: blueprintFile,
+			Line:      lines.line(blueprintFile, name),
+			Variant:   ctx.ModuleSubDir(m),
+			Os:        base.Os().String(),
+			Arch:      base.Arch().ArchType.String(),
+			Deps:      []moduleGraphDepJSON{},
+		}
+
+		for _, dep := range base.moduleGraphDeps {
+			depNamespace := ""
+			if depModule, ok := dep.module.(Module); ok {
+				depNamespace = depModule.base().commonProperties.NamespacePath
+			}
+			graphModule.Deps = append(graphModule.Deps, moduleGraphDepJSON{
+				Name:      dep.name,
+				Namespace: depNamespace,
+				Variant:   ctx.ModuleSubDir(dep.module),
+				Tag:       dependencyTagTypeName(dep.tag),
+			})
+		}
+
+		for _, props := range module.GetProperties() {
+			graphModule.Properties = append(graphModule.Properties, moduleGraphProperty{
+				Type:   propertyStructTypeName(props),
+				Values: props,
+			})
+		}
+
+		modules = append(modules, graphModule)
+	})
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create module graph file: %s", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := json.NewEncoder(w).Encode(modules); err != nil {
+		return fmt.Errorf("failed to write module graph file %s: %s", filename, err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write module graph file %s: %s", filename, err)
+	}
+
+	return nil
+}
+
+func dependencyTagTypeName(tag blueprint.DependencyTag) string {
+	if tag == nil {
+		return ""
+	}
+	return reflect.TypeOf(tag).String()
+}
+
+// propertyStructTypeName returns the name of the type of a property struct, or "" for the struct
+// types created at runtime, e.g. for arch specific properties, which have no name.
+func propertyStructTypeName(props interface{}) string {
+	t := reflect.TypeOf(props)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return ""
+	}
+	return t.String()
+}
+
+// moduleLines finds the line on which a module is defined in a Blueprints file.  Blueprint doesn't
+// expose the position of a module, so each Blueprints file is parsed again the first time it is
+// needed.
+type moduleLines struct {
+	files map[string]map[string]int
+}
+
+func (l *moduleLines) line(filename, name string) int {
+	lines, ok := l.files[filename]
+	if !ok {
+		lines = parseModuleLines(filename)
+		l.files[filename] = lines
+	}
+	return lines[name]
+}
+
+func parseModuleLines(filename string) map[string]int {
+	lines := make(map[string]int)
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return lines
+	}
+	defer f.Close()
+
+	file, errs := parser.ParseAndEval(filename, f, parser.NewScope(nil))
+	if len(errs) > 0 {
+		return lines
+	}
+
+	for _, def := range file.Defs {
+		module, ok := def.(*parser.Module)
+		if !ok {
+			continue
+		}
+		if name, ok := parserString(parserProperties(module)["name"]); ok {
+			if _, exists := lines[name]; !exists {
+				lines[name] = module.TypePos.Line
+			}
+		}
+	}
+
+	return lines
+}
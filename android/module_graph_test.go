@@ -0,0 +1,125 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteModuleGraph(t *testing.T) {
+	buildDir, err := ioutil.TempDir("", "soong_module_graph_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	config := TestArchConfig(buildDir, nil)
+	graphFile := filepath.Join(buildDir, "module_graph.json")
+	config.SetModuleGraphFile(graphFile)
+
+	ctx := NewTestArchContext()
+	ctx.RegisterModuleType("mock_library", ModuleFactoryAdaptor(newMockLibraryModule))
+	ctx.Register()
+
+	ctx.MockFileSystem(map[string][]byte{
+		"Android.bp": []byte(`
+			mock_library {
+				name: "libfoo",
+				deps: ["libbar"],
+			}
+
+			mock_library {
+				name: "libbar",
+			}
+		`),
+	})
+
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	FailIfErrored(t, errs)
+	_, errs = ctx.PrepareBuildActions(config)
+	FailIfErrored(t, errs)
+
+	if err := WriteModuleGraph(ctx.Context, config); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(graphFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var modules []struct {
+		Name       string
+		Type       string
+		Blueprint  string
+		Variant    string
+		Deps       []moduleGraphDepJSON
+		Properties []struct {
+			Type   string
+			Values json.RawMessage
+		}
+	}
+	if err := json.Unmarshal(data, &modules); err != nil {
+		t.Fatalf("failed to parse module graph: %s", err)
+	}
+
+	found := false
+	for _, m := range modules {
+		if m.Name != "libfoo" || m.Variant != "android_common" {
+			continue
+		}
+		found = true
+
+		if g, w := m.Type, "mock_library"; g != w {
+			t.Errorf("expected type %q, got %q", w, g)
+		}
+		if g, w := m.Blueprint, "Android.bp"; g != w {
+			t.Errorf("expected blueprint %q, got %q", w, g)
+		}
+
+		expectedDeps := []moduleGraphDepJSON{
+			{Name: "libbar", Namespace: ".", Variant: "android_common", Tag: "android.dependencyTag"},
+		}
+		if !reflect.DeepEqual(m.Deps, expectedDeps) {
+			t.Errorf("expected deps %#v, got %#v", expectedDeps, m.Deps)
+		}
+
+		foundProps := false
+		for _, p := range m.Properties {
+			if p.Type == "android.mockLibraryProperties" {
+				foundProps = true
+				var props mockLibraryProperties
+				if err := json.Unmarshal(p.Values, &props); err != nil {
+					t.Fatal(err)
+				}
+				if g, w := props.Deps, []string{"libbar"}; !reflect.DeepEqual(g, w) {
+					t.Errorf("expected deps property %q, got %q", w, g)
+				}
+			}
+		}
+		if !foundProps {
+			t.Errorf("missing android.mockLibraryProperties in %#v", m.Properties)
+		}
+	}
+
+	if !found {
+		t.Errorf("libfoo android_common not found in module graph:\n%s", string(data))
+	}
+}
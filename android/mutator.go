@@ -15,6 +15,8 @@
 package android
 
 import (
+	"time"
+
 	"github.com/google/blueprint"
 	"github.com/google/blueprint/proptools"
 )
@@ -41,7 +43,7 @@ func registerMutatorsToContext(ctx *blueprint.Context, mutators []*mutator) {
 	}
 }
 
-func registerMutators(ctx *blueprint.Context, preArch, preDeps, postDeps []RegisterMutatorFunc) {
+func registerMutators(ctx *blueprint.Context, preArch, preDeps, postDeps []RegisterMutatorFunc) []*mutator {
 	mctx := &registerMutatorsContext{}
 
 	register := func(funcs []RegisterMutatorFunc) {
@@ -59,6 +61,8 @@ func registerMutators(ctx *blueprint.Context, preArch, preDeps, postDeps []Regis
 	register(postDeps)
 
 	registerMutatorsToContext(ctx, mctx.mutators)
+
+	return mctx.mutators
 }
 
 type registerMutatorsContext struct {
@@ -79,6 +83,9 @@ var preArch = []RegisterMutatorFunc{
 	RegisterNamespaceMutator,
 	RegisterPrebuiltsPreArchMutators,
 	RegisterDefaultsPreArchMutators,
+	RegisterOverridePreArchMutators,
+	registerVisibilityRuleGatherer,
+	registerLicensesGatherer,
 }
 
 func registerArchMutator(ctx RegisterMutatorsContext) {
@@ -94,6 +101,8 @@ var postDeps = []RegisterMutatorFunc{
 	registerPathDepsMutator,
 	RegisterPrebuiltsPostDepsMutators,
 	registerNeverallowMutator,
+	registerVisibilityRuleEnforcer,
+	registerLicensesPropagator,
 }
 
 func PreArchMutators(f RegisterMutatorFunc) {
@@ -171,31 +180,33 @@ type androidBottomUpMutatorContext struct {
 }
 
 func (x *registerMutatorsContext) BottomUp(name string, m AndroidBottomUpMutator) MutatorHandle {
-	f := func(ctx blueprint.BottomUpMutatorContext) {
+	mutator := &mutator{name: name}
+	mutator.bottomUpMutator = func(ctx blueprint.BottomUpMutatorContext) {
 		if a, ok := ctx.Module().(Module); ok {
 			actx := &androidBottomUpMutatorContext{
 				BottomUpMutatorContext: ctx,
 				androidBaseContextImpl: a.base().androidBaseContextFactory(ctx),
 			}
+			defer mutator.timing.record(time.Now())
 			m(actx)
 		}
 	}
-	mutator := &mutator{name: name, bottomUpMutator: f}
 	x.mutators = append(x.mutators, mutator)
 	return mutator
 }
 
 func (x *registerMutatorsContext) TopDown(name string, m AndroidTopDownMutator) MutatorHandle {
-	f := func(ctx blueprint.TopDownMutatorContext) {
+	mutator := &mutator{name: name}
+	mutator.topDownMutator = func(ctx blueprint.TopDownMutatorContext) {
 		if a, ok := ctx.Module().(Module); ok {
 			actx := &androidTopDownMutatorContext{
 				TopDownMutatorContext:  ctx,
 				androidBaseContextImpl: a.base().androidBaseContextFactory(ctx),
 			}
+			defer mutator.timing.record(time.Now())
 			m(actx)
 		}
 	}
-	mutator := &mutator{name: name, topDownMutator: f}
 	x.mutators = append(x.mutators, mutator)
 	return mutator
 }
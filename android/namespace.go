@@ -89,6 +89,9 @@ type NameResolver struct {
 	// Map from dir to namespace. Will have duplicates if two dirs are part of the same namespace.
 	namespacesByDir sync.Map // if generics were supported, this would be sync.Map[string]*Namespace
 
+	// Map from dir to the package module declared in the Android.bp file in that dir.
+	packagesByDir sync.Map // if generics were supported, this would be sync.Map[string]*packageModule
+
 	// func telling whether to export a namespace to Kati
 	namespaceExportFilter func(*Namespace) bool
 }
@@ -144,6 +147,34 @@ func (r *NameResolver) addNamespace(namespace *Namespace) (err error) {
 	return nil
 }
 
+func (r *NameResolver) addPackage(module *packageModule, path string) error {
+	dir := filepath.Dir(path)
+	module.dir = dir
+	if _, exists := r.packagesByDir.LoadOrStore(dir, module); exists {
+		return fmt.Errorf("package %v already exists, there can only be one package per Android.bp file", dir)
+	}
+	return nil
+}
+
+// findPackage returns the package module whose defaults apply to the modules in dir: the package
+// declared in dir if there is one, otherwise the closest package declared in a parent of dir that
+// applies to its subpackages.
+func (r *NameResolver) findPackage(dir string) *packageModule {
+	if p, found := r.packagesByDir.Load(dir); found {
+		return p.(*packageModule)
+	}
+	for {
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			return nil
+		}
+		dir = parentDir
+		if p, found := r.packagesByDir.Load(dir); found && Bool(p.(*packageModule).properties.Apply_to_subpackages) {
+			return p.(*packageModule)
+		}
+	}
+}
+
 // non-recursive check for namespace
 func (r *NameResolver) namespaceAt(path string) (namespace *Namespace, found bool) {
 	mapVal, found := r.namespacesByDir.Load(path)
@@ -179,6 +210,18 @@ func (r *NameResolver) NewModule(ctx blueprint.NamespaceContext, moduleGroup blu
 		return nil, nil
 	}
 
+	// if this module is a package, then save it to our map of packages.  Like namespaces, packages
+	// are not added to a namespace, they are not referenced by name.
+	if p, ok := module.(*packageModule); ok {
+		err := r.addPackage(p, ctx.ModulePath())
+		if err != nil {
+			return nil, []error{err}
+		}
+		p.commonProperties.ModuleDir = p.dir
+		p.commonProperties.NamespacePath = r.findNamespaceFromCtx(ctx).Path
+		return nil, nil
+	}
+
 	// if this module is not a namespace, then save it into the appropriate namespace
 	ns := r.findNamespaceFromCtx(ctx)
 
@@ -191,6 +234,13 @@ func (r *NameResolver) NewModule(ctx blueprint.NamespaceContext, moduleGroup blu
 	if ok {
 		// inform the module whether its namespace is one that we want to export to Make
 		amod.base().commonProperties.NamespaceExportedToMake = ns.exportToKati
+
+		// record where the module is defined so that visibility rules can be checked against it
+		amod.base().commonProperties.ModuleDir = filepath.Dir(ctx.ModulePath())
+		amod.base().commonProperties.NamespacePath = ns.Path
+
+		// record the resolver so that the module can find the package module that applies to it
+		amod.base().nameResolver = r
 	}
 
 	return ns, nil
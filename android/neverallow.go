@@ -40,6 +40,8 @@ import (
 // - - if the property is a list, any of the values in the list being matches
 //     counts as a match
 // - it has none of the "without" properties matched (same rules as above)
+//
+// Rules can also be declared in neverallow.bp policy files, see neverallow_policy.go.
 
 func registerNeverallowMutator(ctx RegisterMutatorsContext) {
 	ctx.BottomUp("neverallow", neverallowMutator).Parallel()
@@ -149,7 +151,7 @@ func neverallowMutator(ctx BottomUpMutatorContext) {
 	dir := ctx.ModuleDir() + "/"
 	properties := m.GetProperties()
 
-	for _, n := range neverallows {
+	for _, n := range neverallowRules(ctx) {
 		if !n.appliesToPath(dir) {
 			continue
 		}
@@ -183,6 +185,9 @@ type rule struct {
 
 	props       []ruleProperty
 	unlessProps []ruleProperty
+
+	// Location of the rule in a policy file, empty for built-in rules.
+	source string
 }
 
 func neverallow() *rule {
@@ -253,6 +258,9 @@ func (r *rule) String() string {
 	if len(r.reason) != 0 {
 		s += " which is restricted because " + r.reason
 	}
+	if r.source != "" {
+		s += " (declared at " + r.source + ")"
+	}
 	return s
 }
 
@@ -0,0 +1,237 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/scanner"
+
+	"github.com/google/blueprint/parser"
+)
+
+// Neverallow rules declared in policy files.
+//
+// In addition to the rules compiled into soong by createNeverAllows(), any directory may contain a
+// neverallow.bp policy file.  The policy files are found by soong_ui together with the Android.bp
+// files and listed in neverallow.bp.list next to Android.bp.list.  The policy file uses the
+// Blueprint syntax and contains one or more neverallow blocks, each of which is equivalent to a
+// rule built with neverallow():
+//
+//     neverallow {
+//         in: ["vendor/foo"],
+//         not_in: ["vendor/foo/legacy"],
+//         module_types: ["cc_library", "cc_library_shared"],
+//         not_module_types: ["cc_library_static"],
+//         with: ["vndk.enabled=true"],
+//         without: ["vendor=true"],
+//         because: "vendor/foo may not provide VNDK libraries",
+//     }
+//
+// A rule applies to the directory containing the policy file and all of its subdirectories, or to
+// the whole tree for a policy file in the root directory.  It can be restricted further with in,
+// which may only list paths within that subtree so that a policy file can't restrict other
+// projects.  Properties in with and without are written as <property>=<value>, following the same
+// matching rules as the built-in rules.  Errors for modules that violate a rule from a policy file
+// include the location of the rule in the policy file.
+
+const neverallowPolicyFile = "neverallow.bp"
+
+var neverallowRulesKey = NewOnceKey("neverallowRules")
+
+// neverallowRules returns the built-in rules followed by the rules from all policy files, sorted by
+// the directory of the policy file so that errors are reported deterministically.  The policy files
+// are read once, by the first module that is checked.
+func neverallowRules(ctx BottomUpMutatorContext) []*rule {
+	return ctx.Config().Once(neverallowRulesKey, func() interface{} {
+		rules := append([]*rule(nil), neverallows...)
+		for _, policyFile := range neverallowPolicyFiles(ctx) {
+			rules = append(rules, readNeverallowPolicy(ctx, policyFile)...)
+		}
+		return rules
+	}).([]*rule)
+}
+
+// neverallowPolicyFiles returns the sorted list of policy files in the list written by soong_ui.
+func neverallowPolicyFiles(ctx BottomUpMutatorContext) []string {
+	listFile := ctx.Config().NeverallowPolicyList()
+	if listFile == "" {
+		return nil
+	}
+
+	// Adding or removing a policy file changes the list, which reruns soong.
+	ctx.AddNinjaFileDeps(listFile)
+
+	f, err := ctx.Fs().Open(listFile)
+	if err != nil {
+		ctx.ModuleErrorf("failed to open %s: %s", listFile, err.Error())
+		return nil
+	}
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		ctx.ModuleErrorf("failed to read %s: %s", listFile, err.Error())
+		return nil
+	}
+
+	var files []string
+	for _, file := range strings.Fields(string(data)) {
+		file = filepath.Clean(file)
+		if filepath.Base(file) == neverallowPolicyFile {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func readNeverallowPolicy(ctx BottomUpMutatorContext, policyFile string) []*rule {
+	ctx.AddNinjaFileDeps(policyFile)
+
+	f, err := ctx.Fs().Open(policyFile)
+	if err != nil {
+		ctx.ModuleErrorf("failed to open %s: %s", policyFile, err.Error())
+		return nil
+	}
+	defer f.Close()
+
+	file, errs := parser.ParseAndEval(policyFile, f, parser.NewScope(nil))
+	if len(errs) > 0 {
+		for _, err := range errs {
+			if parseErr, ok := err.(*parser.ParseError); ok {
+				ctx.Errorf(parseErr.Pos, "%s", parseErr.Err.Error())
+			} else {
+				ctx.Errorf(scanner.Position{Filename: policyFile}, "%s", err.Error())
+			}
+		}
+		return nil
+	}
+
+	dir := filepath.Dir(policyFile)
+
+	var rules []*rule
+	for _, def := range file.Defs {
+		module, ok := def.(*parser.Module)
+		if !ok {
+			continue
+		}
+		if module.Type != "neverallow" {
+			ctx.Errorf(module.TypePos, "unsupported policy type %q, expected \"neverallow\"",
+				module.Type)
+			continue
+		}
+		if r := parseNeverallowPolicyRule(ctx, dir, module); r != nil {
+			rules = append(rules, r)
+		}
+	}
+
+	return rules
+}
+
+func parseNeverallowPolicyRule(ctx BottomUpMutatorContext, dir string, module *parser.Module) *rule {
+	r := neverallow()
+	r.source = module.TypePos.String()
+
+	failed := false
+	errorf := func(pos scanner.Position, format string, args ...interface{}) {
+		ctx.Errorf(pos, format, args...)
+		failed = true
+	}
+
+	hasIn := false
+	for _, prop := range module.Properties {
+		switch prop.Name {
+		case "in", "not_in", "module_types", "not_module_types", "with", "without":
+			list, ok := parserStringList(prop)
+			if !ok {
+				errorf(prop.NamePos, "%s must be a list of strings", prop.Name)
+				continue
+			}
+			switch prop.Name {
+			case "in":
+				for _, path := range list {
+					if dir != "." && !isAncestor(dir, filepath.Clean(path)) {
+						errorf(prop.NamePos, "in entry %q must be within %s, the directory of the policy file",
+							path, dir)
+					}
+				}
+				hasIn = true
+				r.in(list...)
+			case "not_in":
+				r.notIn(list...)
+			case "module_types":
+				r.moduleType(list...)
+			case "not_module_types":
+				r.notModuleType(list...)
+			case "with", "without":
+				for _, s := range list {
+					i := strings.Index(s, "=")
+					if i < 1 {
+						errorf(prop.NamePos, "%s entry %q must be of the form <property>=<value>",
+							prop.Name, s)
+						continue
+					}
+					if prop.Name == "with" {
+						r.with(s[:i], s[i+1:])
+					} else {
+						r.without(s[:i], s[i+1:])
+					}
+				}
+			}
+		case "because":
+			s, ok := prop.Value.Eval().(*parser.String)
+			if !ok {
+				errorf(prop.NamePos, "because must be a string")
+				continue
+			}
+			r.because(s.Value)
+		default:
+			errorf(prop.NamePos, "unrecognized neverallow property %q", prop.Name)
+		}
+	}
+
+	if !hasIn && dir != "." {
+		r.in(dir)
+	}
+
+	if failed {
+		return nil
+	}
+
+	return r
+}
+
+func parserStringList(prop *parser.Property) ([]string, bool) {
+	if prop == nil {
+		return nil, false
+	}
+	list, ok := prop.Value.Eval().(*parser.List)
+	if !ok {
+		return nil, false
+	}
+
+	ret := make([]string, 0, len(list.Values))
+	for _, v := range list.Values {
+		s, ok := v.Eval().(*parser.String)
+		if !ok {
+			return nil, false
+		}
+		ret = append(ret, s.Value)
+	}
+	return ret, true
+}
@@ -17,6 +17,8 @@ package android
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -159,6 +161,116 @@ var neverallowTests = []struct {
 		},
 		expectedError: "java_device_for_host can only be used in whitelisted projects",
 	},
+	{
+		name: "rule from policy file",
+		fs: map[string][]byte{
+			"vendor/foo/neverallow.bp": []byte(`
+				neverallow {
+					module_types: ["cc_library"],
+					with: ["vendor_available=true"],
+					because: "libraries in vendor/foo must not be vendor available",
+				}`),
+			"vendor/foo/Blueprints": []byte(`
+				cc_library {
+					name: "libfoo",
+					vendor_available: true,
+				}`),
+		},
+		expectedError: `libraries in vendor/foo must not be vendor available \(declared at vendor/foo/neverallow.bp:`,
+	},
+	{
+		name: "rule from policy file only applies to its directory",
+		fs: map[string][]byte{
+			"vendor/foo/neverallow.bp": []byte(`
+				neverallow {
+					module_types: ["cc_library"],
+					with: ["vendor_available=true"],
+					because: "libraries in vendor/foo must not be vendor available",
+				}`),
+			"vendor/foo/Blueprints": []byte(`
+				cc_library {
+					name: "libfoo",
+				}`),
+			"vendor/bar/Blueprints": []byte(`
+				cc_library {
+					name: "libbar",
+					vendor_available: true,
+				}`),
+		},
+		expectedError: "",
+	},
+	{
+		name: "rule from policy file with explicit paths",
+		fs: map[string][]byte{
+			"vendor/foo/neverallow.bp": []byte(`
+				neverallow {
+					in: ["vendor/foo"],
+					not_in: ["vendor/foo/internal"],
+					with: ["libs=libfoo_internal"],
+					because: "libfoo_internal is internal to vendor/foo/internal",
+				}`),
+			"vendor/foo/internal/Blueprints": []byte(`
+				java_library {
+					name: "libfoo",
+					libs: ["libfoo_internal"],
+				}`),
+			"vendor/foo/bar/Blueprints": []byte(`
+				java_library {
+					name: "libbar",
+					libs: ["libfoo_internal"],
+				}`),
+		},
+		expectedError: `module "libbar": violates neverallow dir:vendor/foo/\* -dir:vendor/foo/internal/\* ` +
+			`libs=libfoo_internal which is restricted because libfoo_internal is internal to vendor/foo/internal`,
+	},
+	{
+		name: "rule from policy file in directory without modules",
+		fs: map[string][]byte{
+			"vendor/neverallow.bp": []byte(`
+				neverallow {
+					module_types: ["cc_library"],
+					with: ["vendor_available=true"],
+					because: "libraries in vendor must not be vendor available",
+				}`),
+			"vendor/bar/Blueprints": []byte(`
+				cc_library {
+					name: "libbar",
+					vendor_available: true,
+				}`),
+		},
+		expectedError: `libraries in vendor must not be vendor available \(declared at vendor/neverallow.bp:`,
+	},
+	{
+		name: "rule from policy file outside its directory",
+		fs: map[string][]byte{
+			"vendor/foo/neverallow.bp": []byte(`
+				neverallow {
+					in: ["frameworks"],
+					module_types: ["cc_library"],
+				}`),
+			"vendor/foo/Blueprints": []byte(`
+				cc_library {
+					name: "libfoo",
+				}`),
+		},
+		expectedError: `vendor/foo/neverallow.bp:.*: in entry "frameworks" must be within vendor/foo, ` +
+			`the directory of the policy file`,
+	},
+	{
+		name: "invalid policy file",
+		fs: map[string][]byte{
+			"vendor/foo/neverallow.bp": []byte(`
+				neverallow {
+					with: ["vendor_available"],
+					paths: ["vendor"],
+				}`),
+			"vendor/foo/Blueprints": []byte(`
+				cc_library {
+					name: "libfoo",
+				}`),
+		},
+		expectedError: `vendor/foo/neverallow.bp:.*: unrecognized neverallow property "paths"`,
+	},
 }
 
 func TestNeverallow(t *testing.T) {
@@ -168,10 +280,11 @@ func TestNeverallow(t *testing.T) {
 	}
 	defer os.RemoveAll(buildDir)
 
-	config := TestConfig(buildDir, nil)
-
 	for _, test := range neverallowTests {
 		t.Run(test.name, func(t *testing.T) {
+			// Create a new config per test as the rules from policy files are stored in the config.
+			config := TestConfig(buildDir, nil)
+
 			_, errs := testNeverallow(t, config, test.fs)
 
 			if test.expectedError == "" {
@@ -191,7 +304,22 @@ func testNeverallow(t *testing.T, config Config, fs map[string][]byte) (*TestCon
 	ctx.PostDepsMutators(registerNeverallowMutator)
 	ctx.Register()
 
-	ctx.MockFileSystem(fs)
+	// soong_ui lists the policy files found in the tree.
+	var policyFiles []string
+	for file := range fs {
+		if filepath.Base(file) == neverallowPolicyFile {
+			policyFiles = append(policyFiles, file)
+		}
+	}
+	mockFS := map[string][]byte{
+		"neverallow.bp.list": []byte(strings.Join(policyFiles, "\n")),
+	}
+	for file, contents := range fs {
+		mockFS[file] = contents
+	}
+	config.SetNeverallowPolicyList("neverallow.bp.list")
+
+	ctx.MockFileSystem(mockFS)
 
 	_, errs := ctx.ParseBlueprintsFiles("Blueprints")
 	if len(errs) > 0 {
@@ -0,0 +1,81 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"github.com/google/blueprint"
+)
+
+func init() {
+	RegisterModuleType("package", PackageFactory)
+}
+
+type packageProperties struct {
+	// Specifies the default visibility for all modules defined in this package, used by modules
+	// that do not set their own visibility property.  See the visibility property for the allowed
+	// values.
+	Default_visibility []string
+
+	// Specifies the default licenses for all modules defined in this package, used by modules that
+	// do not set their own licenses property.
+	Default_applicable_licenses []string
+
+	// If true, the defaults also apply to the modules defined in subdirectories of this package
+	// that do not declare a package of their own.
+	Apply_to_subpackages *bool
+}
+
+type packageModule struct {
+	ModuleBase
+
+	properties packageProperties
+
+	// The directory of the Android.bp file that declares the package, set by NameResolver.
+	dir string
+
+	// True once default_visibility has been checked without errors, so that modules can use it.
+	defaultVisibilityChecked bool
+}
+
+func (p *packageModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+}
+
+func (p *packageModule) GenerateBuildActions(ctx blueprint.ModuleContext) {
+}
+
+func (p *packageModule) Name() string {
+	return *p.nameProperties.Name
+}
+
+// packageModule returns the package module whose defaults apply to the module, or nil.
+func (a *ModuleBase) packageModule() *packageModule {
+	if a.nameResolver == nil {
+		return nil
+	}
+	return a.nameResolver.findPackage(a.commonProperties.ModuleDir)
+}
+
+// package modules hold the defaults for the properties of all modules defined in the same
+// Android.bp file and, with apply_to_subpackages, in its subdirectories.  There can be at most one
+// package module per Android.bp file.  See ../README.md#Packages.
+func PackageFactory() Module {
+	module := &packageModule{}
+
+	name := "package"
+	module.nameProperties.Name = &name
+
+	module.AddProperties(&module.properties)
+	return module
+}
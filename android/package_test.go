@@ -0,0 +1,118 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestTwoPackagesInSameFile(t *testing.T) {
+	_, errs := setupPackageTest(map[string]string{
+		"dir1": `
+			package {
+			}
+			package {
+			}
+			`,
+	})
+
+	expectedErrors := []error{
+		errors.New(`dir1/Android.bp:4:4: package dir1 already exists, there can only be one package per Android.bp file`),
+	}
+	if len(errs) != 1 || errs[0].Error() != expectedErrors[0].Error() {
+		t.Errorf("Incorrect errors. Expected:\n%v\n, got:\n%v\n", expectedErrors, errs)
+	}
+}
+
+func TestFindPackage(t *testing.T) {
+	ctx, errs := setupPackageTest(map[string]string{
+		"a": `
+			package {
+				apply_to_subpackages: true,
+			}
+			`,
+		"a/b": `
+			package {
+			}
+			test_module {
+				name: "b",
+			}
+			`,
+		"a/b/c": `
+			test_module {
+				name: "c",
+			}
+			`,
+		"d": `
+			package {
+			}
+			`,
+		"d/e": `
+			test_module {
+				name: "e",
+			}
+			`,
+	})
+	FailIfErrored(t, errs)
+
+	expectedPackages := map[string]string{
+		// The package in the same directory always applies.
+		"b": "a/b",
+		// Packages in parent directories only apply if they apply to subpackages.
+		"c": "a",
+		"e": "",
+	}
+	for name, expected := range expectedPackages {
+		actual := ""
+		if p := ctx.ModuleForTests(name, "").Module().base().packageModule(); p != nil {
+			actual = p.dir
+		}
+		if actual != expected {
+			t.Errorf("expected the package of %s to be %q, got %q", name, expected, actual)
+		}
+	}
+}
+
+func setupPackageTest(bps map[string]string) (*TestContext, []error) {
+	files := make(map[string][]byte, len(bps))
+	files["Android.bp"] = []byte("")
+	for dir, text := range bps {
+		files[dir+"/Android.bp"] = []byte(text)
+	}
+
+	buildDir, err := ioutil.TempDir("", "soong_package_test")
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer os.RemoveAll(buildDir)
+
+	config := TestConfig(buildDir, nil)
+
+	ctx := NewTestContext()
+	ctx.MockFileSystem(files)
+	ctx.RegisterModuleType("test_module", ModuleFactoryAdaptor(newTestModule))
+	ctx.RegisterModuleType("package", ModuleFactoryAdaptor(PackageFactory))
+	ctx.Register()
+
+	_, errs := ctx.ParseBlueprintsFiles("Android.bp")
+	if len(errs) > 0 {
+		return ctx, errs
+	}
+	_, errs = ctx.PrepareBuildActions(config)
+	return ctx, errs
+}
@@ -38,6 +38,9 @@ type mutator struct {
 	bottomUpMutator blueprint.BottomUpMutator
 	topDownMutator  blueprint.TopDownMutator
 	parallel        bool
+
+	// Records how long the mutator ran, for the soong_build metrics.
+	timing mutatorTiming
 }
 
 type ModuleFactory func() Module
@@ -79,10 +82,13 @@ func RegisterPreSingletonType(name string, factory SingletonFactory) {
 
 type Context struct {
 	*blueprint.Context
+
+	// The mutators registered by Register, in the order they run.
+	mutators []*mutator
 }
 
 func NewContext() *Context {
-	return &Context{blueprint.NewContext()}
+	return &Context{Context: blueprint.NewContext()}
 }
 
 func (ctx *Context) Register() {
@@ -98,7 +104,7 @@ func (ctx *Context) Register() {
 		ctx.RegisterSingletonType(t.name, t.factory)
 	}
 
-	registerMutators(ctx.Context, preArch, preDeps, postDeps)
+	ctx.mutators = registerMutators(ctx.Context, preArch, preDeps, postDeps)
 
 	// Register makevars after other singletons so they can export values through makevars
 	ctx.RegisterSingletonType("makevars", SingletonFactoryAdaptor(makeVarsSingletonFunc))
@@ -0,0 +1,74 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/google/blueprint/proptools"
+)
+
+// Remote execution of RuleBuilder rules and genrules.
+//
+// When USE_REMOTE_EXEC=true is set the commands of RuleBuilder rules and genrules are wrapped with
+// the rexec client (cmd/rexec), which stages the declared inputs and tools into a content
+// addressable store, executes the command against a Remote Execution API (REAPI) v2 service over
+// gRPC and downloads the declared outputs.  The service is selected when the command runs with the
+// REMOTE_EXEC_SERVER (a gRPC address, prefixed with grpcs:// for TLS) and REMOTE_EXEC_INSTANCE
+// environment variables, so changing them does not rerun Soong.  Setting
+// REMOTE_EXEC_LOCAL_FALLBACK=true runs commands locally when the service cannot be reached.
+// cmd/rexec_server is a local stand-in for a remote execution service.
+//
+// The inputs are passed to rexec in a ninja rspfile so that long input lists don't exceed the
+// command line length limit.
+
+// RemoteExecTool returns the path to the remote execution client.
+func RemoteExecTool(ctx PathContext) Path {
+	return ctx.Config().HostToolPath(ctx, "rexec")
+}
+
+// RemoteExecCommand wraps a ninja escaped shell command line so that it runs through the remote
+// execution client.  The inputs and tools of the command must be listed in inputsListFile, which
+// is usually the rspfile of the rule, and the outputs are downloaded once the command completes.
+func RemoteExecCommand(ctx PathContext, command string, outputs []string, inputsListFile string) string {
+	var sb strings.Builder
+	sb.WriteString(RemoteExecTool(ctx).String())
+	sb.WriteString(" --inputs_list ")
+	sb.WriteString(inputsListFile)
+	for _, output := range outputs {
+		sb.WriteString(" --output ")
+		sb.WriteString(proptools.NinjaEscape(output))
+	}
+	// Commands are run with an empty environment, pass through PATH so that they can find the
+	// standard tools.
+	sb.WriteString(" --env_var PATH -- /bin/bash -c ")
+	sb.WriteString(proptools.ShellEscape(command))
+	return sb.String()
+}
+
+// StagedInputs returns the inputs and tools of a command that should be staged by sbox or the remote
+// execution client.  Absolute paths and bare command names like "cp", which are found through PATH
+// on the machine that runs the command, are not in the source or output tree and are dropped.
+func StagedInputs(paths []string) []string {
+	var ret []string
+	for _, path := range paths {
+		if filepath.IsAbs(path) || !strings.Contains(path, "/") {
+			continue
+		}
+		ret = append(ret, path)
+	}
+	return ret
+}
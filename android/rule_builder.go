@@ -16,11 +16,14 @@ package android
 
 import (
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/google/blueprint"
 	"github.com/google/blueprint/proptools"
+
+	"android/soong/shared"
 )
 
 // RuleBuilder provides an alternative to ModuleContext.Rule and ModuleContext.Build to add a command line to the build
@@ -30,6 +33,8 @@ type RuleBuilder struct {
 	installs       RuleBuilderInstalls
 	temporariesSet map[WritablePath]bool
 	restat         bool
+	sbox           bool
+	sboxOutDir     WritablePath
 	missingDeps    []string
 }
 
@@ -78,6 +83,22 @@ func (r *RuleBuilder) Restat() *RuleBuilder {
 	return r
 }
 
+// Sbox marks the rule as needing to be wrapped by sbox.  The commands will be run in a sandbox that contains only
+// the inputs and tools of the rule, and the outputs will be written to a temporary directory and then moved into
+// outputDir, which all outputs of the rule must be in.  Writing files that are not outputs of the rule is an error,
+// and paths on the command line that were not declared as inputs are reported.  Sbox must be called before Command.
+func (r *RuleBuilder) Sbox(outputDir WritablePath) *RuleBuilder {
+	if r.sbox {
+		panic("Sbox() may not be called more than once")
+	}
+	if len(r.commands) > 0 {
+		panic("Sbox() may not be called after Command()")
+	}
+	r.sbox = true
+	r.sboxOutDir = outputDir
+	return r
+}
+
 // Install associates an output of the rule with an install location, which can be retrieved later using
 // RuleBuilder.Installs.
 func (r *RuleBuilder) Install(from Path, to string) {
@@ -88,7 +109,10 @@ func (r *RuleBuilder) Install(from Path, to string) {
 // created by this method.  That can be mutated through their methods in any order, as long as the mutations do not
 // race with any call to Build.
 func (r *RuleBuilder) Command() *RuleBuilderCommand {
-	command := &RuleBuilderCommand{}
+	command := &RuleBuilderCommand{
+		sbox:       r.sbox,
+		sboxOutDir: r.sboxOutDir,
+	}
 	r.commands = append(r.commands, command)
 	return command
 }
@@ -238,11 +262,44 @@ func (r *RuleBuilder) Build(pctx PackageContext, ctx BuilderContext, name string
 	}
 
 	if len(r.Commands()) > 0 {
+		command := strings.Join(proptools.NinjaEscapeList(r.Commands()), " && ")
+		commandDeps := r.Tools().Strings()
+		var rspfile, rspfileContent string
+		var rspfileInputs []string
+
+		if r.sbox {
+			// sbox stages the inputs and tools listed in the rspfile into the sandbox.  The rspfile can't be
+			// in the output directory, which sbox deletes before running the command.
+			rspfile = r.sboxOutDir.String() + ".rsp"
+			rspfileInputs = append(r.Inputs().Strings(), commandDeps...)
+			command = r.sboxCommand(ctx, command, rspfile)
+			commandDeps = append(commandDeps, SboxTool(ctx).String())
+		}
+
+		if ctx.Config().UseRemoteExec() && len(r.Outputs()) > 0 {
+			// The remote execution client needs the inputs and tools to stage them, including the rspfile
+			// read by sbox.
+			if rspfile == "" {
+				rspfile = r.Outputs()[0].String() + ".rsp"
+				rspfileInputs = append(r.Inputs().Strings(), commandDeps...)
+			} else {
+				rspfileInputs = append(rspfileInputs, SboxTool(ctx).String(), rspfile)
+			}
+			command = RemoteExecCommand(ctx, command, r.Outputs().Strings(), proptools.NinjaEscape(rspfile))
+			commandDeps = append(commandDeps, RemoteExecTool(ctx).String())
+		}
+
+		if rspfile != "" {
+			rspfileContent = strings.Join(proptools.NinjaEscapeList(StagedInputs(rspfileInputs)), " ")
+		}
+
 		ctx.Build(pctx, BuildParams{
 			Rule: ctx.Rule(pctx, name, blueprint.RuleParams{
-				Command:     strings.Join(proptools.NinjaEscapeList(r.Commands()), " && "),
-				CommandDeps: r.Tools().Strings(),
-				Restat:      r.restat,
+				Command:        command,
+				CommandDeps:    commandDeps,
+				Restat:         r.restat,
+				Rspfile:        rspfile,
+				RspfileContent: rspfileContent,
 			}),
 			Implicits:   r.Inputs(),
 			Outputs:     r.Outputs(),
@@ -251,6 +308,37 @@ func (r *RuleBuilder) Build(pctx PackageContext, ctx BuilderContext, name string
 	}
 }
 
+// SboxTool returns the path to the sbox tool.
+func SboxTool(ctx PathContext) Path {
+	return ctx.Config().HostToolPath(ctx, "sbox")
+}
+
+// sboxCommand wraps a ninja escaped command line with sbox.
+func (r *RuleBuilder) sboxCommand(ctx BuilderContext, command string, inputsList string) string {
+	var outputs []string
+	for _, output := range r.Outputs() {
+		rel, isRel := MaybeRel(ctx, r.sboxOutDir.String(), output.String())
+		if !isRel {
+			reportPathErrorf(ctx, "output path %q is not under sbox output directory %q", output,
+				r.sboxOutDir)
+			continue
+		}
+		outputs = append(outputs, "__SBOX_OUT_DIR__/"+rel)
+	}
+
+	sboxCmd := &RuleBuilderCommand{}
+	sboxCmd.Text(SboxTool(ctx).String()).
+		Flag("-c").Text(proptools.ShellEscape(command)).
+		Flag("--sandbox-path").Text(shared.TempDirForOutDir(PathForOutput(ctx).String())).
+		Flag("--output-root").Text(r.sboxOutDir.String()).
+		Flag("--inputs-list").Text(inputsList)
+	for _, output := range outputs {
+		sboxCmd.Text(output)
+	}
+
+	return sboxCmd.String()
+}
+
 // RuleBuilderCommand is a builder for a command in a command line.  It can be mutated by its methods to add to the
 // command and track dependencies.  The methods mutate the RuleBuilderCommand in place, as well as return the
 // RuleBuilderCommand, so they can be used chained or unchained.  All methods that add text implicitly add a single
@@ -260,6 +348,23 @@ type RuleBuilderCommand struct {
 	inputs  Paths
 	outputs WritablePaths
 	tools   Paths
+
+	sbox       bool
+	sboxOutDir WritablePath
+}
+
+// outputStr returns the path to use for an output on the command line, which is relative to the sandbox output
+// directory if the rule is using sbox.
+func (c *RuleBuilderCommand) outputStr(path WritablePath) string {
+	if c.sbox {
+		// Outputs that are not in the output directory are reported by RuleBuilder.Build, where there is a
+		// context to report them to.
+		rel, err := filepath.Rel(c.sboxOutDir.String(), path.String())
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return "__SBOX_OUT_DIR__/" + rel
+		}
+	}
+	return path.String()
 }
 
 // Text adds the specified raw text to the command line.  The text should not contain input or output paths or the
@@ -348,7 +453,7 @@ func (c *RuleBuilderCommand) Implicits(paths Paths) *RuleBuilderCommand {
 // RuleBuilder.Outputs.
 func (c *RuleBuilderCommand) Output(path WritablePath) *RuleBuilderCommand {
 	c.outputs = append(c.outputs, path)
-	return c.Text(path.String())
+	return c.Text(c.outputStr(path))
 }
 
 // Outputs adds the specified output paths to the command line, separated by spaces.  The paths will also be added to
@@ -403,7 +508,7 @@ func (c *RuleBuilderCommand) FlagForEachInput(flag string, paths Paths) *RuleBui
 // will also be added to the outputs returned by RuleBuilder.Outputs.
 func (c *RuleBuilderCommand) FlagWithOutput(flag string, path WritablePath) *RuleBuilderCommand {
 	c.outputs = append(c.outputs, path)
-	return c.Text(flag + path.String())
+	return c.Text(flag + c.outputStr(path))
 }
 
 // String returns the command line.
@@ -293,6 +293,27 @@ func TestRuleBuilder(t *testing.T) {
 	}
 }
 
+func TestRuleBuilder_SboxOutputs(t *testing.T) {
+	ctx := pathContext()
+
+	rule := NewRuleBuilder().Sbox(PathForOutput(ctx, "gen"))
+	rule.Command().
+		Tool(PathForSource(ctx, "ld")).
+		Input(PathForSource(ctx, "a")).
+		FlagWithOutput("-o ", PathForOutput(ctx, "gen/b")).
+		ImplicitOutput(PathForOutput(ctx, "gen/c"))
+
+	wantCommands := []string{"ld a -o __SBOX_OUT_DIR__/b"}
+	if !reflect.DeepEqual(rule.Commands(), wantCommands) {
+		t.Errorf("\nwant rule.Commands() = %#v\n                   got %#v", wantCommands, rule.Commands())
+	}
+
+	wantOutputs := PathsForOutput(ctx, []string{"gen/b", "gen/c"})
+	if !reflect.DeepEqual(rule.Outputs(), wantOutputs) {
+		t.Errorf("\nwant rule.Outputs() = %#v\n                  got %#v", wantOutputs, rule.Outputs())
+	}
+}
+
 func testRuleBuilderFactory() Module {
 	module := &testRuleBuilderModule{}
 	module.AddProperties(&module.properties)
@@ -304,14 +325,22 @@ type testRuleBuilderModule struct {
 	ModuleBase
 	properties struct {
 		Src string
+
+		Sbox bool
 	}
 }
 
 func (t *testRuleBuilderModule) GenerateAndroidBuildActions(ctx ModuleContext) {
 	in := PathForSource(ctx, t.properties.Src)
-	out := PathForModuleOut(ctx, ctx.ModuleName())
 
-	testRuleBuilder_Build(ctx, in, out)
+	if t.properties.Sbox {
+		outDir := PathForModuleOut(ctx, "gen")
+		out := PathForModuleOut(ctx, "gen", ctx.ModuleName())
+		testRuleBuilder_Build(ctx, in, out, outDir)
+	} else {
+		out := PathForModuleOut(ctx, ctx.ModuleName())
+		testRuleBuilder_Build(ctx, in, out, nil)
+	}
 }
 
 type testRuleBuilderSingleton struct{}
@@ -323,12 +352,16 @@ func testRuleBuilderSingletonFactory() Singleton {
 func (t *testRuleBuilderSingleton) GenerateBuildActions(ctx SingletonContext) {
 	in := PathForSource(ctx, "bar")
 	out := PathForOutput(ctx, "baz")
-	testRuleBuilder_Build(ctx, in, out)
+	testRuleBuilder_Build(ctx, in, out, nil)
 }
 
-func testRuleBuilder_Build(ctx BuilderContext, in Path, out WritablePath) {
+func testRuleBuilder_Build(ctx BuilderContext, in Path, out WritablePath, sboxOutDir WritablePath) {
 	rule := NewRuleBuilder()
 
+	if sboxOutDir != nil {
+		rule.Sbox(sboxOutDir)
+	}
+
 	rule.Command().Tool(PathForSource(ctx, "cp")).Input(in).Output(out)
 
 	rule.Restat()
@@ -336,21 +369,7 @@ func testRuleBuilder_Build(ctx BuilderContext, in Path, out WritablePath) {
 	rule.Build(pctx, ctx, "rule", "desc")
 }
 
-func TestRuleBuilder_Build(t *testing.T) {
-	buildDir, err := ioutil.TempDir("", "soong_test_rule_builder")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.RemoveAll(buildDir)
-
-	bp := `
-		rule_builder_test {
-			name: "foo",
-			src: "bar",
-		}
-	`
-
-	config := TestConfig(buildDir, nil)
+func testRuleBuilderContext(t *testing.T, config Config, bp string) *TestContext {
 	ctx := NewTestContext()
 	ctx.MockFileSystem(map[string][]byte{
 		"Android.bp": []byte(bp),
@@ -366,6 +385,25 @@ func TestRuleBuilder_Build(t *testing.T) {
 	_, errs = ctx.PrepareBuildActions(config)
 	FailIfErrored(t, errs)
 
+	return ctx
+}
+
+func TestRuleBuilder_Build(t *testing.T) {
+	buildDir, err := ioutil.TempDir("", "soong_test_rule_builder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	bp := `
+		rule_builder_test {
+			name: "foo",
+			src: "bar",
+		}
+	`
+
+	ctx := testRuleBuilderContext(t, TestConfig(buildDir, nil), bp)
+
 	check := func(t *testing.T, params TestingBuildParams, wantOutput string) {
 		if len(params.RuleParams.CommandDeps) != 1 || params.RuleParams.CommandDeps[0] != "cp" {
 			t.Errorf("want RuleParams.CommandDeps = [%q], got %q", "cp", params.RuleParams.CommandDeps)
@@ -393,3 +431,102 @@ func TestRuleBuilder_Build(t *testing.T) {
 			filepath.Join(buildDir, "baz"))
 	})
 }
+
+func TestRuleBuilder_BuildRemoteExec(t *testing.T) {
+	buildDir, err := ioutil.TempDir("", "soong_test_rule_builder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	bp := `
+		rule_builder_test {
+			name: "foo",
+			src: "bar",
+		}
+	`
+
+	config := TestConfig(buildDir, map[string]string{"USE_REMOTE_EXEC": "true"})
+	ctx := testRuleBuilderContext(t, config, bp)
+
+	check := func(t *testing.T, params TestingBuildParams, wantOutput string) {
+		rexec := RemoteExecTool(PathContextForTesting(config, nil)).String()
+
+		wantCommand := rexec + " --inputs_list " + wantOutput + ".rsp --output " + wantOutput +
+			" --env_var PATH -- /bin/bash -c 'cp bar " + wantOutput + "'"
+		if params.RuleParams.Command != wantCommand {
+			t.Errorf("want RuleParams.Command = %q, got %q", wantCommand, params.RuleParams.Command)
+		}
+
+		wantCommandDeps := []string{"cp", rexec}
+		if !reflect.DeepEqual(params.RuleParams.CommandDeps, wantCommandDeps) {
+			t.Errorf("want RuleParams.CommandDeps = %q, got %q", wantCommandDeps, params.RuleParams.CommandDeps)
+		}
+
+		if params.RuleParams.Rspfile != wantOutput+".rsp" {
+			t.Errorf("want RuleParams.Rspfile = %q, got %q", wantOutput+".rsp", params.RuleParams.Rspfile)
+		}
+
+		// cp is found through PATH, it is not staged.
+		if params.RuleParams.RspfileContent != "bar" {
+			t.Errorf("want RuleParams.RspfileContent = %q, got %q", "bar", params.RuleParams.RspfileContent)
+		}
+	}
+
+	t.Run("module", func(t *testing.T) {
+		check(t, ctx.ModuleForTests("foo", "").Rule("rule"),
+			filepath.Join(buildDir, ".intermediates", "foo", "foo"))
+	})
+	t.Run("singleton", func(t *testing.T) {
+		check(t, ctx.SingletonForTests("rule_builder_test").Rule("rule"),
+			filepath.Join(buildDir, "baz"))
+	})
+}
+
+func TestRuleBuilder_BuildSbox(t *testing.T) {
+	buildDir, err := ioutil.TempDir("", "soong_test_rule_builder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	bp := `
+		rule_builder_test {
+			name: "foo",
+			src: "bar",
+			sbox: true,
+		}
+	`
+
+	config := TestConfig(buildDir, nil)
+	ctx := testRuleBuilderContext(t, config, bp)
+
+	params := ctx.ModuleForTests("foo", "").Rule("rule")
+
+	sbox := SboxTool(PathContextForTesting(config, nil)).String()
+	outDir := filepath.Join(buildDir, ".intermediates", "foo", "gen")
+	wantOutput := filepath.Join(outDir, "foo")
+
+	wantCommand := sbox + " -c 'cp bar __SBOX_OUT_DIR__/foo' --sandbox-path " + filepath.Join(buildDir, ".temp") +
+		" --output-root " + outDir + " --inputs-list " + outDir + ".rsp __SBOX_OUT_DIR__/foo"
+	if params.RuleParams.Command != wantCommand {
+		t.Errorf("want RuleParams.Command = %q, got %q", wantCommand, params.RuleParams.Command)
+	}
+
+	wantCommandDeps := []string{"cp", sbox}
+	if !reflect.DeepEqual(params.RuleParams.CommandDeps, wantCommandDeps) {
+		t.Errorf("want RuleParams.CommandDeps = %q, got %q", wantCommandDeps, params.RuleParams.CommandDeps)
+	}
+
+	if params.RuleParams.Rspfile != outDir+".rsp" {
+		t.Errorf("want RuleParams.Rspfile = %q, got %q", outDir+".rsp", params.RuleParams.Rspfile)
+	}
+
+	if params.RuleParams.RspfileContent != "bar" {
+		t.Errorf("want RuleParams.RspfileContent = %q, got %q", "bar", params.RuleParams.RspfileContent)
+	}
+
+	if len(params.Outputs) != 1 || params.Outputs[0].String() != wantOutput {
+		t.Errorf("want Outputs = [%q], got %q", wantOutput, params.Outputs.Strings())
+	}
+}
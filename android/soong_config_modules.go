@@ -0,0 +1,604 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+// This file implements module types that create new module types whose properties are affected by
+// variables set in the product config.
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/scanner"
+
+	"github.com/google/blueprint/parser"
+	"github.com/google/blueprint/proptools"
+)
+
+func init() {
+	RegisterModuleType("soong_config_module_type", soongConfigModuleTypeFactory)
+	RegisterModuleType("soong_config_string_variable", soongConfigStringVariableFactory)
+	RegisterModuleType("soong_config_bool_variable", soongConfigBoolVariableFactory)
+}
+
+// soong_config_module_type defines a new module type that wraps an existing module type and allows
+// some of its properties to be set based on variables in a namespace of the product config.  For
+// example, an Android.bp file could have:
+//
+//     soong_config_module_type {
+//         name: "acme_cc_defaults",
+//         module_type: "cc_defaults",
+//         config_namespace: "acme",
+//         variables: ["board"],
+//         bool_variables: ["feature"],
+//         properties: ["cflags", "srcs"],
+//     }
+//
+//     soong_config_string_variable {
+//         name: "board",
+//         values: ["soc_a", "soc_b"],
+//     }
+//
+//     acme_cc_defaults {
+//         name: "acme_defaults",
+//         cflags: ["-DGENERIC"],
+//         soong_config_variables: {
+//             board: {
+//                 soc_a: {
+//                     cflags: ["-DSOC_A"],
+//                 },
+//                 soc_b: {
+//                     cflags: ["-DSOC_B"],
+//                 },
+//             },
+//             feature: {
+//                 cflags: ["-DFEATURE"],
+//             },
+//         },
+//     }
+//
+// And the product config could have:
+//
+//     SOONG_CONFIG_NAMESPACES += acme
+//     SOONG_CONFIG_acme += board feature
+//     SOONG_CONFIG_acme_board := soc_a
+//     SOONG_CONFIG_acme_feature := true
+//
+// Which would result in acme_defaults having cflags: ["-DGENERIC", "-DSOC_A", "-DFEATURE"].
+//
+// The properties selected by the variables are appended to the module's properties in a load hook,
+// before defaults are applied and before the module is split into architecture variants.
+//
+// Module types are registered before the Blueprints files are parsed, so soong_config_module_type
+// definitions are read from every Blueprints file that contains one by
+// RegisterSoongConfigModuleTypes before the Context is created.  This has two limitations: every
+// Blueprints file is read once more by soong_build, and the new module types are global, so their
+// names must be unique across the tree and they can be used in any Blueprints file, not only the
+// one that defines them.  The variables listed in variables must be defined by
+// soong_config_string_variable or soong_config_bool_variable modules in the same file.
+type soongConfigModuleTypeProperties struct {
+	// the existing module type that the new module type wraps, e.g. "cc_defaults"
+	Module_type *string
+
+	// the namespace in the product config that contains the variables, i.e. the name used in
+	// SOONG_CONFIG_NAMESPACES
+	Config_namespace *string
+
+	// the names of soong_config_string_variable or soong_config_bool_variable modules defined in the
+	// same file that can affect the properties of the new module type
+	Variables []string
+
+	// the names of boolean variables that can affect the properties of the new module type, without
+	// needing a separate soong_config_bool_variable module
+	Bool_variables []string
+
+	// the properties of the wrapped module type that can be set by the variables.  Nested
+	// properties are separated with a '.'.
+	Properties []string
+}
+
+type soongConfigModuleTypeModule struct {
+	ModuleBase
+	properties soongConfigModuleTypeProperties
+}
+
+func soongConfigModuleTypeFactory() Module {
+	module := &soongConfigModuleTypeModule{}
+	module.AddProperties(&module.properties)
+	InitAndroidModule(module)
+	return module
+}
+
+func (m *soongConfigModuleTypeModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+	// Nothing to do, the module type was registered before parsing by RegisterSoongConfigModuleTypes.
+}
+
+type soongConfigStringVariableProperties struct {
+	// the list of values the variable can be set to.  A property struct is available for each of
+	// these values in the soong_config_variables property of modules that use the variable.
+	Values []string
+}
+
+type soongConfigStringVariableModule struct {
+	ModuleBase
+	properties soongConfigStringVariableProperties
+}
+
+// soong_config_string_variable defines a string variable with a fixed set of values that can be
+// used in the variables property of soong_config_module_type.
+func soongConfigStringVariableFactory() Module {
+	module := &soongConfigStringVariableModule{}
+	module.AddProperties(&module.properties)
+	InitAndroidModule(module)
+	return module
+}
+
+func (m *soongConfigStringVariableModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+}
+
+type soongConfigBoolVariableModule struct {
+	ModuleBase
+}
+
+// soong_config_bool_variable defines a boolean variable that can be used in the variables property
+// of soong_config_module_type.
+func soongConfigBoolVariableFactory() Module {
+	module := &soongConfigBoolVariableModule{}
+	InitAndroidModule(module)
+	return module
+}
+
+func (m *soongConfigBoolVariableModule) GenerateAndroidBuildActions(ctx ModuleContext) {
+}
+
+// A soongConfigVariable is a variable in a product config namespace that can select properties.
+type soongConfigVariable interface {
+	// variableName returns the name of the variable in the product config namespace.
+	variableName() string
+
+	// variableValuesType returns the type of the struct in soong_config_variables for this
+	// variable, given the type of the struct that contains the affectable properties.
+	variableValuesType(affectablePropertiesType reflect.Type) reflect.Type
+
+	// selectProperties returns a pointer to the property struct in values that should be applied
+	// for the value of the variable in config, or an invalid reflect.Value if no properties should
+	// be applied.
+	selectProperties(values reflect.Value, config VendorConfig) (reflect.Value, error)
+}
+
+type stringVariable struct {
+	name   string
+	values []string
+}
+
+func (s *stringVariable) variableName() string {
+	return s.name
+}
+
+func (s *stringVariable) variableValuesType(affectablePropertiesType reflect.Type) reflect.Type {
+	var fields []reflect.StructField
+	for _, v := range s.values {
+		fields = append(fields, reflect.StructField{
+			Name: proptools.FieldNameForProperty(v),
+			Type: affectablePropertiesType,
+		})
+	}
+	return reflect.StructOf(fields)
+}
+
+func (s *stringVariable) selectProperties(values reflect.Value, config VendorConfig) (reflect.Value, error) {
+	if !config.IsSet(s.name) {
+		return reflect.Value{}, nil
+	}
+
+	value := config.String(s.name)
+	for i, v := range s.values {
+		if v == value {
+			return values.Field(i).Addr(), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("soong config variable %q set to unknown value %q, expected one of %q",
+		s.name, value, s.values)
+}
+
+type boolVariable struct {
+	name string
+}
+
+func (b *boolVariable) variableName() string {
+	return b.name
+}
+
+func (b *boolVariable) variableValuesType(affectablePropertiesType reflect.Type) reflect.Type {
+	return affectablePropertiesType
+}
+
+func (b *boolVariable) selectProperties(values reflect.Value, config VendorConfig) (reflect.Value, error) {
+	if config.Bool(b.name) {
+		return values.Addr(), nil
+	}
+	return reflect.Value{}, nil
+}
+
+// A soongConfigModuleType is the definition of a module type parsed from a
+// soong_config_module_type module.
+type soongConfigModuleType struct {
+	name            string
+	baseModuleType  string
+	configNamespace string
+	variables       []soongConfigVariable
+	properties      []string
+
+	// The type of the struct containing the soong_config_variables property.
+	conditionalPropertiesType reflect.Type
+}
+
+// factory returns a ModuleFactory for the new module type that wraps the factory of the base
+// module type and applies the properties selected by the product config in a load hook.
+func (t *soongConfigModuleType) factory(baseFactory ModuleFactory) ModuleFactory {
+	return func() Module {
+		module := baseFactory()
+
+		conditionalProps := reflect.New(t.conditionalPropertiesType)
+		module.AddProperties(conditionalProps.Interface())
+
+		AddLoadHook(module, func(ctx LoadHookContext) {
+			config := ctx.Config().VendorConfig(t.configNamespace)
+			variables := conditionalProps.Elem().Field(0)
+			for i, v := range t.variables {
+				props, err := v.selectProperties(variables.Field(i), config)
+				if err != nil {
+					ctx.PropertyErrorf("soong_config_variables."+v.variableName(), "%s", err.Error())
+					continue
+				}
+				if props.IsValid() {
+					ctx.AppendProperties(props.Interface())
+				}
+			}
+		})
+
+		return module
+	}
+}
+
+// createConditionalPropertiesType creates the type of the struct that contains the
+// soong_config_variables property, using the types of the affectable properties found in the
+// property structs of the base module type.
+func (t *soongConfigModuleType) createConditionalPropertiesType(baseProps []interface{}) error {
+	affectablePropertiesType, err := createAffectablePropertiesType(t.properties, baseProps)
+	if err != nil {
+		return err
+	}
+
+	var fields []reflect.StructField
+	for _, v := range t.variables {
+		fields = append(fields, reflect.StructField{
+			Name: proptools.FieldNameForProperty(v.variableName()),
+			Type: v.variableValuesType(affectablePropertiesType),
+		})
+	}
+
+	t.conditionalPropertiesType = reflect.StructOf([]reflect.StructField{
+		{
+			Name: "Soong_config_variables",
+			Type: reflect.StructOf(fields),
+		},
+	})
+
+	return nil
+}
+
+// createAffectablePropertiesType creates a struct type containing the listed properties, with the
+// same types as the matching properties of the base module type.  Nested properties separated with
+// '.' are created as nested structs.
+func createAffectablePropertiesType(properties []string, baseProps []interface{}) (reflect.Type, error) {
+	type propertyTree struct {
+		typ      reflect.Type
+		children map[string]*propertyTree
+	}
+
+	root := &propertyTree{children: make(map[string]*propertyTree)}
+	for _, property := range properties {
+		typ := typeForPropertyFromPropertyStructs(baseProps, property)
+		if typ == nil {
+			return nil, fmt.Errorf("property %q not found", property)
+		}
+
+		node := root
+		for _, name := range strings.Split(property, ".") {
+			child := node.children[name]
+			if child == nil {
+				child = &propertyTree{children: make(map[string]*propertyTree)}
+				node.children[name] = child
+			}
+			node = child
+		}
+		node.typ = typ
+	}
+
+	var createType func(node *propertyTree) reflect.Type
+	createType = func(node *propertyTree) reflect.Type {
+		if node.typ != nil {
+			return node.typ
+		}
+		var names []string
+		for name := range node.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var fields []reflect.StructField
+		for _, name := range names {
+			fields = append(fields, reflect.StructField{
+				Name: proptools.FieldNameForProperty(name),
+				Type: createType(node.children[name]),
+			})
+		}
+		return reflect.StructOf(fields)
+	}
+
+	return createType(root), nil
+}
+
+func typeForPropertyFromPropertyStructs(propertyStructs []interface{}, property string) reflect.Type {
+	for _, propertyStruct := range propertyStructs {
+		if typ := typeForPropertyFromPropertyStruct(propertyStruct, property); typ != nil {
+			return typ
+		}
+	}
+	return nil
+}
+
+func typeForPropertyFromPropertyStruct(propertyStruct interface{}, property string) reflect.Type {
+	typ := reflect.TypeOf(propertyStruct).Elem()
+	for _, name := range strings.Split(property, ".") {
+		if typ.Kind() != reflect.Struct {
+			return nil
+		}
+		field, ok := typ.FieldByName(proptools.FieldNameForProperty(name))
+		if !ok {
+			return nil
+		}
+		typ = field.Type
+	}
+	return typ
+}
+
+// SoongConfigModuleTypeFactories parses a Blueprints file and returns a ModuleFactory for each
+// soong_config_module_type defined in it, keyed by the name of the new module type.  factories
+// contains the factories of the module types that can be wrapped.
+func SoongConfigModuleTypeFactories(filename string, r io.Reader,
+	factories map[string]ModuleFactory) (map[string]ModuleFactory, []error) {
+
+	file, errs := parser.ParseAndEval(filename, r, parser.NewScope(nil))
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	var errorList []error
+	errorf := func(pos scanner.Position, format string, args ...interface{}) {
+		errorList = append(errorList, &parser.ParseError{
+			Err: fmt.Errorf(format, args...),
+			Pos: pos,
+		})
+	}
+
+	// Find the variables defined in the file first, they may be defined after the module types
+	// that use them.
+	variables := make(map[string]soongConfigVariable)
+	for _, def := range file.Defs {
+		module, ok := def.(*parser.Module)
+		if !ok {
+			continue
+		}
+		switch module.Type {
+		case "soong_config_string_variable", "soong_config_bool_variable":
+			props := parserProperties(module)
+			name, _ := parserString(props["name"])
+			if name == "" {
+				errorf(module.TypePos, "%s must have a name", module.Type)
+				continue
+			}
+			if module.Type == "soong_config_bool_variable" {
+				variables[name] = &boolVariable{name: name}
+				continue
+			}
+			values, ok := parserStringList(props["values"])
+			if !ok || len(values) == 0 {
+				errorf(module.TypePos, "soong_config_string_variable %q must have values", name)
+				continue
+			}
+			variables[name] = &stringVariable{name: name, values: values}
+		}
+	}
+
+	ret := make(map[string]ModuleFactory)
+	for _, def := range file.Defs {
+		module, ok := def.(*parser.Module)
+		if !ok || module.Type != "soong_config_module_type" {
+			continue
+		}
+
+		props := parserProperties(module)
+		t := &soongConfigModuleType{}
+		t.name, _ = parserString(props["name"])
+		t.baseModuleType, _ = parserString(props["module_type"])
+		t.configNamespace, _ = parserString(props["config_namespace"])
+		t.properties, _ = parserStringList(props["properties"])
+		variableNames, _ := parserStringList(props["variables"])
+		boolVariableNames, _ := parserStringList(props["bool_variables"])
+
+		if t.name == "" {
+			errorf(module.TypePos, "soong_config_module_type must have a name")
+			continue
+		}
+		if t.configNamespace == "" {
+			errorf(module.TypePos, "soong_config_module_type %q must have a config_namespace", t.name)
+			continue
+		}
+		if len(t.properties) == 0 {
+			errorf(module.TypePos, "soong_config_module_type %q must have properties", t.name)
+			continue
+		}
+		if _, exists := ret[t.name]; exists {
+			errorf(module.TypePos, "soong_config_module_type %q is already defined", t.name)
+			continue
+		}
+
+		baseFactory := factories[t.baseModuleType]
+		if baseFactory == nil {
+			errorf(module.TypePos, "soong_config_module_type %q wraps unknown module_type %q",
+				t.name, t.baseModuleType)
+			continue
+		}
+
+		failed := false
+		for _, name := range variableNames {
+			v := variables[name]
+			if v == nil {
+				errorf(module.TypePos, "soong_config_module_type %q uses undefined variable %q",
+					t.name, name)
+				failed = true
+				continue
+			}
+			t.variables = append(t.variables, v)
+		}
+		for _, name := range boolVariableNames {
+			t.variables = append(t.variables, &boolVariable{name: name})
+		}
+		if failed {
+			continue
+		}
+
+		if err := t.createConditionalPropertiesType(baseFactory().GetProperties()); err != nil {
+			errorf(module.TypePos, "soong_config_module_type %q: %s in module_type %q",
+				t.name, err.Error(), t.baseModuleType)
+			continue
+		}
+
+		ret[t.name] = t.factory(baseFactory)
+	}
+
+	return ret, errorList
+}
+
+// RegisterSoongConfigModuleTypes reads the soong_config_module_type definitions in the given
+// Blueprints files and registers the module types they define.  It must be called before
+// Context.Register.  The files are read in parallel, and files that don't mention
+// soong_config_module_type are not parsed.
+func RegisterSoongConfigModuleTypes(files []string) []error {
+	factories := ModuleTypeFactories()
+	newFactories := make(map[string]ModuleFactory)
+
+	contents, errs := readSoongConfigModuleTypeFiles(files)
+	for i, file := range files {
+		data := contents[i]
+		if data == nil {
+			continue
+		}
+
+		fileFactories, fileErrs := SoongConfigModuleTypeFactories(file, bytes.NewReader(data), factories)
+		errs = append(errs, fileErrs...)
+		for name, factory := range fileFactories {
+			if _, exists := factories[name]; exists {
+				errs = append(errs, fmt.Errorf("%s: module type %q is already defined", file, name))
+				continue
+			}
+			if _, exists := newFactories[name]; exists {
+				errs = append(errs, fmt.Errorf("%s: soong_config_module_type %q is already defined in "+
+					"another file", file, name))
+				continue
+			}
+			newFactories[name] = factory
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	var names []string
+	for name := range newFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		RegisterModuleType(name, newFactories[name])
+	}
+
+	return nil
+}
+
+// readSoongConfigModuleTypeFiles reads the given files in parallel, and returns the contents of
+// each file that mentions soong_config_module_type, or nil for the other files.
+func readSoongConfigModuleTypeFiles(files []string) ([][]byte, []error) {
+	contents := make([][]byte, len(files))
+	fileErrs := make([]error, len(files))
+
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				data, err := ioutil.ReadFile(files[i])
+				if err != nil {
+					fileErrs[i] = err
+				} else if bytes.Contains(data, []byte("soong_config_module_type")) {
+					contents[i] = data
+				}
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var errs []error
+	for _, err := range fileErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return contents, errs
+}
+
+func parserProperties(module *parser.Module) map[string]*parser.Property {
+	props := make(map[string]*parser.Property)
+	for _, prop := range module.Properties {
+		props[prop.Name] = prop
+	}
+	return props
+}
+
+func parserString(prop *parser.Property) (string, bool) {
+	if prop == nil {
+		return "", false
+	}
+	s, ok := prop.Value.Eval().(*parser.String)
+	if !ok {
+		return "", false
+	}
+	return s.Value, true
+}
@@ -0,0 +1,276 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type soongConfigTestModule struct {
+	ModuleBase
+	props struct {
+		Cflags []string
+		Nested struct {
+			Ldflags []string
+		}
+	}
+}
+
+func soongConfigTestModuleFactory() Module {
+	m := &soongConfigTestModule{}
+	m.AddProperties(&m.props)
+	InitAndroidModule(m)
+	return m
+}
+
+func (t *soongConfigTestModule) GenerateAndroidBuildActions(ModuleContext) {}
+
+const soongConfigModuleTypeDefinitions = `
+	soong_config_module_type {
+		name: "acme_test_module",
+		module_type: "test_module",
+		config_namespace: "acme",
+		variables: ["board"],
+		bool_variables: ["feature1", "feature2"],
+		properties: ["cflags", "nested.ldflags"],
+	}
+
+	soong_config_string_variable {
+		name: "board",
+		values: ["soc_a", "soc_b"],
+	}
+`
+
+func TestSoongConfigModule(t *testing.T) {
+	bp := soongConfigModuleTypeDefinitions + `
+		acme_test_module {
+			name: "foo",
+			cflags: ["-DGENERIC"],
+			soong_config_variables: {
+				board: {
+					soc_a: {
+						cflags: ["-DSOC_A"],
+					},
+					soc_b: {
+						cflags: ["-DSOC_B"],
+					},
+				},
+				feature1: {
+					cflags: ["-DFEATURE1"],
+					nested: {
+						ldflags: ["-lfeature1"],
+					},
+				},
+				feature2: {
+					cflags: ["-DFEATURE2"],
+				},
+			},
+		}
+	`
+
+	buildDir, err := ioutil.TempDir("", "soong_config_module_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	config := TestConfig(buildDir, nil)
+	config.TestProductVariables.VendorVars = map[string]map[string]string{
+		"acme": map[string]string{
+			"board":    "soc_a",
+			"feature1": "true",
+		},
+	}
+
+	ctx := testSoongConfigModuleContext(t, bp)
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	FailIfErrored(t, errs)
+	_, errs = ctx.PrepareBuildActions(config)
+	FailIfErrored(t, errs)
+
+	foo := ctx.ModuleForTests("foo", "").Module().(*soongConfigTestModule)
+
+	if g, w := foo.props.Cflags, []string{"-DGENERIC", "-DSOC_A", "-DFEATURE1"}; !reflect.DeepEqual(g, w) {
+		t.Errorf("wanted foo cflags %q, got %q", w, g)
+	}
+	if g, w := foo.props.Nested.Ldflags, []string{"-lfeature1"}; !reflect.DeepEqual(g, w) {
+		t.Errorf("wanted foo nested.ldflags %q, got %q", w, g)
+	}
+}
+
+func TestSoongConfigModuleUnknownValue(t *testing.T) {
+	bp := soongConfigModuleTypeDefinitions + `
+		acme_test_module {
+			name: "foo",
+		}
+	`
+
+	buildDir, err := ioutil.TempDir("", "soong_config_module_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(buildDir)
+
+	config := TestConfig(buildDir, nil)
+	config.TestProductVariables.VendorVars = map[string]map[string]string{
+		"acme": map[string]string{
+			"board": "soc_c",
+		},
+	}
+
+	ctx := testSoongConfigModuleContext(t, bp)
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	FailIfErrored(t, errs)
+	_, errs = ctx.PrepareBuildActions(config)
+	FailIfNoMatchingErrors(t, `soong config variable "board" set to unknown value "soc_c"`, errs)
+}
+
+func TestSoongConfigModuleTypeErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		bp   string
+		err  string
+	}{
+		{
+			name: "undefined variable",
+			bp: `
+				soong_config_module_type {
+					name: "acme_test_module",
+					module_type: "test_module",
+					config_namespace: "acme",
+					variables: ["board"],
+					properties: ["cflags"],
+				}
+			`,
+			err: `soong_config_module_type "acme_test_module" uses undefined variable "board"`,
+		},
+		{
+			name: "unknown module type",
+			bp: `
+				soong_config_module_type {
+					name: "acme_test_module",
+					module_type: "unknown_module",
+					config_namespace: "acme",
+					bool_variables: ["feature"],
+					properties: ["cflags"],
+				}
+			`,
+			err: `soong_config_module_type "acme_test_module" wraps unknown module_type "unknown_module"`,
+		},
+		{
+			name: "unknown property",
+			bp: `
+				soong_config_module_type {
+					name: "acme_test_module",
+					module_type: "test_module",
+					config_namespace: "acme",
+					bool_variables: ["feature"],
+					properties: ["cppflags"],
+				}
+			`,
+			err: `property "cppflags" not found in module_type "test_module"`,
+		},
+		{
+			name: "missing config_namespace",
+			bp: `
+				soong_config_module_type {
+					name: "acme_test_module",
+					module_type: "test_module",
+					bool_variables: ["feature"],
+					properties: ["cflags"],
+				}
+			`,
+			err: `soong_config_module_type "acme_test_module" must have a config_namespace`,
+		},
+	}
+
+	factories := map[string]ModuleFactory{
+		"test_module": soongConfigTestModuleFactory,
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, errs := SoongConfigModuleTypeFactories("Android.bp", strings.NewReader(tc.bp), factories)
+			FailIfNoMatchingErrors(t, tc.err, errs)
+		})
+	}
+}
+
+func testSoongConfigModuleContext(t *testing.T, bp string) *TestContext {
+	t.Helper()
+
+	factories, errs := SoongConfigModuleTypeFactories("Android.bp", strings.NewReader(bp),
+		map[string]ModuleFactory{
+			"test_module": soongConfigTestModuleFactory,
+		})
+	FailIfErrored(t, errs)
+
+	ctx := NewTestContext()
+	ctx.PreArchMutators(func(ctx RegisterMutatorsContext) {
+		ctx.TopDown("load_hooks", LoadHookMutator).Parallel()
+	})
+	ctx.RegisterModuleType("soong_config_module_type", ModuleFactoryAdaptor(soongConfigModuleTypeFactory))
+	ctx.RegisterModuleType("soong_config_string_variable", ModuleFactoryAdaptor(soongConfigStringVariableFactory))
+	ctx.RegisterModuleType("soong_config_bool_variable", ModuleFactoryAdaptor(soongConfigBoolVariableFactory))
+	ctx.RegisterModuleType("test_module", ModuleFactoryAdaptor(soongConfigTestModuleFactory))
+	for name, factory := range factories {
+		ctx.RegisterModuleType(name, ModuleFactoryAdaptor(factory))
+	}
+	ctx.Register()
+
+	ctx.MockFileSystem(map[string][]byte{
+		"Android.bp": []byte(bp),
+	})
+
+	return ctx
+}
+
+func TestReadSoongConfigModuleTypeFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "soong_config_module_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var files []string
+	for i, content := range []string{soongConfigModuleTypeDefinitions, `cc_library { name: "libfoo" }`} {
+		file := filepath.Join(dir, fmt.Sprintf("%d", i), "Android.bp")
+		if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	files = append(files, filepath.Join(dir, "missing", "Android.bp"))
+
+	contents, errs := readSoongConfigModuleTypeFiles(files)
+	if g, w := string(contents[0]), soongConfigModuleTypeDefinitions; g != w {
+		t.Errorf("expected the contents of %q, got %q", files[0], g)
+	}
+	if contents[1] != nil || contents[2] != nil {
+		t.Errorf("expected no contents for files without soong_config_module_type, got %q", contents[1:])
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), files[2]) {
+		t.Errorf("expected an error for %q, got %q", files[2], errs)
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package android
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Enforces visibility rules between modules.
+//
+// Two stage process:
+// * First stage works bottom up to extract visibility information from the modules, parse it,
+//   create visibilityRule structures and store them in a map keyed by the module's
+//   qualifiedModuleName instance, i.e. //<pkg>:<name>. The map is stored in the config rather
+//   than a global variable for testing. Each test has its own Config so they do not share a map
+//   and so can be run in parallel. The gatherer runs after the defaults mutator so that a
+//   visibility property set on a *_defaults module is inherited by the modules that use it,
+//   unless they set their own which replaces it. A module that does not set visibility, even
+//   through its defaults, uses the default_visibility of its package module, which is checked by
+//   an earlier pass over the package modules.
+//
+// * Second stage works top down and iterates over all the deps for each module. If the dep is in
+//   the same package then it is automatically visible. Otherwise, for each dep it first extracts
+//   its visibilityRule from the config map. If one could not be found then it assumes that it is
+//   publicly visible. Otherwise, it calls the visibility rule to check that the module can see
+//   the dependency. If it cannot then an error is reported naming both ends of the dependency.
+//
+// The package and namespace of each module are recorded by NameResolver.NewModule when the module
+// is added to its namespace, so the rules can be checked against dependencies that were resolved
+// through namespace imports or fully qualified references.
+
+// Patterns for the values that can be specified in visibility property.
+const (
+	packagePattern        = `//([^/:]+(?:/[^/:]+)*)`
+	namePattern           = `:([^/:]+)`
+	visibilityRulePattern = `^(?:` + packagePattern + `)?(?:` + namePattern + `)?$`
+)
+
+var visibilityRuleRegexp = regexp.MustCompile(visibilityRulePattern)
+
+// Qualified id for a module
+type qualifiedModuleName struct {
+	// The package (i.e. directory) in which the module is defined, without trailing /
+	pkg string
+
+	// The name of the module.
+	name string
+
+	// The path of the soong_namespace that contains the module, "." for the root namespace.
+	namespace string
+}
+
+func (q qualifiedModuleName) String() string {
+	return fmt.Sprintf("//%s:%s", q.pkg, q.name)
+}
+
+// A visibility rule is associated with a module and determines which other modules it is visible
+// to, i.e. which other modules can depend on the rule's module.
+type visibilityRule interface {
+	// Check to see whether this rules matches m.
+	// Returns true if it does, false otherwise.
+	matches(m qualifiedModuleName) bool
+
+	String() string
+}
+
+// A compositeRule is a visibility rule composed from other visibility rules.
+// This array will only be [] if all the rules are invalid and will behave as if visibility was
+// ["//visibility:private"].
+type compositeRule []visibilityRule
+
+// A compositeRule matches if and only if any of its rules matches.
+func (c compositeRule) matches(m qualifiedModuleName) bool {
+	for _, r := range c {
+		if r.matches(m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c compositeRule) String() string {
+	s := make([]string, 0, len(c))
+	for _, r := range c {
+		s = append(s, r.String())
+	}
+
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+// A packageRule is a visibility rule that matches modules in a specific package (i.e. directory).
+type packageRule struct {
+	pkg string
+}
+
+func (r packageRule) matches(m qualifiedModuleName) bool {
+	return m.pkg == r.pkg
+}
+
+func (r packageRule) String() string {
+	return fmt.Sprintf("//%s:__pkg__", r.pkg)
+}
+
+// A subpackagesRule is a visibility rule that matches modules in a specific package (i.e.
+// directory) or any of its subpackages (i.e. subdirectories).
+type subpackagesRule struct {
+	pkgPrefix string
+}
+
+func (r subpackagesRule) matches(m qualifiedModuleName) bool {
+	return isAncestor(r.pkgPrefix, m.pkg)
+}
+
+func isAncestor(p1 string, p2 string) bool {
+	return strings.HasPrefix(p2+"/", p1+"/")
+}
+
+func (r subpackagesRule) String() string {
+	return fmt.Sprintf("//%s:__subpackages__", r.pkgPrefix)
+}
+
+// A namespaceRule is a visibility rule that matches modules in the same soong_namespace as the
+// module that declared it.
+type namespaceRule struct {
+	namespace string
+}
+
+func (r namespaceRule) matches(m qualifiedModuleName) bool {
+	return m.namespace == r.namespace
+}
+
+func (r namespaceRule) String() string {
+	return fmt.Sprintf("//visibility:namespace(%s)", r.namespace)
+}
+
+var visibilityRuleMap = NewOnceKey("visibilityRuleMap")
+
+// The map from qualifiedModuleName to visibilityRule.
+func moduleToVisibilityRuleMap(ctx BaseModuleContext) *sync.Map {
+	return ctx.Config().Once(visibilityRuleMap, func() interface{} {
+		return &sync.Map{}
+	}).(*sync.Map)
+}
+
+// Visibility is not dependent on arch so this must be registered before the arch phase to avoid
+// having to process multiple variants for each module. It must be registered after the defaults
+// mutator so that visibility is inherited from defaults.
+func registerVisibilityRuleGatherer(ctx RegisterMutatorsContext) {
+	ctx.BottomUp("packageVisibilityChecker", packageVisibilityChecker).Parallel()
+	ctx.BottomUp("visibilityRuleGatherer", visibilityRuleGatherer).Parallel()
+}
+
+// This must be registered after the deps have been resolved.
+func registerVisibilityRuleEnforcer(ctx RegisterMutatorsContext) {
+	ctx.TopDown("visibilityRuleEnforcer", visibilityRuleEnforcer).Parallel()
+}
+
+// Gathers the visibility rules, parses the visibility properties, stores them in a map by
+// qualifiedModuleName for retrieval during enforcement.
+//
+// See ../README.md#Visibility for information on the format of the visibility rules.
+func visibilityRuleGatherer(ctx BottomUpMutatorContext) {
+	m, ok := ctx.Module().(Module)
+	if !ok {
+		return
+	}
+
+	// Defaults and package modules only carry visibility on behalf of the modules that use them.
+	switch m.(type) {
+	case Defaults, *packageModule:
+		return
+	}
+
+	property := "visibility"
+	visibility := m.base().commonProperties.Visibility
+	if visibility == nil {
+		// The default_visibility of the package is interpreted relative to the module, so that
+		// e.g. //visibility:private makes the module private to its own directory.  Errors in it
+		// have already been reported on the package module.
+		if p := m.base().packageModule(); p != nil && p.defaultVisibilityChecked {
+			property = "default_visibility"
+			visibility = p.properties.Default_visibility
+		}
+	}
+
+	if visibility != nil {
+		rule := parseRules(ctx, m, property, visibility)
+		if rule != nil {
+			moduleToVisibilityRuleMap(ctx).Store(m.base().qualifiedModuleName(ctx.ModuleName()), rule)
+		}
+	}
+}
+
+// Checks the default_visibility property of the package modules, so that the modules that use it
+// do not have to report the same errors.
+func packageVisibilityChecker(ctx BottomUpMutatorContext) {
+	p, ok := ctx.Module().(*packageModule)
+	if !ok || p.properties.Default_visibility == nil {
+		return
+	}
+
+	parseRules(ctx, p, "default_visibility", p.properties.Default_visibility)
+	p.defaultVisibilityChecked = !ctx.Failed()
+}
+
+func parseRules(ctx BaseModuleContext, m Module, property string, visibility []string) compositeRule {
+	ruleCount := len(visibility)
+	if ruleCount == 0 {
+		// This prohibits an empty list as its meaning is unclear, e.g. it could mean no visibility and
+		// it could mean public visibility. Requiring at least one rule makes the owner's intent
+		// clearer.
+		ctx.PropertyErrorf(property, "must contain at least one visibility rule")
+		return nil
+	}
+
+	rules := make(compositeRule, 0, ruleCount)
+	for _, v := range visibility {
+		ok, pkg, name := splitRule(ctx, property, v)
+		if !ok {
+			// Visibility rule is invalid so ignore it. Keep going rather than aborting straight away to
+			// ensure all the rules on this module are checked.
+			continue
+		}
+
+		if pkg == "visibility" {
+			switch name {
+			case "private", "public":
+				if ruleCount != 1 {
+					ctx.PropertyErrorf(property, "cannot mix %q with any other visibility rules", v)
+					continue
+				}
+				if name == "public" {
+					return nil
+				}
+				rules = append(rules, packageRule{ctx.ModuleDir()})
+			case "namespace":
+				rules = append(rules, namespaceRule{m.base().commonProperties.NamespacePath})
+			default:
+				ctx.PropertyErrorf(property, "unrecognized visibility rule %q", v)
+			}
+			continue
+		}
+
+		// Within a rule :__pkg__ implies the package in which it is used, e.g. //foo:__pkg__ is
+		// equivalent to //foo.
+		var r visibilityRule
+		switch name {
+		case "__pkg__":
+			r = packageRule{pkg}
+		case "__subpackages__":
+			r = subpackagesRule{pkg}
+		default:
+			ctx.PropertyErrorf(property, "unrecognized visibility rule %q", v)
+			continue
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules
+}
+
+func splitRule(ctx BaseModuleContext, property, ruleExpression string) (bool, string, string) {
+	// Make sure that the rule is of the correct format.
+	matches := visibilityRuleRegexp.FindStringSubmatch(ruleExpression)
+	if ruleExpression == "" || matches == nil {
+		ctx.PropertyErrorf(property,
+			"invalid visibility pattern %q must match"+
+				" //<package>:<module>, //<package> or :<module>",
+			ruleExpression)
+		return false, "", ""
+	}
+
+	// Extract the package and name.
+	pkg := matches[1]
+	name := matches[2]
+
+	// Normalize the short forms into the normalized forms.
+	if pkg == "" {
+		// Use the current package.
+		pkg = ctx.ModuleDir()
+	} else if name == "" {
+		// Use the default name.
+		name = "__pkg__"
+	}
+
+	return true, pkg, name
+}
+
+func visibilityRuleEnforcer(ctx TopDownMutatorContext) {
+	m, ok := ctx.Module().(Module)
+	if !ok {
+		return
+	}
+
+	qualified := m.base().qualifiedModuleName(ctx.ModuleName())
+
+	moduleToVisibilityRule := moduleToVisibilityRuleMap(ctx)
+
+	// Visit all the dependencies making sure that this module has access to them all.
+	ctx.VisitDirectDeps(func(dep Module) {
+		depQualified := dep.base().qualifiedModuleName(ctx.OtherModuleName(dep))
+
+		// Targets are always visible to other targets in their own package.
+		if depQualified.pkg == qualified.pkg {
+			return
+		}
+
+		rule, ok := moduleToVisibilityRule.Load(depQualified)
+		if ok {
+			if !rule.(compositeRule).matches(qualified) {
+				ctx.ModuleErrorf("%s depends on %s which is not visible to this module; "+
+					"%s is only visible to %s", qualified, depQualified, depQualified, rule)
+			}
+		}
+	})
+}
+
+func (a *ModuleBase) qualifiedModuleName(name string) qualifiedModuleName {
+	return qualifiedModuleName{
+		pkg:       a.commonProperties.ModuleDir,
+		name:      name,
+		namespace: a.commonProperties.NamespacePath,
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"android/soong/cc"
 	"android/soong/java"
 	"android/soong/python"
+	"android/soong/sh"
 
 	"github.com/google/blueprint"
 	"github.com/google/blueprint/bootstrap"
@@ -112,6 +113,13 @@ var (
 	certificateTag = dependencyTag{name: "certificate"}
 )
 
+var _ android.LicensePropagatingDependencyTag = dependencyTag{}
+
+// PropagatesLicenses returns true for the dependencies that are packaged into the APEX.
+func (d dependencyTag) PropagatesLicenses() bool {
+	return d != keyTag && d != certificateTag
+}
+
 func init() {
 	pctx.Import("android/soong/common")
 	pctx.Import("android/soong/java")
@@ -141,6 +149,7 @@ func init() {
 	android.RegisterModuleType("apex", apexBundleFactory)
 	android.RegisterModuleType("apex_test", testApexBundleFactory)
 	android.RegisterModuleType("apex_defaults", defaultsFactory)
+	android.RegisterModuleType("override_apex", overrideApexFactory)
 
 	android.PostDepsMutators(func(ctx android.RegisterMutatorsContext) {
 		ctx.TopDown("apex_deps", apexDepsMutator)
@@ -236,17 +245,10 @@ type apexBundleProperties struct {
 	// List of prebuilt files that are embedded inside this APEX bundle
 	Prebuilts []string
 
-	// Name of the apex_key module that provides the private key to sign APEX
-	Key *string
-
 	// The type of APEX to build. Controls what the APEX payload is. Either
 	// 'image', 'zip' or 'both'. Default: 'image'.
 	Payload_type *string
 
-	// The name of a certificate in the default certificate directory, blank to use the default product certificate,
-	// or an android_app_certificate module name in the form ":module".
-	Certificate *string
-
 	// Whether this APEX is installable to one of the partitions. Default: true.
 	Installable *bool
 
@@ -284,6 +286,23 @@ type apexTargetBundleProperties struct {
 	}
 }
 
+// Properties of an apex module that an override_apex module can replace.
+type overridableProperties struct {
+	// Name of the apex_key module that provides the private key to sign APEX
+	Key *string
+
+	// The name of a certificate in the default certificate directory, blank to use the default product certificate,
+	// or an android_app_certificate module name in the form ":module".
+	Certificate *string
+
+	// Names of modules to be overridden. Listed modules can only be other binaries
+	// (in Make or Soong).
+	// This does not completely prevent installation of the overridden binaries, but if both
+	// binaries would be installed by default (in PRODUCT_PACKAGES) the other binary will be removed
+	// from PRODUCT_PACKAGES.
+	Overrides []string
+}
+
 type apexFileClass int
 
 const (
@@ -373,9 +392,11 @@ type apexFile struct {
 type apexBundle struct {
 	android.ModuleBase
 	android.DefaultableModuleBase
+	android.OverridableModuleBase
 
-	properties       apexBundleProperties
-	targetProperties apexTargetBundleProperties
+	properties            apexBundleProperties
+	targetProperties      apexTargetBundleProperties
+	overridableProperties overridableProperties
 
 	apexTypes apexPackaging
 
@@ -396,6 +417,12 @@ type apexBundle struct {
 	// list of module names that this APEX is depending on
 	externalDeps []string
 
+	// name of the linker namespace of this APEX, with the native shared libraries that it provides
+	// to the platform and that it requires from the platform through their stubs
+	linkerNamespaceName string
+	provideNativeLibs   []string
+	requireNativeLibs   []string
+
 	flattened bool
 
 	testApex bool
@@ -515,11 +542,11 @@ func (a *apexBundle) DepsMutator(ctx android.BottomUpMutatorContext) {
 		{Mutator: "arch", Variation: "android_common"},
 	}, javaLibTag, a.properties.Java_libs...)
 
-	if String(a.properties.Key) == "" {
+	if String(a.overridableProperties.Key) == "" {
 		ctx.ModuleErrorf("key is missing")
 		return
 	}
-	ctx.AddDependency(ctx.Module(), keyTag, String(a.properties.Key))
+	ctx.AddDependency(ctx.Module(), keyTag, String(a.overridableProperties.Key))
 
 	cert := android.SrcIsModule(a.getCertString(ctx))
 	if cert != "" {
@@ -528,11 +555,20 @@ func (a *apexBundle) DepsMutator(ctx android.BottomUpMutatorContext) {
 }
 
 func (a *apexBundle) getCertString(ctx android.BaseContext) string {
-	certificate, overridden := ctx.DeviceConfig().OverrideCertificateFor(ctx.ModuleName())
+	certificate, overridden := ctx.DeviceConfig().OverrideCertificateFor(a.apexName(ctx))
 	if overridden {
 		return ":" + certificate
 	}
-	return String(a.properties.Certificate)
+	return String(a.overridableProperties.Certificate)
+}
+
+// apexName returns the name of the APEX produced by this variant, which is the name of the
+// override_apex module for variants created for one.
+func (a *apexBundle) apexName(ctx android.BaseContext) string {
+	if overriddenBy := a.GetOverriddenBy(); overriddenBy != "" {
+		return overriddenBy
+	}
+	return ctx.ModuleName()
 }
 
 func (a *apexBundle) Srcs() android.Paths {
@@ -636,7 +672,7 @@ func getCopyManifestForGoBinary(ctx android.ModuleContext, gb bootstrap.GoBinary
 	return
 }
 
-func getCopyManifestForShBinary(sh *android.ShBinary) (fileToCopy android.Path, dirInApex string) {
+func getCopyManifestForShBinary(sh *sh.ShBinary) (fileToCopy android.Path, dirInApex string) {
 	dirInApex = filepath.Join("bin", sh.SubDir())
 	fileToCopy = sh.OutputFile()
 	return
@@ -680,6 +716,9 @@ func (a *apexBundle) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 				if cc, ok := child.(*cc.Module); ok {
 					fileToCopy, dirInApex := getCopyManifestForNativeLibrary(cc, handleSpecialLibs)
 					filesInfo = append(filesInfo, apexFile{fileToCopy, depName, dirInApex, nativeSharedLib, cc, nil})
+					if cc.HasStubsVariants() {
+						a.provideNativeLibs = append(a.provideNativeLibs, fileToCopy.Base())
+					}
 					return true
 				} else {
 					ctx.PropertyErrorf("native_shared_libs", "%q is not a cc_library or cc_library_shared module", depName)
@@ -694,7 +733,7 @@ func (a *apexBundle) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 					fileToCopy, dirInApex := getCopyManifestForExecutable(cc)
 					filesInfo = append(filesInfo, apexFile{fileToCopy, depName, dirInApex, nativeExecutable, cc, cc.Symlinks()})
 					return true
-				} else if sh, ok := child.(*android.ShBinary); ok {
+				} else if sh, ok := child.(*sh.ShBinary); ok {
 					fileToCopy, dirInApex := getCopyManifestForShBinary(sh)
 					filesInfo = append(filesInfo, apexFile{fileToCopy, depName, dirInApex, shBinary, sh, nil})
 				} else if py, ok := child.(*python.Module); ok && py.HostToolPath().Valid() {
@@ -765,6 +804,9 @@ func (a *apexBundle) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 						if !android.DirectlyInAnyApex(ctx, cc.Name()) && !android.InList(cc.Name(), a.externalDeps) {
 							a.externalDeps = append(a.externalDeps, cc.Name())
 						}
+						if cc.OutputFile().Valid() {
+							a.requireNativeLibs = append(a.requireNativeLibs, cc.OutputFile().Path().Base())
+						}
 						// Don't track further
 						return false
 					}
@@ -780,7 +822,7 @@ func (a *apexBundle) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 
 	a.flattened = ctx.Config().FlattenApex() && !ctx.Config().UnbundledBuild()
 	if a.private_key_file == nil {
-		ctx.PropertyErrorf("key", "private_key for %q could not be found", String(a.properties.Key))
+		ctx.PropertyErrorf("key", "private_key for %q could not be found", String(a.overridableProperties.Key))
 		return
 	}
 
@@ -806,12 +848,22 @@ func (a *apexBundle) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 	// prepend the name of this APEX to the module names. These names will be the names of
 	// modules that will be defined if the APEX is flattened.
 	for i := range filesInfo {
-		filesInfo[i].moduleName = ctx.ModuleName() + "." + filesInfo[i].moduleName
+		filesInfo[i].moduleName = a.apexName(ctx) + "." + filesInfo[i].moduleName
 	}
 
 	a.installDir = android.PathForModuleInstall(ctx, "apex")
 	a.filesInfo = filesInfo
 
+	if !a.Host() {
+		a.linkerNamespaceName = a.apexName(ctx)
+		a.provideNativeLibs = android.FirstUniqueStrings(a.provideNativeLibs)
+		// Libraries with stubs in this APEX are linked directly by the other libraries in it.
+		a.requireNativeLibs = android.RemoveListFromList(android.FirstUniqueStrings(a.requireNativeLibs),
+			a.provideNativeLibs)
+		sort.Strings(a.provideNativeLibs)
+		sort.Strings(a.requireNativeLibs)
+	}
+
 	if a.apexTypes.zip() {
 		a.buildUnflattenedApex(ctx, zipApex)
 	}
@@ -826,7 +878,7 @@ func (a *apexBundle) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 }
 
 func (a *apexBundle) buildUnflattenedApex(ctx android.ModuleContext, apexType apexPackaging) {
-	cert := String(a.properties.Certificate)
+	cert := String(a.overridableProperties.Certificate)
 	if cert != "" && android.SrcIsModule(cert) == "" {
 		defaultDir := ctx.Config().DefaultAppCertificateDir(ctx)
 		a.container_certificate_file = defaultDir.Join(ctx, cert+".x509.pem")
@@ -849,7 +901,7 @@ func (a *apexBundle) buildUnflattenedApex(ctx android.ModuleContext, apexType ap
 	abis = android.FirstUniqueStrings(abis)
 
 	suffix := apexType.suffix()
-	unsignedOutputFile := android.PathForModuleOut(ctx, ctx.ModuleName()+suffix+".unsigned")
+	unsignedOutputFile := android.PathForModuleOut(ctx, a.apexName(ctx)+suffix+".unsigned")
 
 	filesToCopy := []android.Path{}
 	for _, f := range a.filesInfo {
@@ -928,7 +980,7 @@ func (a *apexBundle) buildUnflattenedApex(ctx android.ModuleContext, apexType ap
 			optFlags = append(optFlags, "--pubkey "+a.public_key_file.String())
 		}
 
-		manifestPackageName, overridden := ctx.DeviceConfig().OverrideManifestPackageNameFor(ctx.ModuleName())
+		manifestPackageName, overridden := ctx.DeviceConfig().OverrideManifestPackageNameFor(a.apexName(ctx))
 		if overridden {
 			optFlags = append(optFlags, "--override_apk_package_name "+manifestPackageName)
 		}
@@ -956,8 +1008,8 @@ func (a *apexBundle) buildUnflattenedApex(ctx android.ModuleContext, apexType ap
 			},
 		})
 
-		apexProtoFile := android.PathForModuleOut(ctx, ctx.ModuleName()+".pb"+suffix)
-		bundleModuleFile := android.PathForModuleOut(ctx, ctx.ModuleName()+suffix+"-base.zip")
+		apexProtoFile := android.PathForModuleOut(ctx, a.apexName(ctx)+".pb"+suffix)
+		bundleModuleFile := android.PathForModuleOut(ctx, a.apexName(ctx)+suffix+"-base.zip")
 		a.bundleModuleFile = bundleModuleFile
 
 		ctx.Build(pctx, android.BuildParams{
@@ -991,7 +1043,7 @@ func (a *apexBundle) buildUnflattenedApex(ctx android.ModuleContext, apexType ap
 		})
 	}
 
-	a.outputFiles[apexType] = android.PathForModuleOut(ctx, ctx.ModuleName()+suffix)
+	a.outputFiles[apexType] = android.PathForModuleOut(ctx, a.apexName(ctx)+suffix)
 	ctx.Build(pctx, android.BuildParams{
 		Rule:        java.Signapk,
 		Description: "signapk",
@@ -1005,7 +1057,7 @@ func (a *apexBundle) buildUnflattenedApex(ctx android.ModuleContext, apexType ap
 
 	// Install to $OUT/soong/{target,host}/.../apex
 	if a.installable() && (!ctx.Config().FlattenApex() || apexType.zip()) {
-		ctx.InstallFile(a.installDir, ctx.ModuleName()+suffix, a.outputFiles[apexType])
+		ctx.InstallFile(a.installDir, a.apexName(ctx)+suffix, a.outputFiles[apexType])
 	}
 }
 
@@ -1022,11 +1074,11 @@ func (a *apexBundle) buildFlattenedApex(ctx android.ModuleContext) {
 			Input:  manifest,
 			Output: copiedManifest,
 		})
-		a.filesInfo = append(a.filesInfo, apexFile{copiedManifest, ctx.ModuleName() + ".apex_manifest.json", ".", etc, nil, nil})
+		a.filesInfo = append(a.filesInfo, apexFile{copiedManifest, a.apexName(ctx) + ".apex_manifest.json", ".", etc, nil, nil})
 
 		if ctx.Config().FlattenApex() {
 			for _, fi := range a.filesInfo {
-				dir := filepath.Join("apex", ctx.ModuleName(), fi.installDir)
+				dir := filepath.Join("apex", a.apexName(ctx), fi.installDir)
 				target := ctx.InstallFile(android.PathForModuleInstall(ctx, dir), fi.builtFile.Base(), fi.builtFile)
 				for _, sym := range fi.symlinks {
 					ctx.InstallSymlink(android.PathForModuleInstall(ctx, dir), sym, target)
@@ -1036,6 +1088,13 @@ func (a *apexBundle) buildFlattenedApex(ctx android.ModuleContext) {
 	}
 }
 
+var _ cc.LinkerNamespaceModule = (*apexBundle)(nil)
+
+// LinkerNamespace returns the linker namespace of the native libraries of the APEX.
+func (a *apexBundle) LinkerNamespace() (name string, provideLibs, requireLibs []string) {
+	return a.linkerNamespaceName, a.provideNativeLibs, a.requireNativeLibs
+}
+
 func (a *apexBundle) AndroidMk() android.AndroidMkData {
 	writers := []android.AndroidMkData{}
 	if a.apexTypes.image() {
@@ -1163,13 +1222,16 @@ func (a *apexBundle) androidMkForType(apexType apexPackaging) android.AndroidMkD
 				fmt.Fprintln(w, "LOCAL_MODULE_PATH :=", filepath.Join("$(OUT_DIR)", a.installDir.RelPathString()))
 				fmt.Fprintln(w, "LOCAL_MODULE_STEM :=", name+apexType.suffix())
 				fmt.Fprintln(w, "LOCAL_UNINSTALLABLE_MODULE :=", !a.installable())
-				fmt.Fprintln(w, "LOCAL_REQUIRED_MODULES :=", String(a.properties.Key))
+				fmt.Fprintln(w, "LOCAL_REQUIRED_MODULES :=", String(a.overridableProperties.Key))
 				if len(moduleNames) > 0 {
 					fmt.Fprintln(w, "LOCAL_REQUIRED_MODULES +=", strings.Join(moduleNames, " "))
 				}
 				if len(a.externalDeps) > 0 {
 					fmt.Fprintln(w, "LOCAL_REQUIRED_MODULES +=", strings.Join(a.externalDeps, " "))
 				}
+				if len(a.overridableProperties.Overrides) > 0 {
+					fmt.Fprintln(w, "LOCAL_OVERRIDES_MODULES :=", strings.Join(a.overridableProperties.Overrides, " "))
+				}
 				fmt.Fprintln(w, "include $(BUILD_PREBUILT)")
 
 				if apexType == imageApex {
@@ -1194,11 +1256,13 @@ func ApexBundleFactory(testApex bool) android.Module {
 	}
 	module.AddProperties(&module.properties)
 	module.AddProperties(&module.targetProperties)
+	module.AddProperties(&module.overridableProperties)
 	module.Prefer32(func(ctx android.BaseModuleContext, base *android.ModuleBase, class android.OsClass) bool {
 		return class == android.Device && ctx.Config().DevicePrefer32BitExecutables()
 	})
 	android.InitAndroidMultiTargetsArchModule(module, android.HostAndDeviceSupported, android.MultilibCommon)
 	android.InitDefaultableModule(module)
+	android.InitOverridableModule(module, &module.overridableProperties.Overrides, &module.overridableProperties)
 	return module
 }
 
@@ -1224,8 +1288,33 @@ func DefaultsFactory(props ...interface{}) android.Module {
 	module.AddProperties(
 		&apexBundleProperties{},
 		&apexTargetBundleProperties{},
+		&overridableProperties{},
 	)
 
 	android.InitDefaultsModule(module)
 	return module
 }
+
+//
+// override_apex
+//
+type OverrideApex struct {
+	android.ModuleBase
+	android.OverrideModuleBase
+}
+
+func (o *OverrideApex) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	// All the overrides happen in the base module.
+}
+
+// override_apex is used to create an apex module based on another apex module by overriding some of
+// its properties.  The contents of the base module are built once and packaged into a variant of the
+// base module that is signed and installed under the name of the override_apex.
+func overrideApexFactory() android.Module {
+	m := &OverrideApex{}
+	m.AddProperties(&overridableProperties{})
+
+	android.InitAndroidModule(m)
+	android.InitOverrideModule(m)
+	return m
+}
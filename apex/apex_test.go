@@ -17,6 +17,7 @@ package apex
 import (
 	"io/ioutil"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -25,6 +26,7 @@ import (
 	"android/soong/android"
 	"android/soong/cc"
 	"android/soong/java"
+	"android/soong/sh"
 )
 
 func testApex(t *testing.T, bp string) *android.TestContext {
@@ -36,7 +38,9 @@ func testApex(t *testing.T, bp string) *android.TestContext {
 	ctx.RegisterModuleType("apex_test", android.ModuleFactoryAdaptor(testApexBundleFactory))
 	ctx.RegisterModuleType("apex_key", android.ModuleFactoryAdaptor(apexKeyFactory))
 	ctx.RegisterModuleType("apex_defaults", android.ModuleFactoryAdaptor(defaultsFactory))
+	ctx.RegisterModuleType("override_apex", android.ModuleFactoryAdaptor(overrideApexFactory))
 	ctx.PreArchMutators(android.RegisterDefaultsPreArchMutators)
+	ctx.PreArchMutators(android.RegisterOverridePreArchMutators)
 
 	ctx.PostDepsMutators(func(ctx android.RegisterMutatorsContext) {
 		ctx.TopDown("apex_deps", apexDepsMutator)
@@ -51,7 +55,7 @@ func testApex(t *testing.T, bp string) *android.TestContext {
 	ctx.RegisterModuleType("llndk_library", android.ModuleFactoryAdaptor(cc.LlndkLibraryFactory))
 	ctx.RegisterModuleType("toolchain_library", android.ModuleFactoryAdaptor(cc.ToolchainLibraryFactory))
 	ctx.RegisterModuleType("prebuilt_etc", android.ModuleFactoryAdaptor(android.PrebuiltEtcFactory))
-	ctx.RegisterModuleType("sh_binary", android.ModuleFactoryAdaptor(android.ShBinaryFactory))
+	ctx.RegisterModuleType("sh_binary", android.ModuleFactoryAdaptor(sh.ShBinaryFactory))
 	ctx.RegisterModuleType("android_app_certificate", android.ModuleFactoryAdaptor(java.AndroidAppCertificateFactory))
 	ctx.RegisterModuleType("filegroup", android.ModuleFactoryAdaptor(android.FileGroupFactory))
 	ctx.PreDepsMutators(func(ctx android.RegisterMutatorsContext) {
@@ -453,6 +457,65 @@ func TestApexWithStubs(t *testing.T) {
 	ensureContains(t, "--apex", ctx.ModuleForTests("mylib2", "android_arm64_armv8-a_core_static_3_myapex").Rule("genStubSrc").Args["flags"])
 }
 
+func TestApexLinkerNamespace(t *testing.T) {
+	ctx := testApex(t, `
+		apex {
+			name: "myapex",
+			key: "myapex.key",
+			native_shared_libs: ["mylib", "mylib3"],
+		}
+
+		apex_key {
+			name: "myapex.key",
+			public_key: "testkey.avbpubkey",
+			private_key: "testkey.pem",
+		}
+
+		cc_library {
+			name: "mylib",
+			srcs: ["mylib.cpp"],
+			shared_libs: ["mylib2", "mylib3"],
+			system_shared_libs: [],
+			stl: "none",
+		}
+
+		cc_library {
+			name: "mylib2",
+			srcs: ["mylib.cpp"],
+			system_shared_libs: [],
+			stl: "none",
+			stubs: {
+				versions: ["1", "2", "3"],
+			},
+		}
+
+		cc_library {
+			name: "mylib3",
+			srcs: ["mylib.cpp"],
+			system_shared_libs: [],
+			stl: "none",
+			stubs: {
+				versions: ["10", "11", "12"],
+			},
+		}
+	`)
+
+	apex := ctx.ModuleForTests("myapex", "android_common_myapex").Module().(*apexBundle)
+	name, provideLibs, requireLibs := apex.LinkerNamespace()
+
+	if name != "myapex" {
+		t.Errorf("expected linker namespace %q, got %q", "myapex", name)
+	}
+	// mylib3 has stubs, so the platform can use it.
+	if expected := []string{"mylib3.so"}; !reflect.DeepEqual(provideLibs, expected) {
+		t.Errorf("expected provided libs %q, got %q", expected, provideLibs)
+	}
+	// mylib2 is used through its stubs, while mylib3 is linked directly inside the APEX.
+	if expected := []string{"mylib2.so"}; !reflect.DeepEqual(requireLibs, expected) {
+		t.Errorf("expected required libs %q, got %q", expected, requireLibs)
+	}
+}
+
 func TestApexWithExplicitStubsDependency(t *testing.T) {
 	ctx := testApex(t, `
 		apex {
@@ -1144,3 +1207,64 @@ func TestApexWithShBinary(t *testing.T) {
 
 	ensureContains(t, copyCmds, "image.apex/bin/script/myscript.sh")
 }
+
+func TestOverrideApex(t *testing.T) {
+	ctx := testApex(t, `
+		apex {
+			name: "myapex",
+			key: "myapex.key",
+			native_shared_libs: ["mylib"],
+			overrides: ["oldapex"],
+		}
+
+		override_apex {
+			name: "override_myapex",
+			base: "myapex",
+			key: "myapex.key",
+			certificate: ":myapex.certificate",
+		}
+
+		apex_key {
+			name: "myapex.key",
+			public_key: "testkey.avbpubkey",
+			private_key: "testkey.pem",
+		}
+
+		android_app_certificate {
+			name: "myapex.certificate",
+			certificate: "testkey",
+		}
+
+		cc_library {
+			name: "mylib",
+			srcs: ["mylib.cpp"],
+			system_shared_libs: [],
+			stl: "none",
+		}
+	`)
+
+	originalVariant := ctx.ModuleForTests("myapex", "android_common_myapex").Module().(*apexBundle)
+	if originalVariant.GetOverriddenBy() != "" {
+		t.Errorf("original variant is overridden by %q", originalVariant.GetOverriddenBy())
+	}
+
+	module := ctx.ModuleForTests("myapex", "override_myapex_android_common_myapex")
+	apexBundle := module.Module().(*apexBundle)
+	if overriddenBy := apexBundle.GetOverriddenBy(); overriddenBy != "override_myapex" {
+		t.Errorf("override variant is overridden by %q, expected %q", overriddenBy, "override_myapex")
+	}
+
+	apexRule := module.Rule("apexRule")
+	ensureContains(t, apexRule.Output.String(), "override_myapex.apex.unsigned")
+	ensureContains(t, apexRule.Args["copy_commands"], "image.apex/lib64/mylib.so")
+
+	certs := module.Rule("signapk").Args["certificates"]
+	if expected := "testkey.x509.pem testkey.pk8"; certs != expected {
+		t.Errorf("signing certificates are %q, expected %q", certs, expected)
+	}
+
+	if !reflect.DeepEqual(apexBundle.overridableProperties.Overrides, []string{"oldapex", "myapex"}) {
+		t.Errorf("overrides of the override variant are %q, expected %q",
+			apexBundle.overridableProperties.Overrides, []string{"oldapex", "myapex"})
+	}
+}
@@ -146,6 +146,15 @@ var (
 		},
 		"crossCompile", "format")
 
+	_ = pctx.SourcePathVariable("checkStubSymbolsPath", "build/soong/scripts/check_stub_symbols.sh")
+
+	stubSymbolsCheck = pctx.AndroidStaticRule("stubSymbolsCheck",
+		blueprint.RuleParams{
+			Command:     "CROSS_COMPILE=$crossCompile $checkStubSymbolsPath -m $module -o ${out} $stubs ${in}",
+			CommandDeps: []string{"$checkStubSymbolsPath"},
+		},
+		"crossCompile", "module", "stubs")
+
 	clangTidy = pctx.AndroidStaticRule("clangTidy",
 		blueprint.RuleParams{
 			Command:     "rm -f $out && CLANG_TIDY=${config.ClangBin}/clang-tidy ${config.ClangTidyShellPath} $tidyFlags $in -- $cFlags && touch $out",
@@ -193,20 +202,29 @@ var (
 		"symbolFilter", "arch", "exportedHeaderFlags")
 
 	_ = pctx.SourcePathVariable("sAbiDiffer", "prebuilts/clang-tools/${config.HostPrebuiltTag}/bin/header-abi-diff")
+	_ = pctx.SourcePathVariable("sAbiDiffSummary", "build/soong/scripts/abi_diff_summary.sh")
 
 	sAbiDiff = pctx.AndroidRuleFunc("sAbiDiff",
 		func(ctx android.PackageRuleContext) blueprint.RuleParams {
 			// TODO(b/78139997): Add -check-all-apis back
 			commandStr := "($sAbiDiffer ${allowFlags} -lib ${libName} -arch ${arch} -o ${out} -new ${in} -old ${referenceDump})"
-			commandStr += "|| (echo 'error: Please update ABI references with: $$ANDROID_BUILD_TOP/development/vndk/tools/header-checker/utils/create_reference_dumps.py ${createReferenceDumpFlags} -l ${libName}'"
+			commandStr += "|| ($sAbiDiffSummary -l ${libName} -a ${arch} -r ${referenceDump} ${out}"
+			commandStr += " && echo 'error: Please update ABI references with: ${updateCommand}'"
 			commandStr += " && (mkdir -p $$DIST_DIR/abidiffs && cp ${out} $$DIST_DIR/abidiffs/)"
 			commandStr += " && exit 1)"
 			return blueprint.RuleParams{
 				Command:     commandStr,
-				CommandDeps: []string{"$sAbiDiffer"},
+				CommandDeps: []string{"$sAbiDiffer", "$sAbiDiffSummary"},
 			}
 		},
-		"allowFlags", "referenceDump", "libName", "arch", "createReferenceDumpFlags")
+		"allowFlags", "referenceDump", "libName", "arch", "updateCommand")
+
+	sAbiUpdateRefDump = pctx.AndroidStaticRule("sAbiUpdateRefDump",
+		blueprint.RuleParams{
+			Command: "mkdir -p $$(dirname ${referenceDump}) && ${copy} < $in > ${referenceDump} && " +
+				"echo 'Updated ABI reference ${referenceDump}' && touch $out",
+		},
+		"referenceDump", "copy")
 
 	unzipRefSAbiDump = pctx.AndroidStaticRule("unzipRefSAbiDump",
 		blueprint.RuleParams{
@@ -710,8 +728,11 @@ func UnzipRefDump(ctx android.ModuleContext, zippedRefDump android.Path, baseNam
 	return outputFile
 }
 
+// Generate a rule to compare a linked sAbi dump file against a reference dump.  If updateTarget is
+// not empty it is the target that updates the reference dump, otherwise the reference dump is
+// updated with create_reference_dumps.py.
 func SourceAbiDiff(ctx android.ModuleContext, inputDump android.Path, referenceDump android.Path,
-	baseName, exportedHeaderFlags string, isLlndk, isVndkExt bool) android.OptionalPath {
+	baseName, exportedHeaderFlags string, isLlndk, isVndkExt bool, updateTarget string) android.OptionalPath {
 
 	outputFile := android.PathForModuleOut(ctx, baseName+".abidiff")
 	libName := strings.TrimSuffix(baseName, filepath.Ext(baseName))
@@ -729,6 +750,15 @@ func SourceAbiDiff(ctx android.ModuleContext, inputDump android.Path, referenceD
 		localAbiCheckAllowFlags = append(localAbiCheckAllowFlags, "-allow-extensions")
 	}
 
+	updateCommand := "m " + updateTarget
+	if updateTarget == "" {
+		updateCommand = "$$ANDROID_BUILD_TOP/development/vndk/tools/header-checker/utils/create_reference_dumps.py"
+		if createReferenceDumpFlags != "" {
+			updateCommand += " " + createReferenceDumpFlags
+		}
+		updateCommand += " -l " + libName
+	}
+
 	ctx.Build(pctx, android.BuildParams{
 		Rule:        sAbiDiff,
 		Description: "header-abi-diff " + outputFile.Base(),
@@ -736,11 +766,34 @@ func SourceAbiDiff(ctx android.ModuleContext, inputDump android.Path, referenceD
 		Input:       inputDump,
 		Implicit:    referenceDump,
 		Args: map[string]string{
-			"referenceDump":            referenceDump.String(),
-			"libName":                  libName,
-			"arch":                     ctx.Arch().ArchType.Name,
-			"allowFlags":               strings.Join(localAbiCheckAllowFlags, " "),
-			"createReferenceDumpFlags": createReferenceDumpFlags,
+			"referenceDump": referenceDump.String(),
+			"libName":       libName,
+			"arch":          ctx.Arch().ArchType.Name,
+			"allowFlags":    strings.Join(localAbiCheckAllowFlags, " "),
+			"updateCommand": updateCommand,
+		},
+	})
+	return android.OptionalPathForPath(outputFile)
+}
+
+// Generate a rule to copy a linked sAbi dump file over its reference dump in the source tree,
+// gzipping it if the reference dump is gzipped.
+func UpdateRefDump(ctx android.ModuleContext, inputDump android.Path, referenceDump android.Path,
+	baseName string) android.OptionalPath {
+
+	outputFile := android.PathForModuleOut(ctx, baseName+".ref_update.stamp")
+	copyCmd := "cat"
+	if strings.HasSuffix(referenceDump.String(), ".gz") {
+		copyCmd = "gzip -c -n"
+	}
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        sAbiUpdateRefDump,
+		Description: "update ABI reference " + referenceDump.Base(),
+		Output:      outputFile,
+		Input:       inputDump,
+		Args: map[string]string{
+			"referenceDump": referenceDump.String(),
+			"copy":          copyCmd,
 		},
 	})
 	return android.OptionalPathForPath(outputFile)
@@ -775,6 +828,32 @@ func TransformSharedObjectToToc(ctx android.ModuleContext, inputFile android.Pat
 	})
 }
 
+// Generate a rule for checking that the object files and static libraries linked into a module only
+// use the symbols in the versions of the stubs of the shared libraries it links against
+func checkStubSymbols(ctx android.ModuleContext, inputs android.Paths, stubs []linkedStub,
+	flags builderFlags, outputFile android.WritablePath) {
+
+	var stubArgs []string
+	var implicits android.Paths
+	for _, stub := range stubs {
+		stubArgs = append(stubArgs, "-s "+stub.name+":"+stub.version+":"+stub.omittedSymbols.String())
+		implicits = append(implicits, stub.omittedSymbols)
+	}
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        stubSymbolsCheck,
+		Description: "check stub symbols " + outputFile.Base(),
+		Output:      outputFile,
+		Inputs:      inputs,
+		Implicits:   implicits,
+		Args: map[string]string{
+			"crossCompile": gccCmd(flags.toolchain, ""),
+			"module":       ctx.ModuleName(),
+			"stubs":        strings.Join(stubArgs, " "),
+		},
+	})
+}
+
 // Generate a rule for compiling multiple .o files to a .o using ld partial linking
 func TransformObjsToObj(ctx android.ModuleContext, objFiles android.Paths,
 	flags builderFlags, outputFile android.WritablePath) {
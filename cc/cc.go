@@ -60,6 +60,9 @@ func init() {
 		ctx.TopDown("tsan_deps", sanitizerDepsMutator(tsan))
 		ctx.BottomUp("tsan", sanitizerMutator(tsan)).Parallel()
 
+		ctx.TopDown("fuzzer_deps", sanitizerDepsMutator(fuzzer))
+		ctx.BottomUp("fuzzer", sanitizerMutator(fuzzer)).Parallel()
+
 		ctx.TopDown("sanitize_runtime_deps", sanitizerRuntimeDepsMutator)
 		ctx.BottomUp("sanitize_runtime", sanitizerRuntimeMutator).Parallel()
 
@@ -125,6 +128,18 @@ type PathDeps struct {
 
 	// Path to the dynamic linker binary
 	DynamicLinker android.OptionalPath
+
+	// Stubs of shared libraries the module links against
+	LinkedStubs []linkedStub
+}
+
+// linkedStub is a version of the stubs of a shared library that a module links against.
+type linkedStub struct {
+	name    string
+	version string
+
+	// The list of the symbols of the library that are not in this version of its stubs
+	omittedSymbols android.Path
 }
 
 type Flags struct {
@@ -187,6 +202,12 @@ type BaseProperties struct {
 	// Minimum sdk version supported when compiling against the ndk
 	Sdk_version *string
 
+	// Minimum sdk version of the platform the module runs on.  When linking against a shared library
+	// through its stubs, the highest stubs version that is not higher than this one is used, so that
+	// the module only uses the symbols available on all the releases it supports.  Defaults to
+	// "current", the latest stubs version.
+	Min_sdk_version *string
+
 	AndroidMkSharedLibs       []string `blueprint:"mutated"`
 	AndroidMkStaticLibs       []string `blueprint:"mutated"`
 	AndroidMkRuntimeLibs      []string `blueprint:"mutated"`
@@ -255,6 +276,7 @@ type ModuleContextIntf interface {
 	isVndkSp() bool
 	isVndkExt() bool
 	inRecovery() bool
+	canCreateSourceAbiDump() bool
 	shouldCreateVndkSourceAbiDump() bool
 	selectedStl() string
 	baseModuleName() string
@@ -359,6 +381,18 @@ var (
 	runtimeDepTag         = dependencyTag{name: "runtime lib"}
 )
 
+var _ android.LicensePropagatingDependencyTag = dependencyTag{}
+
+// PropagatesLicenses returns true for the dependencies that are linked into the module.
+func (d dependencyTag) PropagatesLicenses() bool {
+	switch d {
+	case staticDepTag, staticExportDepTag, lateStaticDepTag, wholeStaticDepTag,
+		objDepTag, crtBeginDepTag, crtEndDepTag, reuseObjTag:
+		return true
+	}
+	return false
+}
+
 // Module contains the properties and members used by all C/C++ module types, and implements
 // the blueprint.Module interface.  It delegates to compiler, linker, and installer interfaces
 // to construct the output file.  Behavior can be customized with a Customizer interface
@@ -412,6 +446,15 @@ func (c *Module) OutputFile() android.OptionalPath {
 	return c.outputFile
 }
 
+// ExportedIncludeDirs returns the source directories whose headers the module exports to the modules
+// that depend on it.
+func (c *Module) ExportedIncludeDirs() android.Paths {
+	if i, ok := c.linker.(exportedIncludeDirsProducer); ok {
+		return i.exportedIncludeDirs()
+	}
+	return nil
+}
+
 func (c *Module) UnstrippedOutputFile() android.Path {
 	if c.linker != nil {
 		return c.linker.unstrippedOutputFilePath()
@@ -588,6 +631,13 @@ func (c *Module) IsStubs() bool {
 	return false
 }
 
+func (c *Module) headerAbiCheckerEnabled() bool {
+	if library, ok := c.linker.(*libraryDecorator); ok {
+		return library.headerAbiCheckerEnabled()
+	}
+	return false
+}
+
 func (c *Module) HasStubsVariants() bool {
 	if library, ok := c.linker.(*libraryDecorator); ok {
 		return len(library.Properties.Stubs.Versions) > 0
@@ -595,6 +645,23 @@ func (c *Module) HasStubsVariants() bool {
 	return false
 }
 
+// partition returns the partition the module is installed in.  Shared libraries with stubs are
+// linked through their stubs by modules installed in other partitions.
+func (c *Module) partition() string {
+	switch {
+	case c.useVndk() || c.SocSpecific():
+		return "vendor"
+	case c.DeviceSpecific():
+		return "odm"
+	case c.ProductSpecific():
+		return "product"
+	case c.ProductServicesSpecific():
+		return "product_services"
+	default:
+		return "system"
+	}
+}
+
 func (c *Module) bootstrap() bool {
 	return Bool(c.Properties.Bootstrap)
 }
@@ -705,8 +772,8 @@ func (ctx *moduleContextImpl) inRecovery() bool {
 	return ctx.mod.inRecovery()
 }
 
-// Check whether ABI dumps should be created for this module.
-func (ctx *moduleContextImpl) shouldCreateVndkSourceAbiDump() bool {
+// Check whether ABI dumps can be created for this variant of the module.
+func (ctx *moduleContextImpl) canCreateSourceAbiDump() bool {
 	if ctx.ctx.Config().IsEnvTrue("SKIP_ABI_CHECKS") {
 		return false
 	}
@@ -728,6 +795,14 @@ func (ctx *moduleContextImpl) shouldCreateVndkSourceAbiDump() bool {
 		// APEX variants do not need ABI dumps.
 		return false
 	}
+	return true
+}
+
+// Check whether ABI dumps should be created for this module.
+func (ctx *moduleContextImpl) shouldCreateVndkSourceAbiDump() bool {
+	if !ctx.canCreateSourceAbiDump() {
+		return false
+	}
 	if ctx.isNdk() {
 		return true
 	}
@@ -960,6 +1035,14 @@ func (c *Module) GenerateAndroidBuildActions(actx android.ModuleContext) {
 	}
 
 	if c.linker != nil {
+		if len(deps.LinkedStubs) > 0 && !ctx.Darwin() && !ctx.Windows() {
+			// Check that the module only uses the symbols in the versions of the stubs it links
+			// against before linking, which would fail with a less helpful error.
+			checkFile := android.PathForModuleOut(ctx, "stub_symbols.check")
+			checkStubSymbols(ctx, linkInputs(objs, deps), deps.LinkedStubs, flagsToBuilderFlags(flags), checkFile)
+			flags.LdFlagsDeps = append(flags.LdFlagsDeps, checkFile)
+		}
+
 		outputFile := c.linker.link(ctx, flags, deps, objs)
 		if ctx.Failed() {
 			return
@@ -1120,6 +1203,16 @@ func (c *Module) beginMutator(actx android.BottomUpMutatorContext) {
 	c.begin(ctx)
 }
 
+// linkInputs returns the object files and whole static libraries that are linked into the module.
+// Static libraries are not included, as only the members that are needed are linked.
+func linkInputs(objs Objects, deps PathDeps) android.Paths {
+	var inputs android.Paths
+	inputs = append(inputs, objs.objFiles...)
+	inputs = append(inputs, deps.Objs.objFiles...)
+	inputs = append(inputs, deps.WholeStaticLibs...)
+	return inputs
+}
+
 // Split name#version into name and version
 func stubsLibNameAndVersion(name string) (string, string) {
 	if sharp := strings.LastIndex(name, "#"); sharp != -1 && sharp != len(name)-1 {
@@ -1261,7 +1354,7 @@ func (c *Module) DepsMutator(actx android.BottomUpMutatorContext) {
 	addSharedLibDependencies := func(depTag dependencyTag, name string, version string) {
 		var variations []blueprint.Variation
 		variations = append(variations, blueprint.Variation{Mutator: "link", Variation: "shared"})
-		versionVariantAvail := !ctx.useVndk() && !c.inRecovery()
+		versionVariantAvail := !c.inRecovery()
 		if version != "" && versionVariantAvail {
 			// Version is explicitly specified. i.e. libFoo#30
 			variations = append(variations, blueprint.Variation{Mutator: "version", Variation: version})
@@ -1269,16 +1362,21 @@ func (c *Module) DepsMutator(actx android.BottomUpMutatorContext) {
 		}
 		actx.AddVariationDependencies(variations, depTag, name)
 
-		// If the version is not specified, add dependency to the latest stubs library.
-		// The stubs library will be used when the depending module is built for APEX and
-		// the dependent module is not in the same APEX.
-		latestVersion := latestStubsVersionFor(actx.Config(), name)
-		if version == "" && latestVersion != "" && versionVariantAvail {
-			actx.AddVariationDependencies([]blueprint.Variation{
-				{Mutator: "link", Variation: "shared"},
-				{Mutator: "version", Variation: latestVersion},
-			}, depTag, name)
-			// Note that depTag.explicitlyVersioned is false in this case.
+		// If the version is not specified, add dependency to the stubs library selected by
+		// min_sdk_version. The stubs library will be used when the depending module is built
+		// for APEX and the dependent module is not in the same APEX, or when they are installed
+		// in different partitions.
+		if version == "" && versionVariantAvail {
+			// If no stubs version is compatible with min_sdk_version the error is reported by
+			// depsToPaths, only if the stubs would have been linked against.
+			stubsVersion, err := stubsVersionFor(actx.Config(), name, String(c.Properties.Min_sdk_version))
+			if err == nil && stubsVersion != "" {
+				actx.AddVariationDependencies([]blueprint.Variation{
+					{Mutator: "link", Variation: "shared"},
+					{Mutator: "version", Variation: stubsVersion},
+				}, depTag, name)
+				// Note that depTag.explicitlyVersioned is false in this case.
+			}
 		}
 	}
 
@@ -1618,6 +1716,7 @@ func (c *Module) depsToPaths(ctx android.ModuleContext) PathDeps {
 				depHasStubs := ccDep.HasStubsVariants()
 				depInSameApex := android.DirectlyInApex(c.ApexName(), depName)
 				depInPlatform := !android.DirectlyInAnyApex(ctx, depName)
+				depInSamePartition := c.partition() == ccDep.partition()
 
 				var useThisDep bool
 				if depIsStubs && explicitlyVersioned {
@@ -1629,8 +1728,9 @@ func (c *Module) depsToPaths(ctx android.ModuleContext) PathDeps {
 					useThisDep = true
 				} else if c.IsForPlatform() {
 					// If not building for APEX, use stubs only when it is from
-					// an APEX (and not from platform)
-					useThisDep = (depInPlatform != depIsStubs)
+					// an APEX (and not from platform), or installed in another
+					// partition
+					useThisDep = (depInPlatform && depInSamePartition) != depIsStubs
 					if c.inRecovery() || c.bootstrap() {
 						// However, for recovery or bootstrap modules,
 						// always link to non-stub variant
@@ -1643,8 +1743,24 @@ func (c *Module) depsToPaths(ctx android.ModuleContext) PathDeps {
 				}
 
 				if !useThisDep {
+					if !depIsStubs {
+						// The stubs are used instead of this variant, but no stubs variant was
+						// added if none is compatible with min_sdk_version.
+						_, err := stubsVersionFor(ctx.Config(), depName, String(c.Properties.Min_sdk_version))
+						if err != nil {
+							ctx.PropertyErrorf("min_sdk_version", "%s", err)
+						}
+					}
 					return // stop processing this dep
 				}
+
+				if depIsStubs && dependentLibrary.stubOmittedSymbols() != nil {
+					depPaths.LinkedStubs = append(depPaths.LinkedStubs, linkedStub{
+						name:           depName,
+						version:        dependentLibrary.stubsVersion(),
+						omittedSymbols: dependentLibrary.stubOmittedSymbols(),
+					})
+				}
 			}
 
 			if i, ok := ccDep.linker.(exportedFlagsProducer); ok {
@@ -64,6 +64,11 @@ func createTestContext(t *testing.T, config android.Config, bp string, os androi
 	ctx.RegisterModuleType("vendor_public_library", android.ModuleFactoryAdaptor(vendorPublicLibraryFactory))
 	ctx.RegisterModuleType("cc_object", android.ModuleFactoryAdaptor(ObjectFactory))
 	ctx.RegisterModuleType("filegroup", android.ModuleFactoryAdaptor(android.FileGroupFactory))
+	ctx.RegisterModuleType("cc_fuzz", android.ModuleFactoryAdaptor(FuzzFactory))
+	ctx.RegisterModuleType("linker_config_file", android.ModuleFactoryAdaptor(LinkerConfigFileFactory))
+	ctx.RegisterSingletonType("cc_fuzz_packaging", android.SingletonFactoryAdaptor(fuzzPackagingFactory))
+	ctx.RegisterSingletonType("abi_references", android.SingletonFactoryAdaptor(abiReferencesSingletonFactory))
+	ctx.RegisterSingletonType("linker_config", android.SingletonFactoryAdaptor(linkerConfigSingletonFactory))
 	ctx.PreDepsMutators(func(ctx android.RegisterMutatorsContext) {
 		ctx.BottomUp("image", ImageMutator).Parallel()
 		ctx.BottomUp("link", LinkageMutator).Parallel()
@@ -72,6 +77,7 @@ func createTestContext(t *testing.T, config android.Config, bp string, os androi
 		ctx.BottomUp("begin", BeginMutator).Parallel()
 	})
 	ctx.PostDepsMutators(func(ctx android.RegisterMutatorsContext) {
+		ctx.TopDown("vndk_deps", sabiDepsMutator)
 		ctx.TopDown("double_loadable", checkDoubleLoadableLibraries).Parallel()
 	})
 	ctx.Register()
@@ -80,13 +86,24 @@ func createTestContext(t *testing.T, config android.Config, bp string, os androi
 	bp = bp + GatherRequiredDepsForTest(os)
 
 	ctx.MockFileSystem(map[string][]byte{
-		"Android.bp":  []byte(bp),
-		"foo.c":       nil,
-		"bar.c":       nil,
-		"a.proto":     nil,
-		"b.aidl":      nil,
-		"my_include":  nil,
-		"foo.map.txt": nil,
+		"Android.bp":   []byte(bp),
+		"foo.c":        nil,
+		"bar.c":        nil,
+		"a.proto":      nil,
+		"b.aidl":       nil,
+		"my_include":   nil,
+		"foo.map.txt":  nil,
+		"fuzz.dict":    nil,
+		"fuzz.options": nil,
+		"corpus/seed1": nil,
+		"corpus/seed2": nil,
+		"seeds/seed1":  nil,
+
+		"abi-dumps/arm64_armv8-a/libabi.so.lsdump":       nil,
+		"abi-dumps/arm_armv7-a-neon/libabi.so.lsdump.gz": nil,
+
+		"build/soong/scripts/gen_linker_config.py": nil,
+		"extra_links.json":                         nil,
 	})
 
 	return ctx
@@ -131,6 +148,19 @@ func testCcError(t *testing.T, pattern string, bp string) {
 	config.TestProductVariables.DeviceVndkVersion = StringPtr("current")
 	config.TestProductVariables.Platform_vndk_version = StringPtr("VER")
 
+	testCcErrorWithConfig(t, pattern, bp, config)
+}
+
+func testCcErrorNoVndk(t *testing.T, pattern string, bp string) {
+	t.Helper()
+	config := android.TestArchConfig(buildDir, nil)
+	config.TestProductVariables.Platform_vndk_version = StringPtr("VER")
+
+	testCcErrorWithConfig(t, pattern, bp, config)
+}
+
+func testCcErrorWithConfig(t *testing.T, pattern string, bp string, config android.Config) {
+	t.Helper()
 	ctx := createTestContext(t, config, bp, android.Android)
 
 	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
@@ -2090,6 +2120,98 @@ func TestVersionedStubs(t *testing.T) {
 	}
 }
 
+func TestStubsAcrossPartitions(t *testing.T) {
+	ctx := testCcNoVndk(t, `
+		cc_library_shared {
+			name: "libFoo",
+			srcs: ["foo.c"],
+			stubs: {
+				symbol_file: "foo.map.txt",
+				versions: ["1", "2", "3"],
+			},
+		}
+
+		cc_library_shared {
+			name: "libSystem",
+			srcs: ["bar.c"],
+			shared_libs: ["libFoo"],
+		}
+
+		cc_library_shared {
+			name: "libVendor",
+			srcs: ["bar.c"],
+			shared_libs: ["libFoo"],
+			vendor: true,
+		}
+
+		cc_library_shared {
+			name: "libVendorMinSdk",
+			srcs: ["bar.c"],
+			shared_libs: ["libFoo"],
+			vendor: true,
+			min_sdk_version: "2",
+		}
+
+		cc_library_shared {
+			name: "libSystemMinSdk",
+			srcs: ["bar.c"],
+			shared_libs: ["libFoo"],
+			min_sdk_version: "1",
+		}`)
+
+	checkLibFoo := func(module string, expected string) {
+		t.Helper()
+		libFlags := ctx.ModuleForTests(module, coreVariant).Rule("ld").Args["libFlags"]
+		if !strings.Contains(libFlags, expected) {
+			t.Errorf("%q is not found in the libFlags of %s %q", expected, module, libFlags)
+		}
+	}
+
+	// Modules in the same partition link against the implementation.
+	checkLibFoo("libSystem", "libFoo/android_arm64_armv8-a_core_shared/libFoo.so")
+	// min_sdk_version doesn't matter when the stubs are not used.
+	checkLibFoo("libSystemMinSdk", "libFoo/android_arm64_armv8-a_core_shared/libFoo.so")
+	// Modules in other partitions link against the stubs, the latest version by default.
+	checkLibFoo("libVendor", "libFoo/android_arm64_armv8-a_core_shared_3/libFoo.so")
+	// The stubs version is the highest version that is not higher than min_sdk_version.
+	checkLibFoo("libVendorMinSdk", "libFoo/android_arm64_armv8-a_core_shared_2/libFoo.so")
+
+	// Modules linking against stubs check that they only use the symbols of the stubs version.
+	libVendor := ctx.ModuleForTests("libVendor", coreVariant)
+	check := libVendor.Output("stub_symbols.check")
+	omittedSymbols := "libFoo/android_arm64_armv8-a_core_shared_3/gen/stub.omitted"
+	if !strings.Contains(check.Args["stubs"], "-s libFoo:3:") || !strings.Contains(check.Args["stubs"], omittedSymbols) {
+		t.Errorf("expected the stub symbols check of libVendor to check %s, got %q", omittedSymbols, check.Args["stubs"])
+	}
+	if !inList(check.Output.String(), libVendor.Rule("ld").Implicits.Strings()) {
+		t.Errorf("expected libVendor to be linked after the stub symbols check")
+	}
+
+	if ctx.ModuleForTests("libSystem", coreVariant).MaybeOutput("stub_symbols.check").Rule != nil {
+		t.Errorf("expected no stub symbols check for libSystem")
+	}
+}
+
+func TestStubsMinSdkVersionError(t *testing.T) {
+	testCcErrorNoVndk(t, `"libFoo" has no stubs version compatible with min_sdk_version 1, the lowest is 2`, `
+		cc_library_shared {
+			name: "libFoo",
+			srcs: ["foo.c"],
+			stubs: {
+				symbol_file: "foo.map.txt",
+				versions: ["2", "3"],
+			},
+		}
+
+		cc_library_shared {
+			name: "libBar",
+			srcs: ["bar.c"],
+			shared_libs: ["libFoo"],
+			vendor: true,
+			min_sdk_version: "1",
+		}`)
+}
+
 func TestStaticExecutable(t *testing.T) {
 	ctx := testCc(t, `
 		cc_binary {
@@ -2154,3 +2276,236 @@ func TestStaticDepsOrderWithStubs(t *testing.T) {
 		)
 	}
 }
+
+func TestHeaderAbiChecker(t *testing.T) {
+	ctx := testCcNoVndk(t, `
+		cc_library {
+			name: "libabi",
+			srcs: ["foo.c"],
+			static_libs: ["libabi_static"],
+			header_abi_checker: {
+				enabled: true,
+				ref_dump_dir: "abi-dumps",
+			},
+		}
+
+		cc_library_static {
+			name: "libabi_static",
+			srcs: ["bar.c"],
+		}
+
+		cc_library_shared {
+			name: "libnoabi",
+			srcs: ["foo.c"],
+		}
+	`)
+
+	arm64 := ctx.ModuleForTests("libabi", "android_arm64_armv8-a_core_shared")
+	lsdump := arm64.Output("libabi.so.lsdump")
+
+	// The reference dump is read from ref_dump_dir.
+	diff := arm64.Output("libabi.so.abidiff")
+	if diff.Input.String() != lsdump.Output.String() {
+		t.Errorf("expected the ABI diff input to be %q, got %q", lsdump.Output, diff.Input)
+	}
+	if g, w := diff.Args["referenceDump"], "abi-dumps/arm64_armv8-a/libabi.so.lsdump"; g != w {
+		t.Errorf("expected reference dump %q, got %q", w, g)
+	}
+	if g, w := diff.Args["updateCommand"], "m update-abi-references-libabi"; g != w {
+		t.Errorf("expected update command %q, got %q", w, g)
+	}
+
+	update := arm64.Output("libabi.so.ref_update.stamp")
+	if g, w := update.Args["referenceDump"], "abi-dumps/arm64_armv8-a/libabi.so.lsdump"; g != w {
+		t.Errorf("expected updated reference dump %q, got %q", w, g)
+	}
+	if g, w := update.Args["copy"], "cat"; g != w {
+		t.Errorf("expected reference dump to be copied with %q, got %q", w, g)
+	}
+
+	// Gzipped reference dumps are unzipped before diffing, and stay gzipped when they are updated.
+	arm := ctx.ModuleForTests("libabi", "android_arm_armv7-a-neon_core_shared")
+	unzip := arm.Output("libabi.so_ref.lsdump")
+	if g, w := arm.Output("libabi.so.abidiff").Args["referenceDump"], unzip.Output.String(); g != w {
+		t.Errorf("expected reference dump %q, got %q", w, g)
+	}
+	armUpdate := arm.Output("libabi.so.ref_update.stamp")
+	if g, w := armUpdate.Args["referenceDump"], "abi-dumps/arm_armv7-a-neon/libabi.so.lsdump.gz"; g != w {
+		t.Errorf("expected updated reference dump %q, got %q", w, g)
+	}
+	if g, w := armUpdate.Args["copy"], "gzip -c -n"; g != w {
+		t.Errorf("expected reference dump to be copied with %q, got %q", w, g)
+	}
+
+	// Only the shared variant is checked against the reference dumps.
+	static := ctx.ModuleForTests("libabi", "android_arm64_armv8-a_core_static")
+	if diff := static.MaybeOutput("libabi.a.abidiff"); diff.Rule != nil {
+		t.Errorf("expected no ABI diff for the static variant")
+	}
+
+	// Static libraries linked into the library are dumped too.
+	ctx.ModuleForTests("libabi_static", "android_arm64_armv8-a_core_static").Output("obj/bar.sdump")
+
+	// Libraries without header_abi_checker are not dumped.
+	if dump := ctx.ModuleForTests("libnoabi", "android_arm64_armv8-a_core_shared").MaybeOutput("libnoabi.so.lsdump"); dump.Rule != nil {
+		t.Errorf("expected no ABI dump for libnoabi")
+	}
+
+	phony := ctx.SingletonForTests("abi_references").Output("update-abi-references-libabi")
+	for _, expected := range []string{update.Output.String(), armUpdate.Output.String()} {
+		if !android.InList(expected, phony.Implicits.Strings()) {
+			t.Errorf("expected update-abi-references-libabi to depend on %q, got %q", expected, phony.Implicits)
+		}
+	}
+	ctx.SingletonForTests("abi_references").Output("update-abi-references")
+}
+
+func TestHeaderAbiCheckerImageVariants(t *testing.T) {
+	ctx := testCc(t, `
+		cc_library_shared {
+			name: "libabi",
+			srcs: ["foo.c"],
+			vendor_available: true,
+			header_abi_checker: {
+				enabled: true,
+				ref_dump_dir: "abi-dumps",
+			},
+		}
+
+		cc_library_shared {
+			name: "libabi_vendor",
+			srcs: ["foo.c"],
+			vendor: true,
+			header_abi_checker: {
+				enabled: true,
+				ref_dump_dir: "abi-dumps",
+			},
+		}
+	`)
+
+	// Only the core variant of a library with core and vendor variants uses the reference dumps, so
+	// that the two variants don't both write the same reference dump.
+	core := ctx.ModuleForTests("libabi", coreVariant)
+	core.Output("libabi.so.abidiff")
+	core.Output("libabi.so.ref_update.stamp")
+
+	vendor := ctx.ModuleForTests("libabi", vendorVariant)
+	if diff := vendor.MaybeOutput("libabi.so.abidiff"); diff.Rule != nil {
+		t.Errorf("expected no ABI diff for the vendor variant")
+	}
+	if update := vendor.MaybeOutput("libabi.so.ref_update.stamp"); update.Rule != nil {
+		t.Errorf("expected no reference dump update for the vendor variant")
+	}
+
+	// A vendor library only has a vendor variant, which uses the reference dumps.
+	ctx.ModuleForTests("libabi_vendor", vendorVariant).Output("libabi_vendor.so.ref_update.stamp")
+}
+
+func TestLinkerConfig(t *testing.T) {
+	config := android.TestArchConfig(buildDir, nil)
+	config.TestProductVariables.DeviceVndkVersion = StringPtr("current")
+	config.TestProductVariables.Platform_vndk_version = StringPtr("VER")
+	config.TestProductVariables.LinkerConfigExtraLinks = []string{"extra_links.json"}
+
+	ctx := testCcWithConfig(t, `
+		cc_library {
+			name: "libvndk",
+			vendor_available: true,
+			vndk: {
+				enabled: true,
+			},
+			nocrt: true,
+		}
+
+		cc_library {
+			name: "libvndk_private",
+			vendor_available: false,
+			vndk: {
+				enabled: true,
+			},
+			nocrt: true,
+		}
+
+		cc_library {
+			name: "libvndk_sp",
+			vendor_available: true,
+			vndk: {
+				enabled: true,
+				support_system_process: true,
+			},
+			nocrt: true,
+		}
+
+		linker_config_file {
+			name: "generated_ld.config.txt",
+			src: "etc/ld.config.txt",
+		}
+
+		linker_config_file {
+			name: "generated_vendor_public.libraries.txt",
+			src: "etc/public.libraries.txt",
+			vendor: true,
+		}
+	`, config)
+
+	linkerConfig := ctx.SingletonForTests("linker_config")
+
+	libraries := map[string][]string{
+		"linker_config/system/etc/llndk.libraries.txt":       {"libc.so"},
+		"linker_config/system/etc/vndkcore.libraries.txt":    {"libvndk.so", "libvndk_private.so"},
+		"linker_config/system/etc/vndksp.libraries.txt":      {"libvndk_sp.so"},
+		"linker_config/system/etc/vndkprivate.libraries.txt": {"libvndk_private.so"},
+	}
+	for output, libs := range libraries {
+		content := strings.Split(linkerConfig.Output(output).Args["content"], "\\n")
+		for _, lib := range libs {
+			if !inList(lib, content) {
+				t.Errorf("expected %s to list %q, got %q", output, lib, content)
+			}
+		}
+	}
+	linkerConfig.Output("linker_config/vendor/etc/public.libraries.txt")
+
+	metadata := linkerConfig.Output("linker_config/metadata.json")
+	for _, expected := range []string{`"vndk_version":"VER"`, `"libvndk_sp.so"`, `"libvndk.so"`} {
+		if content := metadata.Args["content"]; !strings.Contains(content, expected) {
+			t.Errorf("expected linker config metadata to contain %s, got %s", expected, content)
+		}
+	}
+
+	ldConfig := linkerConfig.Output("linker_config/system/etc/ld.config.txt")
+	for _, expected := range []string{metadata.Output.String(), "extra_links.json"} {
+		if !android.InList(expected, ldConfig.Implicits.Strings()) {
+			t.Errorf("expected ld.config.txt to be generated from %q, got %q", expected, ldConfig.Implicits)
+		}
+	}
+
+	phony := linkerConfig.Output("linker_config")
+	if !android.InList(ldConfig.Output.String(), phony.Implicits.Strings()) {
+		t.Errorf("expected linker_config to build %q, got %q", ldConfig.Output, phony.Implicits)
+	}
+
+	installs := map[string]string{
+		"generated_ld.config.txt":               "target/product/test_device/system/etc",
+		"generated_vendor_public.libraries.txt": "target/product/test_device/vendor/etc",
+	}
+	for name, installDir := range installs {
+		f := ctx.ModuleForTests(name, "android_arm64_armv8-a").Module().(*linkerConfigFile)
+		if g, w := f.installDir.RelPathString(), installDir; g != w {
+			t.Errorf("expected %s to be installed to %q, got %q", name, w, g)
+		}
+		if !android.InList(f.outputFile.String(), phony.Implicits.Strings()) {
+			t.Errorf("expected %s to install a generated file, got %q", name, f.outputFile)
+		}
+	}
+}
+
+func TestLinkerConfigFileNotGenerated(t *testing.T) {
+	testCcError(t, `src: "etc/vndksp.libraries.txt" is not a generated linker config file of the vendor partition`, `
+		linker_config_file {
+			name: "generated_vndksp.libraries.txt",
+			src: "etc/vndksp.libraries.txt",
+			vendor: true,
+		}
+	`)
+}
@@ -0,0 +1,268 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"android/soong/android"
+	"android/soong/cc/config"
+)
+
+func init() {
+	android.RegisterModuleType("cc_fuzz", FuzzFactory)
+	android.RegisterSingletonType("cc_fuzz_packaging", fuzzPackagingFactory)
+}
+
+type FuzzProperties struct {
+	// list of files or filegroup modules that provide the initial corpus for the fuzz target.  The
+	// files are packaged into a single corpus directory, so their names must be unique.
+	Corpus []string `android:"path"`
+
+	// libFuzzer dictionary for the fuzz target, must have a .dict extension.
+	Dictionary *string `android:"path"`
+
+	// libFuzzer options file for the fuzz target, must have a .options extension.
+	Options *string `android:"path"`
+}
+
+// cc_fuzz creates a native binary instrumented for coverage guided fuzzing with libFuzzer.  The
+// binary is built with the fuzzer sanitizer, which is propagated to its static dependencies, along
+// with the sanitizers that fuzzing needs to detect bugs.  It is installed into data/fuzz/<name>
+// alongside its corpus, dictionary and options files, and packaged with them into a zip that is
+// collected into out/soong/fuzz-<os>-<arch>.zip.
+func FuzzFactory() android.Module {
+	module := NewFuzz(android.HostAndDeviceSupported)
+	return module.Init()
+}
+
+type fuzzBinary struct {
+	*binaryDecorator
+	Properties FuzzProperties
+
+	corpus     android.Paths
+	dictionary android.OptionalPath
+	options    android.OptionalPath
+
+	// The directory the fuzz target and its files are staged into, and the staged files other
+	// than the binary relative to it.
+	packageDir   android.OutputPath
+	packageFiles []string
+
+	// The per-target zip, collected by the cc_fuzz_packaging singleton.
+	packagedZip android.OptionalPath
+}
+
+func (fuzz *fuzzBinary) linkerInit(ctx BaseModuleContext) {
+	// The fuzz target is installed into its own directory, find the shared libraries in the
+	// regular library directories.
+	runpath := "../../lib"
+	if ctx.toolchain().Is64Bit() {
+		runpath += "64"
+	}
+	fuzz.baseLinker.dynamicProperties.RunPaths = append(fuzz.baseLinker.dynamicProperties.RunPaths, runpath)
+	fuzz.binaryDecorator.linkerInit(ctx)
+}
+
+func (fuzz *fuzzBinary) linkerProps() []interface{} {
+	props := fuzz.binaryDecorator.linkerProps()
+	props = append(props, &fuzz.Properties)
+	return props
+}
+
+func (fuzz *fuzzBinary) linkerDeps(ctx DepsContext, deps Deps) Deps {
+	deps = fuzz.binaryDecorator.linkerDeps(ctx, deps)
+	if runtime := config.LibFuzzerRuntimeLibrary(ctx.toolchain()); runtime != "" {
+		deps.StaticLibs = append(deps.StaticLibs, runtime)
+	}
+	return deps
+}
+
+func (fuzz *fuzzBinary) linkerFlags(ctx ModuleContext, flags Flags) Flags {
+	flags = fuzz.binaryDecorator.linkerFlags(ctx, flags)
+	if config.LibFuzzerRuntimeLibrary(ctx.toolchain()) == "" {
+		// There is no prebuilt runtime for the host, let the compiler link its own.
+		flags.LdFlags = append(flags.LdFlags, "-fsanitize=fuzzer")
+	}
+	return flags
+}
+
+func (fuzz *fuzzBinary) install(ctx ModuleContext, file android.Path) {
+	fuzz.corpus = ctx.ExpandSources(fuzz.Properties.Corpus, nil)
+	fuzz.dictionary = ctx.ExpandOptionalSource(fuzz.Properties.Dictionary, "dictionary")
+	if fuzz.dictionary.Valid() && fuzz.dictionary.Path().Ext() != ".dict" {
+		ctx.PropertyErrorf("dictionary", "fuzzer dictionary %q does not have a .dict extension",
+			fuzz.dictionary.Path().String())
+	}
+	fuzz.options = ctx.ExpandOptionalSource(fuzz.Properties.Options, "options")
+	if fuzz.options.Valid() && fuzz.options.Path().Ext() != ".options" {
+		ctx.PropertyErrorf("options", "fuzzer options %q does not have a .options extension",
+			fuzz.options.Path().String())
+	}
+
+	fuzz.binaryDecorator.baseInstaller.dir = filepath.Join("fuzz", ctx.ModuleName())
+	fuzz.binaryDecorator.baseInstaller.dir64 = filepath.Join("fuzz", ctx.ModuleName())
+	fuzz.binaryDecorator.install(ctx, file)
+
+	fuzz.packageFuzzTarget(ctx, file)
+}
+
+// packageFuzzTarget stages the fuzz target and its corpus, dictionary and options files into the
+// layout expected by fuzzing infrastructure and zips them under a directory named after the module.
+func (fuzz *fuzzBinary) packageFuzzTarget(ctx ModuleContext, binary android.Path) {
+	name := ctx.ModuleName()
+	fuzz.packageDir = android.PathForModuleOut(ctx, "fuzz_package")
+	packagedZip := android.PathForModuleOut(ctx, name+".zip")
+	fuzz.packageFiles = nil
+
+	rule := android.NewRuleBuilder()
+
+	rule.Command().Text("rm -rf").Text(fuzz.packageDir.String())
+	rule.Command().Text("mkdir -p").Text(fuzz.packageDir.Join(ctx, "corpus").String())
+
+	stage := func(from android.Path, rel string) {
+		rule.Command().Text("cp -f").Input(from).Output(fuzz.packageDir.Join(ctx, rel))
+	}
+
+	stage(binary, binary.Base())
+	// The corpus is a flat directory in the package, as expected by fuzzing infrastructure.
+	corpusFiles := make(map[string]android.Path)
+	for _, f := range fuzz.corpus {
+		if other, exists := corpusFiles[f.Base()]; exists {
+			ctx.PropertyErrorf("corpus", "corpus files %q and %q have the same name %q",
+				other.String(), f.String(), f.Base())
+			continue
+		}
+		corpusFiles[f.Base()] = f
+		rel := filepath.Join("corpus", f.Base())
+		stage(f, rel)
+		fuzz.packageFiles = append(fuzz.packageFiles, rel)
+	}
+	if fuzz.dictionary.Valid() {
+		stage(fuzz.dictionary.Path(), name+".dict")
+		fuzz.packageFiles = append(fuzz.packageFiles, name+".dict")
+	}
+	if fuzz.options.Valid() {
+		stage(fuzz.options.Path(), name+".options")
+		fuzz.packageFiles = append(fuzz.packageFiles, name+".options")
+	}
+
+	rule.Command().
+		Tool(ctx.Config().HostToolPath(ctx, "soong_zip")).
+		FlagWithOutput("-o ", packagedZip).
+		FlagWithArg("-P ", name).
+		FlagWithArg("-C ", fuzz.packageDir.String()).
+		FlagWithArg("-D ", fuzz.packageDir.String())
+
+	rule.Build(pctx, ctx, "fuzz_package", "package fuzz target "+name)
+
+	fuzz.packagedZip = android.OptionalPathForPath(packagedZip)
+}
+
+func NewFuzz(hod android.HostOrDeviceSupported) *Module {
+	module, binary := NewBinary(hod)
+	binary.baseInstaller = NewBaseInstaller("fuzz", "fuzz", InstallInData)
+
+	fuzz := &fuzzBinary{
+		binaryDecorator: binary,
+	}
+	module.linker = fuzz
+	module.installer = fuzz
+
+	module.sanitize.Properties.Sanitize.Fuzzer = BoolPtr(true)
+
+	// There is no libFuzzer runtime for Darwin or host Bionic, disable cc_fuzz modules there.
+	android.AddLoadHook(module, func(ctx android.LoadHookContext) {
+		disableDarwinAndLinuxBionic := struct {
+			Target struct {
+				Darwin struct {
+					Enabled *bool
+				}
+				Linux_bionic struct {
+					Enabled *bool
+				}
+			}
+		}{}
+		disableDarwinAndLinuxBionic.Target.Darwin.Enabled = BoolPtr(false)
+		disableDarwinAndLinuxBionic.Target.Linux_bionic.Enabled = BoolPtr(false)
+		ctx.AppendProperties(&disableDarwinAndLinuxBionic)
+	})
+
+	return module
+}
+
+func fuzzPackagingFactory() android.Singleton {
+	return &fuzzPackager{}
+}
+
+// fuzzPackager merges the zips of all fuzz targets for each os and architecture into
+// out/soong/fuzz-<os>-<arch>.zip, and makes them buildable with "m fuzz-packages".
+type fuzzPackager struct {
+	packages android.Paths
+}
+
+func (s *fuzzPackager) GenerateBuildActions(ctx android.SingletonContext) {
+	archZips := make(map[string]android.Paths)
+
+	ctx.VisitAllModules(func(module android.Module) {
+		m, ok := module.(*Module)
+		if !ok || !m.Enabled() || m.Properties.PreventInstall {
+			return
+		}
+		fuzz, ok := m.linker.(*fuzzBinary)
+		if !ok || !fuzz.packagedZip.Valid() {
+			return
+		}
+		key := m.Target().Os.Name + "-" + m.Target().Arch.ArchType.String()
+		archZips[key] = append(archZips[key], fuzz.packagedZip.Path())
+	})
+
+	var keys []string
+	for key := range archZips {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	s.packages = nil
+	for _, key := range keys {
+		zips := archZips[key]
+		sort.Slice(zips, func(i, j int) bool { return zips[i].String() < zips[j].String() })
+
+		output := android.PathForOutput(ctx, "fuzz-"+key+".zip")
+		rule := android.NewRuleBuilder()
+		rule.Command().
+			Tool(ctx.Config().HostToolPath(ctx, "merge_zips")).
+			Flag("-s").
+			Output(output).
+			Inputs(zips)
+		rule.Build(pctx, ctx, "fuzz_packaging_"+key, "merge fuzz targets for "+key)
+
+		s.packages = append(s.packages, output)
+	}
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:      blueprint.Phony,
+		Output:    android.PathForPhony(ctx, "fuzz-packages"),
+		Implicits: s.packages,
+	})
+}
+
+func (s *fuzzPackager) MakeVars(ctx android.MakeVarsContext) {
+	ctx.Strict("SOONG_FUZZ_PACKAGING_ZIPS", strings.Join(s.packages.Strings(), " "))
+}
@@ -0,0 +1,103 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const fuzzRuntimeBp = `
+	toolchain_library {
+		name: "libclang_rt.fuzzer-aarch64-android",
+		vendor_available: true,
+		recovery_available: true,
+		src: "",
+	}
+`
+
+func TestFuzz(t *testing.T) {
+	ctx := testCc(t, fuzzRuntimeBp+`
+		cc_fuzz {
+			name: "fuzz_target",
+			srcs: ["foo.c"],
+			corpus: ["corpus/*"],
+			dictionary: "fuzz.dict",
+			options: "fuzz.options",
+		}`)
+
+	variant := "android_arm64_armv8-a_core"
+	module := ctx.ModuleForTests("fuzz_target", variant)
+	fuzz := module.Module().(*Module)
+
+	if !Bool(fuzz.sanitize.Properties.Sanitize.Fuzzer) {
+		t.Errorf("fuzzer sanitizer is not enabled")
+	}
+	if !Bool(fuzz.sanitize.Properties.Sanitize.Address) {
+		t.Errorf("address sanitizer is not enabled by default for fuzz targets")
+	}
+
+	libFlags := module.Rule("ld").Args["libFlags"]
+	if !strings.Contains(libFlags, "libclang_rt.fuzzer-aarch64-android") {
+		t.Errorf("fuzz target is not linked against the libFuzzer runtime, libFlags %q", libFlags)
+	}
+
+	binary := fuzz.linker.(*fuzzBinary)
+	wantFiles := []string{"corpus/seed1", "corpus/seed2", "fuzz_target.dict", "fuzz_target.options"}
+	if !reflect.DeepEqual(binary.packageFiles, wantFiles) {
+		t.Errorf("want packaged files %q, got %q", wantFiles, binary.packageFiles)
+	}
+
+	zip := module.Output("fuzz_target.zip")
+	for _, file := range append([]string{"fuzz_target"}, wantFiles...) {
+		staged := binary.packageDir.Join(nil, file).String()
+		if !inList(staged, zip.Outputs.Strings()) {
+			t.Errorf("fuzz_target.zip rule does not stage %q", file)
+		}
+	}
+	for _, file := range []string{"corpus/seed1", "corpus/seed2", "fuzz.dict", "fuzz.options"} {
+		if !inList(file, zip.Implicits.Strings()) {
+			t.Errorf("fuzz_target.zip rule does not depend on %q", file)
+		}
+	}
+
+	packagedZip := binary.packagedZip.String()
+	packaging := ctx.SingletonForTests("cc_fuzz_packaging").Output("fuzz-android-arm64.zip")
+	if !inList(packagedZip, packaging.Implicits.Strings()) {
+		t.Errorf("fuzz-android-arm64.zip inputs %q do not contain %q", packaging.Implicits.Strings(),
+			packagedZip)
+	}
+}
+
+func TestFuzzDictionaryExtension(t *testing.T) {
+	testCcError(t, `dictionary: fuzzer dictionary ".*fuzz.options" does not have a .dict extension`,
+		fuzzRuntimeBp+`
+		cc_fuzz {
+			name: "fuzz_target",
+			srcs: ["foo.c"],
+			dictionary: "fuzz.options",
+		}`)
+}
+
+func TestFuzzCorpusNameCollision(t *testing.T) {
+	testCcError(t, `corpus: corpus files "corpus/seed1" and "seeds/seed1" have the same name "seed1"`,
+		fuzzRuntimeBp+`
+		cc_fuzz {
+			name: "fuzz_target",
+			srcs: ["foo.c"],
+			corpus: ["corpus/*", "seeds/*"],
+		}`)
+}
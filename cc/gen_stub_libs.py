@@ -426,6 +426,27 @@ class Generator(object):
                 self.version_script.write('}' + base + ';\n')
 
 
+def get_omitted_symbols(versions, arch, api, vndk, apex):
+    """Returns the sorted names of the symbols omitted from the stub library.
+
+    These are the symbols of the library for the architecture that a module
+    linking against the stub library must not use, such as private symbols or
+    symbols introduced after the API level being targeted.
+    """
+    omitted = set()
+    for version in versions:
+        omit_version = should_omit_version(version, arch, api, vndk, apex)
+        for symbol in version.symbols:
+            if not symbol_in_arch(version.tags, arch):
+                continue
+            if not symbol_in_arch(symbol.tags, arch):
+                continue
+            if omit_version or should_omit_symbol(symbol, arch, api, vndk,
+                                                  apex):
+                omitted.add(symbol.name)
+    return sorted(omitted)
+
+
 def decode_api_level(api, api_map):
     """Decodes the API level argument into the API level number.
 
@@ -464,6 +485,10 @@ def parse_args():
         '--api-map', type=os.path.realpath, required=True,
         help='Path to the API level map JSON file.')
 
+    parser.add_argument(
+        '--omitted-symbol-list', type=os.path.realpath,
+        help='Path to output the list of symbols omitted from the stubs to.')
+
     parser.add_argument(
         'symbol_file', type=os.path.realpath, help='Path to symbol file.')
     parser.add_argument(
@@ -503,6 +528,12 @@ def main():
                                   args.vndk, args.apex)
             generator.write(versions)
 
+    if args.omitted_symbol_list is not None:
+        with open(args.omitted_symbol_list, 'w') as omitted_file:
+            for symbol in get_omitted_symbols(versions, args.arch, api,
+                                              args.vndk, args.apex):
+                omitted_file.write(symbol + '\n')
+
 
 if __name__ == '__main__':
     main()
@@ -15,6 +15,8 @@
 package cc
 
 import (
+	"fmt"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -95,6 +97,17 @@ type LibraryProperties struct {
 
 	// Properties for ABI compatibility checker
 	Header_abi_checker struct {
+		// Enable ABI checks, even if this is not an LLNDK, NDK or VNDK library
+		Enabled *bool
+
+		// Path to the directory containing the reference ABI dumps of this library, relative to the
+		// module directory.  The reference dump for each architecture is read from
+		// <ref_dump_dir>/<arch>_<arch_variant>/<library>.so.lsdump or a gzipped .lsdump.gz, and is
+		// updated by "m update-abi-references-<library>".  Only the core variant is checked if the
+		// library also has vendor or recovery variants.  Defaults to the reference dumps in
+		// prebuilts/abi-dumps.
+		Ref_dump_dir *string
+
 		// Path to a symbol file that specifies the symbols to be included in the generated
 		// ABI dump file
 		Symbol_file *string `android:"path"`
@@ -199,6 +212,9 @@ type flagExporter struct {
 
 	flags     []string
 	flagsDeps android.Paths
+
+	// the source directories in Export_include_dirs, used to package the exported headers
+	includeDirs android.Paths
 }
 
 func (f *flagExporter) exportedIncludes(ctx ModuleContext) android.Paths {
@@ -214,6 +230,7 @@ func (f *flagExporter) exportIncludes(ctx ModuleContext, inc string) {
 	for _, dir := range includeDirs.Strings() {
 		f.flags = append(f.flags, inc+dir)
 	}
+	f.includeDirs = append(f.includeDirs, includeDirs...)
 }
 
 func (f *flagExporter) reexportFlags(flags []string) {
@@ -239,6 +256,16 @@ type exportedFlagsProducer interface {
 
 var _ exportedFlagsProducer = (*flagExporter)(nil)
 
+func (f *flagExporter) exportedIncludeDirs() android.Paths {
+	return f.includeDirs
+}
+
+type exportedIncludeDirsProducer interface {
+	exportedIncludeDirs() android.Paths
+}
+
+var _ exportedIncludeDirsProducer = (*flagExporter)(nil)
+
 // libraryDecorator wraps baseCompiler, baseLinker and baseInstaller to provide library-specific
 // functionality: static vs. shared linkage, reusing object files for shared libraries
 type libraryDecorator struct {
@@ -278,6 +305,10 @@ type libraryDecorator struct {
 	// Source Abi Diff
 	sAbiDiff android.OptionalPath
 
+	// Stamp of the rule that copies the linked Source Abi Dump over the reference dump in
+	// header_abi_checker.ref_dump_dir
+	sAbiRefUpdate android.OptionalPath
+
 	// Location of the static library in the sysroot. Empty if the library is
 	// not included in the NDK.
 	ndkSysrootPath android.Path
@@ -290,6 +321,10 @@ type libraryDecorator struct {
 
 	versionScriptPath android.ModuleGenPath
 
+	// For stubs variants, the list of the symbols of the library that are not in this version of the
+	// stubs, which modules linking against the stubs must not use
+	omittedSymbolsPath android.Path
+
 	// Decorated interafaces
 	*baseCompiler
 	*baseLinker
@@ -411,6 +446,7 @@ func (library *libraryDecorator) compile(ctx ModuleContext, flags Flags, deps Pa
 	if library.buildStubs() {
 		objs, versionScript := compileStubLibrary(ctx, flags, String(library.Properties.Stubs.Symbol_file), library.MutatedProperties.StubsVersion, "--apex")
 		library.versionScriptPath = versionScript
+		library.omittedSymbolsPath = stubOmittedSymbolsPath(ctx)
 		return objs
 	}
 
@@ -426,7 +462,7 @@ func (library *libraryDecorator) compile(ctx ModuleContext, flags Flags, deps Pa
 		}
 		return Objects{}
 	}
-	if ctx.shouldCreateVndkSourceAbiDump() || library.sabi.Properties.CreateSAbiDumps {
+	if library.shouldCreateSourceAbiDump(ctx) || library.sabi.Properties.CreateSAbiDumps {
 		exportIncludeDirs := library.flagExporter.exportedIncludes(ctx)
 		var SourceAbiFlags []string
 		for _, dir := range exportIncludeDirs.Strings() {
@@ -745,6 +781,32 @@ func getRefAbiDumpFile(ctx ModuleContext, vndkVersion, fileName string) android.
 	refAbiDumpTextFile := android.PathForVndkRefAbiDump(ctx, vndkVersion, fileName, isLlndk, false)
 	refAbiDumpGzipFile := android.PathForVndkRefAbiDump(ctx, vndkVersion, fileName, isLlndk, true)
 
+	return selectRefAbiDumpFile(ctx, refAbiDumpTextFile, refAbiDumpGzipFile, fileName)
+}
+
+// refAbiDumpDir returns the directory in header_abi_checker.ref_dump_dir that contains the reference
+// ABI dumps of the current architecture.
+func (library *libraryDecorator) refAbiDumpDir(ctx ModuleContext) string {
+	arch := ctx.Arch()
+	archNameAndVariant := arch.ArchType.String()
+	if arch.ArchVariant != "" {
+		archNameAndVariant += "_" + arch.ArchVariant
+	}
+	return filepath.Join(ctx.ModuleDir(), String(library.Properties.Header_abi_checker.Ref_dump_dir),
+		archNameAndVariant)
+}
+
+// isRefAbiDumpImage returns true if this is the image variant that is checked against the reference
+// ABI dumps in header_abi_checker.ref_dump_dir: the core variant, or the vendor or recovery variant of
+// a library that has no core variant.
+func isRefAbiDumpImage(ctx ModuleContext) bool {
+	hasCoreVariant := !ctx.SocSpecific() && !ctx.DeviceSpecific() && !ctx.InstallInRecovery()
+	return !hasCoreVariant || (!ctx.useVndk() && !ctx.inRecovery())
+}
+
+func selectRefAbiDumpFile(ctx ModuleContext, refAbiDumpTextFile, refAbiDumpGzipFile android.OptionalPath,
+	fileName string) android.Path {
+
 	if refAbiDumpTextFile.Valid() {
 		if refAbiDumpGzipFile.Valid() {
 			ctx.ModuleErrorf(
@@ -760,8 +822,21 @@ func getRefAbiDumpFile(ctx ModuleContext, vndkVersion, fileName string) android.
 	return nil
 }
 
+func (library *libraryDecorator) headerAbiCheckerEnabled() bool {
+	return Bool(library.Properties.Header_abi_checker.Enabled)
+}
+
+// shouldCreateSourceAbiDump returns true if a linked ABI dump should be created and checked for this
+// library, either because it is an NDK, LLNDK or VNDK library or because its ABI checks are enabled.
+func (library *libraryDecorator) shouldCreateSourceAbiDump(ctx ModuleContext) bool {
+	if ctx.shouldCreateVndkSourceAbiDump() {
+		return true
+	}
+	return library.headerAbiCheckerEnabled() && ctx.canCreateSourceAbiDump()
+}
+
 func (library *libraryDecorator) linkSAbiDumpFiles(ctx ModuleContext, objs Objects, fileName string, soFile android.Path) {
-	if len(objs.sAbiDumpFiles) > 0 && ctx.shouldCreateVndkSourceAbiDump() {
+	if len(objs.sAbiDumpFiles) > 0 && library.shouldCreateSourceAbiDump(ctx) {
 		vndkVersion := ctx.DeviceConfig().PlatformVndkVersion()
 		if ver := ctx.DeviceConfig().VndkVersion(); ver != "" && ver != "current" {
 			vndkVersion = ver
@@ -781,10 +856,36 @@ func (library *libraryDecorator) linkSAbiDumpFiles(ctx ModuleContext, objs Objec
 			library.Properties.Header_abi_checker.Exclude_symbol_versions,
 			library.Properties.Header_abi_checker.Exclude_symbol_tags)
 
+		if library.Properties.Header_abi_checker.Ref_dump_dir != nil {
+			// The reference dumps in ref_dump_dir are per library, so only the shared variant of a
+			// single image is checked against them and allowed to update them.
+			if !library.shared() || !isRefAbiDumpImage(ctx) {
+				return
+			}
+			refAbiDumpDir := library.refAbiDumpDir(ctx)
+			refAbiDumpTextFile := android.ExistentPathForSource(ctx, refAbiDumpDir, fileName+".lsdump")
+			refAbiDumpGzipFile := android.ExistentPathForSource(ctx, refAbiDumpDir, fileName+".lsdump.gz")
+
+			updateTarget := "update-abi-references-" + ctx.baseModuleName()
+			refAbiDumpFile := selectRefAbiDumpFile(ctx, refAbiDumpTextFile, refAbiDumpGzipFile, fileName)
+			if refAbiDumpFile != nil {
+				library.sAbiDiff = SourceAbiDiff(ctx, library.sAbiOutputFile.Path(),
+					refAbiDumpFile, fileName, exportedHeaderFlags, ctx.isLlndk(), ctx.isVndkExt(), updateTarget)
+			}
+
+			// Keep the reference dump gzipped if it already is.
+			var updatedRefAbiDumpFile android.Path = android.PathForSource(ctx, refAbiDumpDir, fileName+".lsdump")
+			if refAbiDumpGzipFile.Valid() {
+				updatedRefAbiDumpFile = refAbiDumpGzipFile.Path()
+			}
+			library.sAbiRefUpdate = UpdateRefDump(ctx, library.sAbiOutputFile.Path(), updatedRefAbiDumpFile, fileName)
+			return
+		}
+
 		refAbiDumpFile := getRefAbiDumpFile(ctx, vndkVersion, fileName)
 		if refAbiDumpFile != nil {
 			library.sAbiDiff = SourceAbiDiff(ctx, library.sAbiOutputFile.Path(),
-				refAbiDumpFile, fileName, exportedHeaderFlags, ctx.isLlndk(), ctx.isVndkExt())
+				refAbiDumpFile, fileName, exportedHeaderFlags, ctx.isLlndk(), ctx.isVndkExt(), "")
 		}
 	}
 }
@@ -981,6 +1082,10 @@ func (library *libraryDecorator) stubsVersion() string {
 	return library.MutatedProperties.StubsVersion
 }
 
+func (library *libraryDecorator) stubOmittedSymbols() android.Path {
+	return library.omittedSymbolsPath
+}
+
 var versioningMacroNamesListKey = android.NewOnceKey("versioningMacroNamesList")
 
 func versioningMacroNamesList(config android.Config) *map[string]string {
@@ -1088,13 +1193,31 @@ func stubsVersionsFor(config android.Config) map[string][]string {
 
 var stubsVersionsLock sync.Mutex
 
-func latestStubsVersionFor(config android.Config, name string) string {
-	versions, ok := stubsVersionsFor(config)[name]
-	if ok && len(versions) > 0 {
-		// the versions are alreay sorted in ascending order
-		return versions[len(versions)-1]
+// stubsVersionFor returns the version of the stubs of the library to link against for a module with
+// the given min_sdk_version: the highest version that is not higher than min_sdk_version, so that
+// the module only uses the symbols available on all the releases it supports.  The latest version
+// is used when min_sdk_version is empty or "current".  It returns "" if the library has no stubs.
+func stubsVersionFor(config android.Config, name string, minSdkVersion string) (string, error) {
+	// the versions are already sorted in ascending order
+	versions := stubsVersionsFor(config)[name]
+	if len(versions) == 0 {
+		return "", nil
+	}
+	if minSdkVersion == "" || minSdkVersion == "current" {
+		return versions[len(versions)-1], nil
+	}
+
+	minSdk, err := strconv.Atoi(minSdkVersion)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a number or \"current\"", minSdkVersion)
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if v, _ := strconv.Atoi(versions[i]); v <= minSdk {
+			return versions[i], nil
+		}
 	}
-	return ""
+	return "", fmt.Errorf("%q has no stubs version compatible with min_sdk_version %s, the lowest is %s",
+		name, minSdkVersion, versions[0])
 }
 
 // Version mutator splits a module into the mandatory non-stubs variant
@@ -0,0 +1,275 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"android/soong/android"
+)
+
+func init() {
+	android.RegisterModuleType("linker_config_file", LinkerConfigFileFactory)
+	android.RegisterSingletonType("linker_config", linkerConfigSingletonFactory)
+}
+
+// LinkerNamespaceModule is implemented by modules, like APEXes, whose native libraries are loaded
+// in a linker namespace of their own.
+type LinkerNamespaceModule interface {
+	android.Module
+
+	// LinkerNamespace returns the name of the namespace, and the file names of the shared libraries
+	// that it provides to the platform and that it requires from the platform.  The name is empty if
+	// the module has no namespace.
+	LinkerNamespace() (name string, provideLibs, requireLibs []string)
+}
+
+// linkerConfigMetadata describes the native libraries of the device that determine its linker
+// namespaces.  It is written as JSON to linker_config/metadata.json in the output directory, and
+// turned into ld.config.txt files by gen_linker_config.py.
+type linkerConfigMetadata struct {
+	// The version of the VNDK installed in the system partition, or "current".
+	VndkVersion string `json:"vndk_version"`
+
+	// The file names of the LLNDK, VNDK-SP and VNDK-core libraries.
+	Llndk    []string `json:"llndk"`
+	VndkSp   []string `json:"vndk_sp"`
+	VndkCore []string `json:"vndk_core"`
+
+	Apexes []linkerConfigApex `json:"apexes"`
+}
+
+type linkerConfigApex struct {
+	Name        string   `json:"name"`
+	ProvideLibs []string `json:"provide_libs"`
+	RequireLibs []string `json:"require_libs"`
+}
+
+func linkerConfigSingletonFactory() android.Singleton {
+	return &linkerConfigSingleton{}
+}
+
+// linkerConfigSingleton generates the linker configuration files of the device from the LLNDK,
+// VNDK, NDK and vendor public libraries and the APEXes in the module graph, so that they don't
+// have to be maintained by hand.  It writes system/etc/ld.config.txt, with the namespaces of the
+// binaries of the system and vendor partitions extended with the links in the JSON files listed in
+// LinkerConfigExtraLinks, system/apex/<apex>/etc/ld.config.txt with the namespaces of the binaries
+// of each APEX, the system/etc/{llndk,vndksp,vndkcore,vndkprivate}.libraries.txt lists, and the
+// public.libraries.txt lists of the NDK libraries in system/etc and of the vendor public libraries
+// in vendor/etc.  The files are written to out/soong/linker_config/<partition>, built by
+// "m linker_config", and installed by linker_config_file modules.
+type linkerConfigSingleton struct{}
+
+func (s *linkerConfigSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	if len(ctx.Config().Targets[android.Android]) == 0 {
+		return
+	}
+
+	apexes := make(map[string]linkerConfigApex)
+	ctx.VisitAllModules(func(m android.Module) {
+		if !m.Enabled() {
+			return
+		}
+		if n, ok := m.(LinkerNamespaceModule); ok {
+			if name, provideLibs, requireLibs := n.LinkerNamespace(); name != "" {
+				apexes[name] = linkerConfigApex{
+					Name:        name,
+					ProvideLibs: provideLibs,
+					RequireLibs: requireLibs,
+				}
+			}
+		}
+	})
+
+	vndkVersion := ctx.DeviceConfig().PlatformVndkVersion()
+	if ver := ctx.DeviceConfig().VndkVersion(); ver != "" && ver != "current" {
+		vndkVersion = ver
+	}
+
+	metadata := linkerConfigMetadata{
+		VndkVersion: vndkVersion,
+		Llndk:       sharedLibFileNames(llndkLibraries),
+		VndkSp:      sharedLibFileNames(vndkSpLibraries),
+		VndkCore:    sharedLibFileNames(vndkCoreLibraries),
+	}
+	var apexNames []string
+	for name := range apexes {
+		apexNames = append(apexNames, name)
+	}
+	sort.Strings(apexNames)
+	for _, name := range apexNames {
+		metadata.Apexes = append(metadata.Apexes, apexes[name])
+	}
+
+	var outputs android.Paths
+
+	writeLibraries := func(partition, name string, libs []string) {
+		output := android.PathForOutput(ctx, "linker_config", partition, "etc", name)
+		ctx.Build(pctx, android.BuildParams{
+			Rule:        android.WriteFile,
+			Description: "linker config " + partition + "/etc/" + name,
+			Output:      output,
+			Args: map[string]string{
+				"content": android.WriteFileContent(strings.Join(sharedLibFileNames(libs), "\n")),
+			},
+		})
+		outputs = append(outputs, output)
+	}
+
+	publicLibraries := android.FirstUniqueStrings(append(append([]string(nil), ndkPrebuiltSharedLibraries...),
+		ndkMigratedLibs...))
+	sort.Strings(publicLibraries)
+	vendorPublic := android.FirstUniqueStrings(vendorPublicLibraries)
+	sort.Strings(vendorPublic)
+
+	writeLibraries("system", "llndk.libraries.txt", llndkLibraries)
+	writeLibraries("system", "vndksp.libraries.txt", vndkSpLibraries)
+	writeLibraries("system", "vndkcore.libraries.txt", vndkCoreLibraries)
+	writeLibraries("system", "vndkprivate.libraries.txt", vndkPrivateLibraries)
+	writeLibraries("system", "public.libraries.txt", publicLibraries)
+	writeLibraries("vendor", "public.libraries.txt", vendorPublic)
+
+	metadataFile := android.PathForOutput(ctx, "linker_config", "metadata.json")
+	content, err := json.Marshal(metadata)
+	if err != nil {
+		ctx.Errorf("failed to write linker config metadata: %s", err)
+		return
+	}
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        android.WriteFile,
+		Description: "linker config metadata",
+		Output:      metadataFile,
+		Args: map[string]string{
+			"content": android.WriteFileContent(string(content)),
+		},
+	})
+
+	ldConfig := android.PathForOutput(ctx, "linker_config", "system", "etc", "ld.config.txt")
+
+	rule := android.NewRuleBuilder()
+	cmd := rule.Command().
+		Tool(android.PathForSource(ctx, "build/soong/scripts/gen_linker_config.py")).
+		FlagWithInput("--metadata ", metadataFile).
+		FlagWithOutput("--system_out ", ldConfig)
+	for _, extraLinks := range ctx.DeviceConfig().LinkerConfigExtraLinks() {
+		cmd.FlagWithInput("--extra_links ", android.PathForSource(ctx, extraLinks))
+	}
+	for _, name := range apexNames {
+		apexLdConfig := android.PathForOutput(ctx, "linker_config", "system", "apex", name, "etc", "ld.config.txt")
+		cmd.FlagWithArg("--apex_out ", name+"="+apexLdConfig.String()).ImplicitOutput(apexLdConfig)
+		outputs = append(outputs, apexLdConfig)
+	}
+	rule.Build(pctx, ctx, "linker_config", "generate linker config")
+	outputs = append(outputs, ldConfig)
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:      blueprint.Phony,
+		Output:    android.PathForPhony(ctx, "linker_config"),
+		Implicits: outputs,
+	})
+
+	// Make sure that every linker_config_file module installs a file that is generated here.
+	ctx.VisitAllModules(func(m android.Module) {
+		if f, ok := m.(*linkerConfigFile); ok && f.outputFile != nil {
+			if !android.InList(f.outputFile.String(), outputs.Strings()) {
+				ctx.ModuleErrorf(m, "src: %q is not a generated linker config file of the %s partition",
+					String(f.properties.Src), f.partition)
+			}
+		}
+	})
+}
+
+type linkerConfigFileProperties struct {
+	// The generated linker config file to install, relative to the root of the partition of the
+	// module, for example "etc/ld.config.txt", "apex/com.android.runtime/etc/ld.config.txt", or
+	// "etc/public.libraries.txt" in a module with vendor: true.
+	Src *string
+}
+
+type linkerConfigFile struct {
+	android.ModuleBase
+
+	properties linkerConfigFileProperties
+
+	partition  string
+	outputFile android.Path
+	installDir android.OutputPath
+}
+
+func (f *linkerConfigFile) DepsMutator(ctx android.BottomUpMutatorContext) {
+	if f.properties.Src == nil {
+		ctx.PropertyErrorf("src", "missing generated linker config file")
+	}
+}
+
+func (f *linkerConfigFile) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	switch {
+	case ctx.SocSpecific():
+		f.partition = "vendor"
+	case ctx.DeviceSpecific(), ctx.ProductSpecific(), ctx.ProductServicesSpecific():
+		ctx.ModuleErrorf("linker config files are only generated for the system and vendor partitions")
+		return
+	default:
+		f.partition = "system"
+	}
+
+	src := filepath.Clean(String(f.properties.Src))
+	if filepath.IsAbs(src) || src == ".." || strings.HasPrefix(src, "../") {
+		ctx.PropertyErrorf("src", "%q must be a path within the partition", String(f.properties.Src))
+		return
+	}
+
+	f.outputFile = android.PathForOutput(ctx, "linker_config", f.partition, src)
+	f.installDir = android.PathForModuleInstall(ctx, filepath.Dir(src))
+}
+
+func (f *linkerConfigFile) AndroidMk() android.AndroidMkData {
+	return android.AndroidMkData{
+		Class:      "ETC",
+		OutputFile: android.OptionalPathForPath(f.outputFile),
+		Extra: []android.AndroidMkExtraFunc{
+			func(w io.Writer, outputFile android.Path) {
+				fmt.Fprintln(w, "LOCAL_MODULE_PATH :=", "$(OUT_DIR)/"+f.installDir.RelPathString())
+				fmt.Fprintln(w, "LOCAL_INSTALLED_MODULE_STEM :=", outputFile.Base())
+			},
+		},
+	}
+}
+
+// linker_config_file installs a linker config file that is generated from the module graph, like
+// ld.config.txt or llndk.libraries.txt, in place of a hand-maintained prebuilt_etc module.  The
+// file is installed to the same path in the partition of the module as src.
+func LinkerConfigFileFactory() android.Module {
+	module := &linkerConfigFile{}
+	module.AddProperties(&module.properties)
+	android.InitAndroidArchModule(module, android.DeviceSupported, android.MultilibFirst)
+	return module
+}
+
+// sharedLibFileNames returns the file names of the shared libraries with the given module names.
+func sharedLibFileNames(names []string) []string {
+	ret := make([]string, len(names))
+	for i, name := range names {
+		ret[i] = name + ".so"
+	}
+	return ret
+}
@@ -31,9 +31,9 @@ var (
 	genStubSrc = pctx.AndroidStaticRule("genStubSrc",
 		blueprint.RuleParams{
 			Command: "$toolPath --arch $arch --api $apiLevel --api-map " +
-				"$apiMap $flags $in $out",
+				"$apiMap --omitted-symbol-list $omittedSymbols $flags $in $out",
 			CommandDeps: []string{"$toolPath"},
-		}, "arch", "apiLevel", "apiMap", "flags")
+		}, "arch", "apiLevel", "apiMap", "flags", "omittedSymbols")
 
 	ndkLibrarySuffix = ".ndk"
 
@@ -272,24 +272,33 @@ func (stub *stubDecorator) compilerFlags(ctx ModuleContext, flags Flags, deps Pa
 	return addStubLibraryCompilerFlags(flags)
 }
 
+// stubOmittedSymbolsPath returns the path of the list of the symbols in the symbol file that
+// compileStubLibrary omits from the stub library, e.g. symbols introduced after its API level.
+func stubOmittedSymbolsPath(ctx ModuleContext) android.ModuleGenPath {
+	return android.PathForModuleGen(ctx, "stub.omitted")
+}
+
 func compileStubLibrary(ctx ModuleContext, flags Flags, symbolFile, apiLevel, genstubFlags string) (Objects, android.ModuleGenPath) {
 	arch := ctx.Arch().ArchType.String()
 
 	stubSrcPath := android.PathForModuleGen(ctx, "stub.c")
 	versionScriptPath := android.PathForModuleGen(ctx, "stub.map")
+	omittedSymbolsPath := stubOmittedSymbolsPath(ctx)
 	symbolFilePath := android.PathForModuleSrc(ctx, symbolFile)
 	apiLevelsJson := android.GetApiLevelsJson(ctx)
 	ctx.Build(pctx, android.BuildParams{
-		Rule:        genStubSrc,
-		Description: "generate stubs " + symbolFilePath.Rel(),
-		Outputs:     []android.WritablePath{stubSrcPath, versionScriptPath},
-		Input:       symbolFilePath,
-		Implicits:   []android.Path{apiLevelsJson},
+		Rule:           genStubSrc,
+		Description:    "generate stubs " + symbolFilePath.Rel(),
+		Outputs:        []android.WritablePath{stubSrcPath, versionScriptPath},
+		ImplicitOutput: omittedSymbolsPath,
+		Input:          symbolFilePath,
+		Implicits:      []android.Path{apiLevelsJson},
 		Args: map[string]string{
-			"arch":     arch,
-			"apiLevel": apiLevel,
-			"apiMap":   apiLevelsJson.String(),
-			"flags":    genstubFlags,
+			"arch":           arch,
+			"apiLevel":       apiLevel,
+			"apiMap":         apiLevelsJson.String(),
+			"flags":          genstubFlags,
+			"omittedSymbols": omittedSymbolsPath.String(),
 		},
 	})
 
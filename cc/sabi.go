@@ -15,9 +15,12 @@
 package cc
 
 import (
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/google/blueprint"
+
 	"android/soong/android"
 	"android/soong/cc/config"
 )
@@ -27,6 +30,10 @@ var (
 	sabiLock    sync.Mutex
 )
 
+func init() {
+	android.RegisterSingletonType("abi_references", abiReferencesSingletonFactory)
+}
+
 type SAbiProperties struct {
 	CreateSAbiDumps        bool `blueprint:"mutated"`
 	ReexportedIncludeFlags []string
@@ -79,7 +86,7 @@ func (sabimod *sabi) flags(ctx ModuleContext, flags Flags) Flags {
 func sabiDepsMutator(mctx android.TopDownMutatorContext) {
 	if c, ok := mctx.Module().(*Module); ok &&
 		((c.isVndk() && c.useVndk()) || inList(c.Name(), llndkLibraries) ||
-			(c.sabi != nil && c.sabi.Properties.CreateSAbiDumps)) {
+			(c.sabi != nil && c.sabi.Properties.CreateSAbiDumps) || c.headerAbiCheckerEnabled()) {
 		mctx.VisitDirectDeps(func(m android.Module) {
 			tag := mctx.OtherModuleDependencyTag(m)
 			switch tag {
@@ -94,3 +101,52 @@ func sabiDepsMutator(mctx android.TopDownMutatorContext) {
 		})
 	}
 }
+
+func abiReferencesSingletonFactory() android.Singleton {
+	return &abiReferencesSingleton{}
+}
+
+// abiReferencesSingleton creates the update-abi-references-<library> targets, which copy the linked
+// ABI dumps of a library with header_abi_checker.ref_dump_dir over its reference dumps, and the
+// update-abi-references target that updates the reference dumps of all such libraries.
+type abiReferencesSingleton struct{}
+
+func (s *abiReferencesSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	updates := make(map[string]android.Paths)
+	ctx.VisitAllModules(func(module android.Module) {
+		c, ok := module.(*Module)
+		if !ok || !c.Enabled() {
+			return
+		}
+		if library, ok := c.linker.(*libraryDecorator); ok && library.sAbiRefUpdate.Valid() {
+			name := c.BaseModuleName()
+			updates[name] = append(updates[name], library.sAbiRefUpdate.Path())
+		}
+	})
+
+	if len(updates) == 0 {
+		return
+	}
+
+	var names []string
+	for name := range updates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var allUpdates android.Paths
+	for _, name := range names {
+		ctx.Build(pctx, android.BuildParams{
+			Rule:      blueprint.Phony,
+			Output:    android.PathForPhony(ctx, "update-abi-references-"+name),
+			Implicits: updates[name],
+		})
+		allUpdates = append(allUpdates, updates[name]...)
+	}
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:      blueprint.Phony,
+		Output:    android.PathForPhony(ctx, "update-abi-references"),
+		Implicits: allUpdates,
+	})
+}
@@ -0,0 +1,235 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// build_app_bundle assembles bundle modules, as produced for android_app modules, into an Android
+// App Bundle (.aab) that bundletool can turn into APKs.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"android/soong/third_party/zip"
+)
+
+var (
+	output  = flag.String("o", "", "output .aab file")
+	version = flag.String("bundletool-version", "0.10.0", "version of bundletool the bundle is compatible with")
+)
+
+const bundleConfigName = "BundleConfig.pb"
+
+// ABIs allowed in the lib/ directory of a bundle module.
+var knownAbis = []string{
+	"armeabi",
+	"armeabi-v7a",
+	"arm64-v8a",
+	"x86",
+	"x86_64",
+	"mips",
+	"mips64",
+}
+
+type bundleModule struct {
+	name string
+	zip  *zip.Reader
+}
+
+// validateModule checks that the entries of a bundle module are in the layout bundletool expects:
+// the manifest under manifest/, dex files under dex/, native libraries under lib/<abi>/, resources
+// in resources.pb, res/ and assets/, and anything else to be copied to the root of the APK under
+// root/.
+func validateModule(m bundleModule) error {
+	hasManifest := false
+	for _, f := range m.zip.File {
+		name := f.Name
+		if strings.HasSuffix(name, "/") {
+			continue
+		}
+
+		dir := name
+		if i := strings.Index(name, "/"); i >= 0 {
+			dir = name[:i]
+		}
+
+		switch dir {
+		case "manifest":
+			if name != "manifest/AndroidManifest.xml" {
+				return fmt.Errorf("module %q: unexpected file %q in manifest/", m.name, name)
+			}
+			hasManifest = true
+		case "dex":
+			if match, _ := path.Match("dex/classes*.dex", name); !match {
+				return fmt.Errorf("module %q: unexpected file %q in dex/", m.name, name)
+			}
+		case "lib":
+			parts := strings.Split(name, "/")
+			if len(parts) != 3 || !inList(parts[1], knownAbis) {
+				return fmt.Errorf("module %q: native library %q is not in lib/<abi>/ for a known ABI",
+					m.name, name)
+			}
+		case "res", "assets", "root", "resources.pb", "assets.pb", "native.pb":
+		default:
+			return fmt.Errorf("module %q: unexpected file %q", m.name, name)
+		}
+	}
+	if !hasManifest {
+		return fmt.Errorf("module %q: missing manifest/AndroidManifest.xml", m.name)
+	}
+	return nil
+}
+
+// bundleConfig returns the BundleConfig.pb for the bundle, the serialized form of:
+//
+//   BundleConfig {
+//     bundletool { version: <version> }
+//   }
+//
+// as defined in bundletool's config.proto.
+func bundleConfig(version string) []byte {
+	const (
+		bundleConfigBundletoolField = 1
+		bundletoolVersionField      = 2
+	)
+	bundletool := appendBytesField(nil, bundletoolVersionField, []byte(version))
+	return appendBytesField(nil, bundleConfigBundletoolField, bundletool)
+}
+
+// appendBytesField appends a length delimited protobuf field, used for strings and embedded
+// messages, to b.
+func appendBytesField(b []byte, field int, value []byte) []byte {
+	const wireTypeLengthDelimited = 2
+	b = appendVarint(b, uint64(field<<3|wireTypeLengthDelimited))
+	b = appendVarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func buildBundle(writer *zip.Writer, modules []bundleModule, version string) error {
+	seen := make(map[string]bool)
+	for _, m := range modules {
+		if seen[m.name] {
+			return fmt.Errorf("duplicate module %q", m.name)
+		}
+		seen[m.name] = true
+		if err := validateModule(m); err != nil {
+			return err
+		}
+	}
+	if !seen["base"] {
+		return fmt.Errorf("missing base module")
+	}
+
+	w, err := writer.CreateHeader(&zip.FileHeader{
+		Name:   bundleConfigName,
+		Method: zip.Deflate,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(bundleConfig(version)); err != nil {
+		return err
+	}
+
+	for _, m := range modules {
+		for _, f := range m.zip.File {
+			if err := writer.CopyFrom(f, m.name+"/"+f.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func inList(s string, list []string) bool {
+	for _, l := range list {
+		if s == l {
+			return true
+		}
+	}
+	return false
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: build_app_bundle -o <bundle.aab> [-bundletool-version <version>] [<module>=]<module.zip>...")
+		flag.PrintDefaults()
+		fmt.Fprintln(os.Stderr, "")
+		fmt.Fprintln(os.Stderr, "A module zip without a module name is the base module.")
+	}
+
+	flag.Parse()
+
+	if *output == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	log.SetFlags(log.Lshortfile)
+
+	var modules []bundleModule
+	for _, arg := range flag.Args() {
+		name, file := "base", arg
+		if i := strings.Index(arg, "="); i >= 0 {
+			name, file = arg[:i], arg[i+1:]
+		}
+
+		reader, err := zip.OpenReader(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer reader.Close()
+
+		modules = append(modules, bundleModule{name, &reader.Reader})
+	}
+
+	if err := writeBundle(*output, modules, *version); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeBundle writes the bundle of the modules to output.  The output is removed on failure, so that
+// a truncated bundle is never left behind.
+func writeBundle(output string, modules []bundleModule, version string) (err error) {
+	outputFile, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			outputFile.Close()
+			os.Remove(output)
+		}
+	}()
+
+	writer := zip.NewWriter(outputFile)
+	if err := buildBundle(writer, modules, version); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return outputFile.Close()
+}
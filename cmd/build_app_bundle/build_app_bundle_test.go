@@ -0,0 +1,227 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"android/soong/third_party/zip"
+)
+
+func testZip(t *testing.T, files []string) *zip.Reader {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	writer := zip.NewWriter(buf)
+	for _, name := range files {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(name))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reader
+}
+
+var baseModuleFiles = []string{
+	"manifest/AndroidManifest.xml",
+	"resources.pb",
+	"res/drawable/icon.png",
+	"assets/data.bin",
+	"dex/classes.dex",
+	"dex/classes2.dex",
+	"root/META-INF/services/foo",
+	"lib/arm64-v8a/libfoo.so",
+	"lib/armeabi-v7a/libfoo.so",
+}
+
+func TestBuildBundle(t *testing.T) {
+	testCases := []struct {
+		name    string
+		modules map[string][]string
+		order   []string
+
+		files []string
+		err   error
+	}{
+		{
+			name:    "base module",
+			modules: map[string][]string{"base": baseModuleFiles},
+			order:   []string{"base"},
+
+			files: append([]string{"BundleConfig.pb"}, prefix("base/", baseModuleFiles)...),
+		},
+		{
+			name: "feature module",
+			modules: map[string][]string{
+				"base":    {"manifest/AndroidManifest.xml"},
+				"feature": {"manifest/AndroidManifest.xml", "dex/classes.dex"},
+			},
+			order: []string{"base", "feature"},
+
+			files: []string{
+				"BundleConfig.pb",
+				"base/manifest/AndroidManifest.xml",
+				"feature/manifest/AndroidManifest.xml",
+				"feature/dex/classes.dex",
+			},
+		},
+		{
+			name:    "missing base",
+			modules: map[string][]string{"feature": {"manifest/AndroidManifest.xml"}},
+			order:   []string{"feature"},
+
+			err: fmt.Errorf("missing base module"),
+		},
+		{
+			name:    "missing manifest",
+			modules: map[string][]string{"base": {"dex/classes.dex"}},
+			order:   []string{"base"},
+
+			err: fmt.Errorf(`module "base": missing manifest/AndroidManifest.xml`),
+		},
+		{
+			name:    "unknown abi",
+			modules: map[string][]string{"base": {"manifest/AndroidManifest.xml", "lib/arm64/libfoo.so"}},
+			order:   []string{"base"},
+
+			err: fmt.Errorf(`module "base": native library "lib/arm64/libfoo.so" is not in lib/<abi>/ for a known ABI`),
+		},
+		{
+			name:    "misplaced dex",
+			modules: map[string][]string{"base": {"manifest/AndroidManifest.xml", "dex/foo.jar"}},
+			order:   []string{"base"},
+
+			err: fmt.Errorf(`module "base": unexpected file "dex/foo.jar" in dex/`),
+		},
+		{
+			name:    "unexpected file",
+			modules: map[string][]string{"base": {"manifest/AndroidManifest.xml", "classes.dex"}},
+			order:   []string{"base"},
+
+			err: fmt.Errorf(`module "base": unexpected file "classes.dex"`),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var modules []bundleModule
+			for _, name := range test.order {
+				modules = append(modules, bundleModule{name, testZip(t, test.modules[name])})
+			}
+
+			buf := &bytes.Buffer{}
+			writer := zip.NewWriter(buf)
+			err := buildBundle(writer, modules, "0.10.0")
+			if errorString(err) != errorString(test.err) {
+				t.Fatalf("want error %q, got %q", errorString(test.err), errorString(err))
+			}
+			if err != nil {
+				return
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var files []string
+			for _, f := range reader.File {
+				files = append(files, f.Name)
+			}
+			if !reflect.DeepEqual(test.files, files) {
+				t.Errorf("want bundle files %q, got %q", test.files, files)
+			}
+
+			config, err := reader.File[0].Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer config.Close()
+			contents, err := ioutil.ReadAll(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(contents, bundleConfig("0.10.0")) {
+				t.Errorf("want bundle config %q, got %q", bundleConfig("0.10.0"), contents)
+			}
+		})
+	}
+}
+
+func TestBundleConfig(t *testing.T) {
+	// BundleConfig { bundletool { version: "0.10.0" } }
+	want := []byte{0x0a, 0x08, 0x12, 0x06, '0', '.', '1', '0', '.', '0'}
+	if got := bundleConfig("0.10.0"); !bytes.Equal(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func prefix(p string, list []string) []string {
+	var ret []string
+	for _, s := range list {
+		ret = append(ret, p+s)
+	}
+	return ret
+}
+
+func errorString(e error) string {
+	if e == nil {
+		return ""
+	}
+	return e.Error()
+}
+
+func TestWriteBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "build_app_bundle_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "app.aab")
+	modules := []bundleModule{{"base", testZip(t, baseModuleFiles)}}
+	if err := writeBundle(output, modules, "0.10.0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := zip.OpenReader(output); err != nil {
+		t.Errorf("want a complete bundle, got %q", err)
+	}
+
+	// A failure removes the bundle written by the previous run.
+	modules = []bundleModule{{"base", testZip(t, []string{"dex/classes.dex"})}}
+	if err := writeBundle(output, modules, "0.10.0"); err == nil {
+		t.Fatal("want an error for a base module without a manifest")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("want %q to be removed, got %v", output, err)
+	}
+}
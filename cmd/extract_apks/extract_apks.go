@@ -0,0 +1,399 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// extract_apks selects the APKs that should be installed on a device from an APK set, the .apks
+// archive produced by "bundletool build-apks", and extracts them.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"android/soong/third_party/zip"
+)
+
+var (
+	output     = flag.String("o", "", "output file for the main APK")
+	splits     = flag.String("splits", "", "output zip file for the split APKs installed alongside the main APK")
+	stem       = flag.String("stem", "", "name of the installed APKs, split APKs are named <stem>-<split>.apk")
+	sdkVersion = flag.Int("sdk-version", 0, "SDK version of the device")
+	abis       = flag.String("abis", "", "comma separated list of the device ABIs, in order of preference")
+	density    = flag.String("screen-density", "", "screen density of the device, e.g. xhdpi, or the highest available if unset")
+)
+
+// Screen densities used by bundletool to name density splits and standalone APKs.
+var densityDpis = map[string]int{
+	"ldpi":    120,
+	"mdpi":    160,
+	"tvdpi":   213,
+	"hdpi":    240,
+	"xhdpi":   320,
+	"xxhdpi":  480,
+	"xxxhdpi": 640,
+}
+
+// ABIs used by bundletool to name ABI splits and standalone APKs, with '-' replaced by '_'.
+var knownAbis = []string{
+	"armeabi",
+	"armeabi_v7a",
+	"arm64_v8a",
+	"x86",
+	"x86_64",
+	"mips",
+	"mips64",
+}
+
+const (
+	systemApk    = "system/system.apk"
+	masterSplit  = "splits/base-master.apk"
+	splitPrefix  = "splits/base-"
+	standalone   = "standalones/standalone"
+	universalApk = "universal.apk"
+	apkExtension = ".apk"
+
+	// Split APKs are supported since Lollipop.
+	minSplitsSdk = 21
+)
+
+type targetConfig struct {
+	sdkVersion int
+	// ABIs of the device in order of preference, in the form used by bundletool, e.g. arm64_v8a.
+	abis []string
+	// Screen density of the device, e.g. xhdpi, or empty to select the highest available.
+	density string
+}
+
+type splitApk struct {
+	entry string
+	// The name of the split, used to name the extracted APK.
+	name string
+}
+
+type selection struct {
+	main   string
+	splits []splitApk
+}
+
+// selectApks returns the entries of an APK set to install on a device with the given configuration.
+// In order of preference, it selects the system APK built with "bundletool build-apks --mode=system",
+// the master split of the base module with the matching ABI, density and all language splits, the
+// best matching standalone APK, or the universal APK.
+func selectApks(entries []string, config targetConfig) (selection, error) {
+	has := make(map[string]bool)
+	for _, entry := range entries {
+		has[entry] = true
+	}
+
+	if has[systemApk] {
+		return selection{main: systemApk}, nil
+	}
+
+	if config.sdkVersion >= minSplitsSdk && has[masterSplit] {
+		return selectSplits(entries, config)
+	}
+
+	if apk := selectStandalone(entries, config); apk != "" {
+		return selection{main: apk}, nil
+	}
+
+	if has[universalApk] {
+		return selection{main: universalApk}, nil
+	}
+
+	return selection{}, fmt.Errorf("no APK in the set matches the device configuration %+v", config)
+}
+
+func selectSplits(entries []string, config targetConfig) (selection, error) {
+	ret := selection{main: masterSplit}
+
+	abiSplits := make(map[string]string)
+	densitySplits := make(map[string]string)
+	var languageSplits []splitApk
+
+	for _, entry := range entries {
+		if entry == masterSplit || !strings.HasPrefix(entry, splitPrefix) ||
+			!strings.HasSuffix(entry, apkExtension) {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(entry, splitPrefix), apkExtension)
+		if isAbi(name) {
+			abiSplits[name] = entry
+		} else if _, ok := densityDpis[name]; ok {
+			densitySplits[name] = entry
+		} else {
+			// Everything else is a language split, install all of them on the system image.
+			languageSplits = append(languageSplits, splitApk{entry, name})
+		}
+	}
+
+	if len(abiSplits) > 0 {
+		abi := matchAbi(keys(abiSplits), config.abis)
+		if abi == "" {
+			return selection{}, fmt.Errorf("none of the ABI splits %q match the device ABIs %q",
+				keys(abiSplits), config.abis)
+		}
+		ret.splits = append(ret.splits, splitApk{abiSplits[abi], abi})
+	}
+
+	if len(densitySplits) > 0 {
+		d := matchDensity(keys(densitySplits), config.density)
+		ret.splits = append(ret.splits, splitApk{densitySplits[d], d})
+	}
+
+	sort.Slice(languageSplits, func(i, j int) bool { return languageSplits[i].name < languageSplits[j].name })
+	ret.splits = append(ret.splits, languageSplits...)
+
+	return ret, nil
+}
+
+// selectStandalone returns the standalone APK built for the most preferred device ABI, and the
+// best matching density among those, or an empty string if there is no compatible standalone APK.
+func selectStandalone(entries []string, config targetConfig) string {
+	type candidate struct {
+		entry   string
+		abi     string
+		density string
+	}
+	var candidates []candidate
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry, standalone) || !strings.HasSuffix(entry, apkExtension) {
+			continue
+		}
+		dims := strings.TrimSuffix(strings.TrimPrefix(entry, standalone), apkExtension)
+		if dims != "" && !strings.HasPrefix(dims, "-") {
+			continue
+		}
+		abi, d := parseStandaloneDimensions(strings.TrimPrefix(dims, "-"))
+		if abi != "" && matchAbi([]string{abi}, config.abis) == "" {
+			continue
+		}
+		candidates = append(candidates, candidate{entry, abi, d})
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	// Standalone APKs without an ABI work on any device, but prefer the ones for the device ABI.
+	var abis []string
+	for _, c := range candidates {
+		abis = append(abis, c.abi)
+	}
+	abi := matchAbi(abis, config.abis)
+
+	var densities []string
+	for _, c := range candidates {
+		if c.abi == abi && c.density != "" {
+			densities = append(densities, c.density)
+		}
+	}
+	d := ""
+	if len(densities) > 0 {
+		d = matchDensity(densities, config.density)
+	}
+
+	for _, c := range candidates {
+		if c.abi == abi && c.density == d {
+			return c.entry
+		}
+	}
+	return ""
+}
+
+// parseStandaloneDimensions splits the dimensions of a standalone APK name, e.g. arm64_v8a_xhdpi,
+// into its ABI and density.
+func parseStandaloneDimensions(dims string) (abi, density string) {
+	if dims == "" {
+		return "", ""
+	}
+	if _, ok := densityDpis[dims]; ok {
+		return "", dims
+	}
+	if i := strings.LastIndex(dims, "_"); i >= 0 {
+		if _, ok := densityDpis[dims[i+1:]]; ok {
+			return dims[:i], dims[i+1:]
+		}
+	}
+	return dims, ""
+}
+
+func isAbi(s string) bool {
+	for _, abi := range knownAbis {
+		if s == abi {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAbi returns the available ABI that is the most preferred by the device, or an empty string
+// if none of them is supported by the device.
+func matchAbi(available []string, deviceAbis []string) string {
+	for _, deviceAbi := range deviceAbis {
+		for _, abi := range available {
+			if abi == deviceAbi {
+				return abi
+			}
+		}
+	}
+	return ""
+}
+
+// matchDensity returns the exact available density, the lowest density higher than the wanted
+// one, or the highest available density.
+func matchDensity(available []string, want string) string {
+	sort.Slice(available, func(i, j int) bool { return densityDpis[available[i]] < densityDpis[available[j]] })
+	if wantDpi, ok := densityDpis[want]; ok {
+		for _, d := range available {
+			if densityDpis[d] >= wantDpi {
+				return d
+			}
+		}
+	}
+	return available[len(available)-1]
+}
+
+func keys(m map[string]string) []string {
+	var ret []string
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func parseDensity(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	if _, ok := densityDpis[s]; ok {
+		return s, nil
+	}
+	if dpi, err := strconv.Atoi(s); err == nil {
+		// Map the dpi to the closest named density that is at least as high.
+		best := ""
+		for name, d := range densityDpis {
+			if d >= dpi && (best == "" || d < densityDpis[best]) {
+				best = name
+			}
+		}
+		if best == "" {
+			best = "xxxhdpi"
+		}
+		return best, nil
+	}
+	return "", fmt.Errorf("unknown screen density %q", s)
+}
+
+func extract(reader *zip.Reader, sel selection, stem string, mainWriter io.Writer, splitsWriter *zip.Writer) error {
+	files := make(map[string]*zip.File)
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+
+	r, err := files[sel.main].Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if _, err := io.Copy(mainWriter, r); err != nil {
+		return err
+	}
+
+	for _, split := range sel.splits {
+		if splitsWriter == nil {
+			return fmt.Errorf("the selected APKs include split %q, but -splits was not given", split.entry)
+		}
+		if err := splitsWriter.CopyFrom(files[split.entry], stem+"-"+split.name+apkExtension); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: extract_apks -o <main.apk> [-splits <splits.zip> -stem <name>] "+
+			"-sdk-version <version> -abis <abi>[,<abi>...] [-screen-density <density>] <set.apks>")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *output == "" || flag.NArg() != 1 || (*splits != "" && *stem == "") {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	log.SetFlags(log.Lshortfile)
+
+	config := targetConfig{sdkVersion: *sdkVersion}
+	for _, abi := range strings.Split(*abis, ",") {
+		if abi != "" {
+			config.abis = append(config.abis, strings.Replace(abi, "-", "_", -1))
+		}
+	}
+	d, err := parseDensity(*density)
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.density = d
+
+	reader, err := zip.OpenReader(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer reader.Close()
+
+	var entries []string
+	for _, f := range reader.File {
+		entries = append(entries, f.Name)
+	}
+
+	sel, err := selectApks(entries, config)
+	if err != nil {
+		log.Fatalf("%s: %s", flag.Arg(0), err)
+	}
+
+	mainFile, err := os.Create(*output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mainFile.Close()
+
+	var splitsWriter *zip.Writer
+	if *splits != "" {
+		splitsFile, err := os.Create(*splits)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer splitsFile.Close()
+
+		splitsWriter = zip.NewWriter(splitsFile)
+		defer func() {
+			if err := splitsWriter.Close(); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	if err := extract(&reader.Reader, sel, *stem, mainFile, splitsWriter); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"android/soong/third_party/zip"
+)
+
+var testCases = []struct {
+	name string
+
+	entries []string
+	config  targetConfig
+
+	selection selection
+	err       error
+}{
+	{
+		name: "system apk",
+
+		entries: []string{
+			"system/system.apk",
+			"splits/base-master.apk",
+			"universal.apk",
+		},
+		config: targetConfig{sdkVersion: 29, abis: []string{"arm64_v8a"}, density: "xhdpi"},
+
+		selection: selection{main: "system/system.apk"},
+	},
+	{
+		name: "splits",
+
+		entries: []string{
+			"toc.pb",
+			"splits/base-master.apk",
+			"splits/base-armeabi_v7a.apk",
+			"splits/base-arm64_v8a.apk",
+			"splits/base-x86.apk",
+			"splits/base-mdpi.apk",
+			"splits/base-xhdpi.apk",
+			"splits/base-xxhdpi.apk",
+			"splits/base-fr.apk",
+			"splits/base-de.apk",
+			"splits/feature-master.apk",
+			"standalones/standalone-arm64_v8a_xhdpi.apk",
+		},
+		config: targetConfig{sdkVersion: 29, abis: []string{"arm64_v8a", "armeabi_v7a"}, density: "hdpi"},
+
+		selection: selection{
+			main: "splits/base-master.apk",
+			splits: []splitApk{
+				{"splits/base-arm64_v8a.apk", "arm64_v8a"},
+				{"splits/base-xhdpi.apk", "xhdpi"},
+				{"splits/base-de.apk", "de"},
+				{"splits/base-fr.apk", "fr"},
+			},
+		},
+	},
+	{
+		name: "splits secondary abi and highest density",
+
+		entries: []string{
+			"splits/base-master.apk",
+			"splits/base-armeabi_v7a.apk",
+			"splits/base-x86.apk",
+			"splits/base-mdpi.apk",
+			"splits/base-xxhdpi.apk",
+		},
+		config: targetConfig{sdkVersion: 29, abis: []string{"arm64_v8a", "armeabi_v7a"}},
+
+		selection: selection{
+			main: "splits/base-master.apk",
+			splits: []splitApk{
+				{"splits/base-armeabi_v7a.apk", "armeabi_v7a"},
+				{"splits/base-xxhdpi.apk", "xxhdpi"},
+			},
+		},
+	},
+	{
+		name: "splits unsupported abi",
+
+		entries: []string{
+			"splits/base-master.apk",
+			"splits/base-x86.apk",
+		},
+		config: targetConfig{sdkVersion: 29, abis: []string{"arm64_v8a"}},
+
+		err: fmt.Errorf(`none of the ABI splits ["x86"] match the device ABIs ["arm64_v8a"]`),
+	},
+	{
+		name: "standalone before splits are supported",
+
+		entries: []string{
+			"splits/base-master.apk",
+			"splits/base-arm64_v8a.apk",
+			"standalones/standalone-armeabi_v7a_mdpi.apk",
+			"standalones/standalone-armeabi_v7a_xhdpi.apk",
+			"standalones/standalone-x86_xhdpi.apk",
+			"standalones/standalone-x86_64_xhdpi.apk",
+		},
+		config: targetConfig{sdkVersion: 19, abis: []string{"x86_64", "x86"}, density: "xhdpi"},
+
+		selection: selection{main: "standalones/standalone-x86_64_xhdpi.apk"},
+	},
+	{
+		name: "standalone without abi",
+
+		entries: []string{
+			"standalones/standalone-mdpi.apk",
+			"standalones/standalone-hdpi.apk",
+		},
+		config: targetConfig{sdkVersion: 29, abis: []string{"arm64_v8a"}, density: "xhdpi"},
+
+		selection: selection{main: "standalones/standalone-hdpi.apk"},
+	},
+	{
+		name: "universal",
+
+		entries: []string{
+			"standalones/standalone-x86.apk",
+			"universal.apk",
+		},
+		config: targetConfig{sdkVersion: 29, abis: []string{"arm64_v8a"}},
+
+		selection: selection{main: "universal.apk"},
+	},
+	{
+		name: "no match",
+
+		entries: []string{
+			"standalones/standalone-x86.apk",
+		},
+		config: targetConfig{sdkVersion: 29, abis: []string{"arm64_v8a"}},
+
+		err: fmt.Errorf("no APK in the set matches the device configuration " +
+			"{sdkVersion:29 abis:[arm64_v8a] density:}"),
+	},
+}
+
+func errorString(e error) string {
+	if e == nil {
+		return ""
+	}
+	return e.Error()
+}
+
+func TestSelectApks(t *testing.T) {
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			sel, err := selectApks(testCase.entries, testCase.config)
+			if errorString(testCase.err) != errorString(err) {
+				t.Fatalf("want error %q, got %q", errorString(testCase.err), errorString(err))
+			}
+			if !reflect.DeepEqual(testCase.selection, sel) {
+				t.Errorf("want selection %+v, got %+v", testCase.selection, sel)
+			}
+		})
+	}
+}
+
+func TestParseDensity(t *testing.T) {
+	for _, test := range []struct{ in, want string }{
+		{"", ""},
+		{"xhdpi", "xhdpi"},
+		{"320", "xhdpi"},
+		{"400", "xxhdpi"},
+		{"800", "xxxhdpi"},
+	} {
+		got, err := parseDensity(test.in)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %s", test.in, err)
+		} else if got != test.want {
+			t.Errorf("want density %q for %q, got %q", test.want, test.in, got)
+		}
+	}
+
+	if _, err := parseDensity("nodpi"); err == nil {
+		t.Errorf("expected an error for an unknown density")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	inputBuf := &bytes.Buffer{}
+	inputWriter := zip.NewWriter(inputBuf)
+	for _, name := range []string{"splits/base-master.apk", "splits/base-arm64_v8a.apk"} {
+		w, err := inputWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(name))
+	}
+	inputWriter.Close()
+
+	reader, err := zip.NewReader(bytes.NewReader(inputBuf.Bytes()), int64(inputBuf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sel := selection{
+		main:   "splits/base-master.apk",
+		splits: []splitApk{{"splits/base-arm64_v8a.apk", "arm64_v8a"}},
+	}
+
+	mainBuf := &bytes.Buffer{}
+	splitsBuf := &bytes.Buffer{}
+	splitsWriter := zip.NewWriter(splitsBuf)
+	if err := extract(reader, sel, "Foo", mainBuf, splitsWriter); err != nil {
+		t.Fatal(err)
+	}
+	splitsWriter.Close()
+
+	if mainBuf.String() != "splits/base-master.apk" {
+		t.Errorf("wrong main APK contents %q", mainBuf.String())
+	}
+
+	splitsReader, err := zip.NewReader(bytes.NewReader(splitsBuf.Bytes()), int64(splitsBuf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range splitsReader.File {
+		names = append(names, f.Name)
+	}
+	if want := []string{"Foo-arm64_v8a.apk"}; !reflect.DeepEqual(want, names) {
+		t.Errorf("want split APKs %q, got %q", want, names)
+	}
+
+	if err := extract(reader, sel, "Foo", &bytes.Buffer{}, nil); err == nil {
+		t.Errorf("expected an error when extracting splits without a splits zip")
+	}
+}
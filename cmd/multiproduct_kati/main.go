@@ -173,7 +173,7 @@ func main() {
 	stat := &status.Status{}
 	defer stat.Finish()
 	stat.AddOutput(terminal.NewStatusOutput(writer, "",
-		build.OsEnvironment().IsEnvTrue("ANDROID_QUIET_BUILD")))
+		build.OsEnvironment().IsEnvTrue("ANDROID_QUIET_BUILD"), 0))
 
 	var failures failureCount
 	stat.AddOutput(&failures)
@@ -391,7 +391,7 @@ func buildProduct(mpctx *mpContext, product string) {
 		Status:  &status.Status{},
 	}}
 	ctx.Status.AddOutput(terminal.NewStatusOutput(ctx.Writer, "",
-		build.OsEnvironment().IsEnvTrue("ANDROID_QUIET_BUILD")))
+		build.OsEnvironment().IsEnvTrue("ANDROID_QUIET_BUILD"), 0))
 
 	config := build.NewConfig(ctx, flag.Args()...)
 	config.Environment().Set("OUT_DIR", outDir)
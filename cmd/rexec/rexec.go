@@ -0,0 +1,149 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// rexec runs a command through a remote execution service, staging only the declared inputs and
+// downloading the declared outputs.  It is used by Soong to wrap RuleBuilder and genrule commands
+// when USE_REMOTE_EXEC is set.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"android/soong/remoteexec"
+	"android/soong/remoteexec/reapi_proto"
+)
+
+type multiString []string
+
+func (m *multiString) String() string     { return strings.Join(*m, " ") }
+func (m *multiString) Set(s string) error { *m = append(*m, s); return nil }
+
+var (
+	server        = flag.String("server", os.Getenv("REMOTE_EXEC_SERVER"), "gRPC address of the REAPI remote execution service, prefixed with grpcs:// to use TLS")
+	instance      = flag.String("instance", os.Getenv("REMOTE_EXEC_INSTANCE"), "instance name on the remote execution service")
+	execRoot      = flag.String("exec_root", ".", "directory that inputs and outputs are relative to")
+	inputsList    = flag.String("inputs_list", "", "file containing a whitespace separated list of inputs")
+	localFallback = flag.Bool("local_fallback", os.Getenv("REMOTE_EXEC_LOCAL_FALLBACK") == "true",
+		"run the command locally if it cannot be run remotely")
+	noCache = flag.Bool("no_cache", false, "do not use the action cache")
+
+	inputs  multiString
+	outputs multiString
+	envVars multiString
+)
+
+func init() {
+	flag.Var(&inputs, "input", "an input file or directory, may be repeated")
+	flag.Var(&outputs, "output", "an output file, may be repeated")
+	flag.Var(&envVars, "env_var", "name of a local environment variable to set for the command, may be repeated")
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: rexec [--server <address>] [--inputs_list <file>] [--input <path>]... "+
+			"[--output <path>]... -- <command> [<args>...]")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if *inputsList != "" {
+		list, err := ioutil.ReadFile(*inputsList)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "rexec:", err)
+			os.Exit(1)
+		}
+		inputs = append(inputs, strings.Fields(string(list))...)
+	}
+
+	var env []*reapi_proto.Command_EnvironmentVariable
+	for _, name := range envVars {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, &reapi_proto.Command_EnvironmentVariable{Name: name, Value: value})
+		}
+	}
+
+	if *server == "" {
+		if *localFallback {
+			os.Exit(runLocally(args))
+		}
+		fmt.Fprintln(os.Stderr, "rexec: --server or REMOTE_EXEC_SERVER is required")
+		os.Exit(1)
+	}
+
+	client, err := remoteexec.Dial(*server, *instance)
+	if err != nil {
+		if *localFallback {
+			fmt.Fprintln(os.Stderr, "rexec: failed to connect to remote execution service, running locally:", err)
+			os.Exit(runLocally(args))
+		}
+		fmt.Fprintln(os.Stderr, "rexec: failed to connect to remote execution service:", err)
+		os.Exit(1)
+	}
+
+	exitCode, err := client.Run(context.Background(), remoteexec.Request{
+		ExecRoot:    *execRoot,
+		Inputs:      inputs,
+		Outputs:     outputs,
+		Arguments:   args,
+		Environment: env,
+		DoNotCache:  *noCache,
+		Stdout:      os.Stdout,
+		Stderr:      os.Stderr,
+	})
+	client.Close()
+	if err != nil {
+		if *localFallback {
+			fmt.Fprintln(os.Stderr, "rexec: remote execution failed, running locally:", err)
+			os.Exit(runLocally(args))
+		}
+		fmt.Fprintln(os.Stderr, "rexec: remote execution failed:", err)
+		os.Exit(1)
+	}
+
+	os.Exit(exitCode)
+}
+
+func runLocally(args []string) int {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = *execRoot
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Exited() {
+				return status.ExitStatus()
+			}
+			return 1
+		}
+		fmt.Fprintln(os.Stderr, "rexec:", err)
+		return 1
+	}
+	return 0
+}
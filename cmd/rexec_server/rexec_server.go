@@ -0,0 +1,66 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// rexec_server is a local stand-in for a remote execution service.  It executes actions on the local
+// machine, each in a directory containing only its declared inputs, which is useful for testing
+// remote execution and for finding undeclared inputs without a remote execution service:
+//
+//     rexec_server --listen localhost:8980 --dir /tmp/rexec &
+//     USE_REMOTE_EXEC=true REMOTE_EXEC_SERVER=localhost:8980 m
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+
+	"android/soong/remoteexec"
+)
+
+var (
+	listen = flag.String("listen", "localhost:8980", "address to listen on")
+	dir    = flag.String("dir", "", "directory to store blobs and execute actions in")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: rexec_server --dir <dir> [--listen <address>]")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *dir == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	server, err := remoteexec.NewLocalServer(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+	log.Fatal(grpcServer.Serve(listener))
+}
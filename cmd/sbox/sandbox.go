@@ -0,0 +1,244 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"debug/elf"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// stageInputs copies each of the inputs into the same path relative to sandboxRoot as it has
+// relative to the current directory.  Inputs that are directories are staged recursively, and
+// symlinks are replaced with the files they point to.  Shared libraries that staged executables
+// load through an $ORIGIN relative runpath, e.g. $ORIGIN/../lib64 for host tools, are staged too.
+// Absolute inputs can't be staged and are left where they are.  It returns the set of staged files
+// relative to sandboxRoot.
+//
+// The inputs are copied rather than hardlinked, so that a command that modifies its inputs in place
+// can't modify the source files.
+func stageInputs(inputs []string, sandboxRoot string) (map[string]bool, error) {
+	staged := make(map[string]bool)
+
+	if err := os.MkdirAll(sandboxRoot, 0777); err != nil {
+		return nil, err
+	}
+
+	for _, input := range inputs {
+		input = filepath.Clean(input)
+		if filepath.IsAbs(input) {
+			continue
+		}
+		if err := stagePath(input, sandboxRoot, staged); err != nil {
+			return nil, err
+		}
+	}
+
+	return staged, nil
+}
+
+func stagePath(path string, sandboxRoot string, staged map[string]bool) error {
+	if staged[path] {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		dir, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		names, err := dir.Readdirnames(-1)
+		dir.Close()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if err := stagePath(filepath.Join(path, name), sandboxRoot, staged); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	staged[path] = true
+	if err := stageFile(path, filepath.Join(sandboxRoot, path), info); err != nil {
+		return err
+	}
+
+	for _, lib := range sharedLibraries(path) {
+		if err := stagePath(lib, sandboxRoot, staged); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sharedLibraries returns the shared libraries needed by the ELF file at path that are found in its
+// $ORIGIN relative runpath, relative to the current directory.  Libraries in other directories,
+// e.g. system libraries, are not returned.  It returns nil if path is not an ELF file.
+func sharedLibraries(path string) []string {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	needed, err := f.ImportedLibraries()
+	if err != nil || len(needed) == 0 {
+		return nil
+	}
+
+	var runpaths []string
+	for _, tag := range []elf.DynTag{elf.DT_RUNPATH, elf.DT_RPATH} {
+		values, _ := f.DynString(tag)
+		for _, value := range values {
+			runpaths = append(runpaths, filepath.SplitList(value)...)
+		}
+	}
+
+	var libs []string
+	for _, runpath := range runpaths {
+		var dir string
+		if rel := strings.TrimPrefix(runpath, "$ORIGIN"); rel != runpath {
+			dir = filepath.Join(filepath.Dir(path), rel)
+		} else if rel := strings.TrimPrefix(runpath, "${ORIGIN}"); rel != runpath {
+			dir = filepath.Join(filepath.Dir(path), rel)
+		} else {
+			continue
+		}
+		if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, "../") {
+			continue
+		}
+
+		for _, lib := range needed {
+			libPath := filepath.Join(dir, lib)
+			if _, err := os.Stat(libPath); err == nil {
+				libs = append(libs, libPath)
+			}
+		}
+	}
+
+	return libs
+}
+
+func stageFile(from, to string, info os.FileInfo) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0777); err != nil {
+		return err
+	}
+
+	// Copy the file that a symlink points to instead of the symlink, whose target may not be staged.
+	resolved, err := filepath.EvalSymlinks(from)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(resolved)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// undeclaredInputs returns the relative paths that appear in the command and exist in the current
+// directory but don't exist in sandboxRoot, which means they are read by the command without being
+// declared as inputs.
+func undeclaredInputs(command string, sandboxRoot string) []string {
+	seen := make(map[string]bool)
+	var undeclared []string
+
+	words := strings.FieldsFunc(command, func(r rune) bool {
+		return strings.ContainsRune(" \t\n'\"`;&|()<>=,:", r)
+	})
+
+	for _, word := range words {
+		if !strings.Contains(word, "/") || strings.ContainsAny(word, "$*?[") || filepath.IsAbs(word) {
+			continue
+		}
+		path := filepath.Clean(word)
+		if strings.HasPrefix(path, "../") || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(sandboxRoot, path)); err == nil {
+			continue
+		}
+		undeclared = append(undeclared, path)
+	}
+
+	sort.Strings(undeclared)
+	return undeclared
+}
+
+// undeclaredOutputs returns the files in outDir that are not in outputs, and the files in
+// sandboxRoot that were not staged into it.
+func undeclaredOutputs(outDir string, outputs []string, sandboxRoot string, staged map[string]bool) ([]string, error) {
+	declared := make(map[string]bool)
+	for _, output := range outputs {
+		declared[filepath.Clean(output)] = true
+	}
+
+	var undeclared []string
+	find := func(root string, expected map[string]bool) error {
+		return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			if !expected[rel] {
+				undeclared = append(undeclared, path)
+			}
+			return nil
+		})
+	}
+
+	if err := find(outDir, declared); err != nil {
+		return nil, err
+	}
+	if err := find(sandboxRoot, staged); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(undeclared)
+	return undeclared, nil
+}
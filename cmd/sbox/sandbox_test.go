@@ -0,0 +1,226 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setUpSourceTree creates a temporary directory containing files and changes into it.  The returned
+// function changes back to the original directory and removes the temporary directory.
+func setUpSourceTree(t *testing.T, files []string) func() {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "sbox_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(file), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	return func() {
+		os.Chdir(wd)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestStageInputs(t *testing.T) {
+	defer setUpSourceTree(t, []string{
+		"a/a.txt",
+		"b/b1.txt",
+		"b/c/b2.txt",
+		"undeclared/d.txt",
+	})()
+
+	if err := os.Symlink("../a/a.txt", "b/link.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	staged, err := stageInputs([]string{"a/a.txt", "b", "./a/a.txt"}, "sandbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantStaged := map[string]bool{
+		"a/a.txt":    true,
+		"b/b1.txt":   true,
+		"b/c/b2.txt": true,
+		"b/link.txt": true,
+	}
+	if !reflect.DeepEqual(staged, wantStaged) {
+		t.Errorf("want staged files %v, got %v", wantStaged, staged)
+	}
+
+	for file := range wantStaged {
+		contents, err := ioutil.ReadFile(filepath.Join("sandbox", file))
+		if err != nil {
+			t.Errorf("reading staged file: %s", err)
+			continue
+		}
+		want := file
+		if file == "b/link.txt" {
+			want = "a/a.txt"
+		}
+		if string(contents) != want {
+			t.Errorf("want %q to contain %q, got %q", file, want, string(contents))
+		}
+	}
+
+	if _, err := os.Lstat(filepath.Join("sandbox", "undeclared")); err == nil {
+		t.Errorf("undeclared directory was staged")
+	}
+
+	if _, err := stageInputs([]string{"missing"}, "sandbox2"); err == nil {
+		t.Errorf("expected error staging missing input")
+	}
+}
+
+func TestStageInputsCopies(t *testing.T) {
+	defer setUpSourceTree(t, []string{
+		"a/a.txt",
+	})()
+
+	if _, err := stageInputs([]string{"a/a.txt"}, "sandbox"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A command that truncates its input in place must not modify the source file.
+	if err := ioutil.WriteFile(filepath.Join("sandbox", "a/a.txt"), nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	contents, err := ioutil.ReadFile("a/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contents) != "a/a.txt" {
+		t.Errorf("source file was modified through the sandbox, contains %q", string(contents))
+	}
+}
+
+func TestStageInputsSharedLibraries(t *testing.T) {
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("no C compiler to build a tool with shared libraries")
+	}
+
+	defer setUpSourceTree(t, []string{
+		"host/lib.c",
+		"host/main.c",
+		"host/bin/.keep",
+		"host/lib64/.keep",
+	})()
+
+	if err := ioutil.WriteFile("host/lib.c", []byte("int foo(void) { return 0; }\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile("host/main.c", []byte("int foo(void);\nint main(void) { return foo(); }\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	// A host tool in bin/ that loads libfoo.so from lib64/ through $ORIGIN, like the host tools
+	// installed by Soong.
+	for _, args := range [][]string{
+		{"-shared", "-fPIC", "-o", "host/lib64/libfoo.so", "host/lib.c"},
+		{"-o", "host/bin/tool", "host/main.c", "-Lhost/lib64", "-lfoo", "-Wl,-rpath,$ORIGIN/../lib64"},
+	} {
+		if output, err := exec.Command(cc, args...).CombinedOutput(); err != nil {
+			t.Skipf("failed to build a tool with shared libraries: %s\n%s", err, output)
+		}
+	}
+
+	staged, err := stageInputs([]string{"host/bin/tool"}, "sandbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantStaged := map[string]bool{
+		"host/bin/tool":        true,
+		"host/lib64/libfoo.so": true,
+	}
+	if !reflect.DeepEqual(staged, wantStaged) {
+		t.Errorf("want staged files %v, got %v", wantStaged, staged)
+	}
+}
+
+func TestUndeclaredInputs(t *testing.T) {
+	defer setUpSourceTree(t, []string{
+		"a/a.txt",
+		"b/b.txt",
+		"c/c.txt",
+	})()
+
+	if _, err := stageInputs([]string{"a/a.txt"}, "sandbox"); err != nil {
+		t.Fatal(err)
+	}
+
+	command := "cat a/a.txt ./b/b.txt > /tmp/out && tool --flag=c/c.txt d/missing.txt c 'c/*.txt'"
+	want := []string{"b/b.txt", "c/c.txt"}
+
+	if got := undeclaredInputs(command, "sandbox"); !reflect.DeepEqual(got, want) {
+		t.Errorf("want undeclared inputs %q, got %q", want, got)
+	}
+}
+
+func TestUndeclaredOutputs(t *testing.T) {
+	defer setUpSourceTree(t, []string{
+		"a/a.txt",
+	})()
+
+	staged, err := stageInputs([]string{"a/a.txt"}, "sandbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"out/declared", "out/dir/declared", "out/undeclared", "sandbox/a/b.txt"} {
+		if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := undeclaredOutputs("out", []string{"declared", "dir/declared"}, "sandbox", staged)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"out/undeclared", "sandbox/a/b.txt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want undeclared outputs %q, got %q", want, got)
+	}
+}
@@ -24,6 +24,8 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+
+	"android/soong/cmd/sbox/sbox_manifest"
 )
 
 var (
@@ -32,6 +34,8 @@ var (
 	outputRoot    string
 	keepOutDir    bool
 	depfileOut    string
+	inputsList    string
+	manifestFile  string
 )
 
 func init() {
@@ -46,6 +50,10 @@ func init() {
 
 	flag.StringVar(&depfileOut, "depfile-out", "",
 		"file path of the depfile to generate. This value will replace '__SBOX_DEPFILE__' in the command and will be treated as an output but won't be added to __SBOX_OUT_FILES__")
+	flag.StringVar(&inputsList, "inputs-list", "",
+		"file containing a whitespace separated list of inputs and tools to stage into the sandbox. If set, the command is run in the sandbox instead of the current directory and writing files other than the declared outputs is an error")
+	flag.StringVar(&manifestFile, "manifest", "",
+		"JSON manifest listing the inputs, tools, outputs, output root and depfile of the command. Replaces --output-root, --depfile-out, --inputs-list and the output file arguments, and runs the command in the sandbox like --inputs-list")
 
 }
 
@@ -55,7 +63,8 @@ func usageViolation(violation string) {
 	}
 
 	fmt.Fprintf(os.Stderr,
-		"Usage: sbox -c <commandToRun> --sandbox-path <sandboxPath> --output-root <outputRoot> --overwrite [--depfile-out depFile] <outputFile> [<outputFile>...]\n"+
+		"Usage: sbox -c <commandToRun> --sandbox-path <sandboxPath> --output-root <outputRoot> --overwrite [--depfile-out depFile] [--inputs-list <inputsFile>] <outputFile> [<outputFile>...]\n"+
+			"       sbox -c <commandToRun> --sandbox-path <sandboxPath> --manifest <manifestFile>\n"+
 			"\n"+
 			"Deletes <outputRoot>,"+
 			"runs <commandToRun>,"+
@@ -107,19 +116,52 @@ func run() error {
 		// and by passing it as a parameter we don't need to duplicate its value
 		usageViolation("--sandbox-path <sandboxPath> is required and must be non-empty")
 	}
-	if len(outputRoot) == 0 {
-		usageViolation("--output-root <outputRoot> is required and must be non-empty")
-	}
 
 	// the contents of the __SBOX_OUT_FILES__ variable
-	outputsVarEntries := flag.Args()
-	if len(outputsVarEntries) == 0 {
-		usageViolation("at least one output file must be given")
+	var outputsVarEntries []string
+
+	// read the inputs before the output root is deleted in case the list is inside it
+	var inputs []string
+
+	if manifestFile != "" {
+		if outputRoot != "" || depfileOut != "" || inputsList != "" || len(flag.Args()) > 0 {
+			usageViolation("--manifest <manifestFile> can't be used with --output-root, --depfile-out, --inputs-list or output files")
+		}
+		data, err := ioutil.ReadFile(manifestFile)
+		if err != nil {
+			return err
+		}
+		manifest, err := sbox_manifest.Parse(data)
+		if err != nil {
+			return err
+		}
+		outputRoot = manifest.OutputRoot
+		depfileOut = manifest.Depfile
+		inputs = append(append(inputs, manifest.Inputs...), manifest.Tools...)
+		for _, output := range manifest.Outputs {
+			outputsVarEntries = append(outputsVarEntries, "__SBOX_OUT_DIR__/"+output)
+		}
+	} else {
+		if len(outputRoot) == 0 {
+			usageViolation("--output-root <outputRoot> is required and must be non-empty")
+		}
+		outputsVarEntries = flag.Args()
+		if len(outputsVarEntries) == 0 {
+			usageViolation("at least one output file must be given")
+		}
 	}
 
 	// all outputs
 	var allOutputs []string
 
+	if inputsList != "" {
+		list, err := ioutil.ReadFile(inputsList)
+		if err != nil {
+			return err
+		}
+		inputs = strings.Fields(string(list))
+	}
+
 	// setup directories
 	err := os.MkdirAll(sandboxesRoot, 0777)
 	if err != nil {
@@ -135,6 +177,32 @@ func run() error {
 	}
 
 	tempDir, err := ioutil.TempDir(sandboxesRoot, "sbox")
+	if err != nil {
+		return fmt.Errorf("Failed to create temp dir: %s", err)
+	}
+
+	// In the common case, the following line of code is what removes the sandbox
+	// If a fatal error occurs (such as if our Go process is killed unexpectedly),
+	// then at the beginning of the next build, Soong will retry the cleanup
+	defer func() {
+		// in some cases we decline to remove the temp dir, to facilitate debugging
+		if !keepOutDir {
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	// outputs are written into outDir.  When the inputs are staged the command runs in sandboxRoot
+	// instead of the current directory, which requires outDir to be an absolute path.
+	outDir := tempDir
+	sandboxRoot := ""
+	if inputsList != "" || manifestFile != "" {
+		tempDir, err = filepath.Abs(tempDir)
+		if err != nil {
+			return err
+		}
+		outDir = filepath.Join(tempDir, "out")
+		sandboxRoot = filepath.Join(tempDir, "root")
+	}
 
 	for i, filePath := range outputsVarEntries {
 		if !strings.HasPrefix(filePath, "__SBOX_OUT_DIR__/") {
@@ -154,33 +222,19 @@ func run() error {
 		if !strings.Contains(rawCommand, "__SBOX_DEPFILE__") {
 			return fmt.Errorf("the --depfile-out argument only makes sense if the command contains the text __SBOX_DEPFILE__")
 		}
-		rawCommand = strings.Replace(rawCommand, "__SBOX_DEPFILE__", filepath.Join(tempDir, sandboxedDepfile), -1)
+		rawCommand = strings.Replace(rawCommand, "__SBOX_DEPFILE__", filepath.Join(outDir, sandboxedDepfile), -1)
 
 	}
 
-	if err != nil {
-		return fmt.Errorf("Failed to create temp dir: %s", err)
-	}
-
-	// In the common case, the following line of code is what removes the sandbox
-	// If a fatal error occurs (such as if our Go process is killed unexpectedly),
-	// then at the beginning of the next build, Soong will retry the cleanup
-	defer func() {
-		// in some cases we decline to remove the temp dir, to facilitate debugging
-		if !keepOutDir {
-			os.RemoveAll(tempDir)
-		}
-	}()
-
 	if strings.Contains(rawCommand, "__SBOX_OUT_DIR__") {
-		rawCommand = strings.Replace(rawCommand, "__SBOX_OUT_DIR__", tempDir, -1)
+		rawCommand = strings.Replace(rawCommand, "__SBOX_OUT_DIR__", outDir, -1)
 	}
 
 	if strings.Contains(rawCommand, "__SBOX_OUT_FILES__") {
 		// expands into a space-separated list of output files to be generated into the sandbox directory
 		tempOutPaths := []string{}
 		for _, outputPath := range outputsVarEntries {
-			tempOutPath := path.Join(tempDir, outputPath)
+			tempOutPath := path.Join(outDir, outputPath)
 			tempOutPaths = append(tempOutPaths, tempOutPath)
 		}
 		pathsText := strings.Join(tempOutPaths, " ")
@@ -188,16 +242,31 @@ func run() error {
 	}
 
 	for _, filePath := range allOutputs {
-		dir := path.Join(tempDir, filepath.Dir(filePath))
+		dir := path.Join(outDir, filepath.Dir(filePath))
 		err = os.MkdirAll(dir, 0777)
 		if err != nil {
 			return err
 		}
 	}
 
+	var staged map[string]bool
+	if sandboxRoot != "" {
+		staged, err = stageInputs(inputs, sandboxRoot)
+		if err != nil {
+			return err
+		}
+
+		// Reading a file that was not staged will fail, point the rule author at the likely culprits.
+		for _, undeclared := range undeclaredInputs(rawCommand, sandboxRoot) {
+			fmt.Fprintf(os.Stderr, "sbox: %s is referenced by the command but is not a declared input\n",
+				undeclared)
+		}
+	}
+
 	commandDescription := rawCommand
 
 	cmd := exec.Command("bash", "-c", rawCommand)
+	cmd.Dir = sandboxRoot
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -212,7 +281,7 @@ func run() error {
 	// validate that all files are created properly
 	var missingOutputErrors []string
 	for _, filePath := range allOutputs {
-		tempPath := filepath.Join(tempDir, filePath)
+		tempPath := filepath.Join(outDir, filePath)
 		fileInfo, err := os.Stat(tempPath)
 		if err != nil {
 			missingOutputErrors = append(missingOutputErrors, fmt.Sprintf("%s: does not exist", filePath))
@@ -224,12 +293,12 @@ func run() error {
 	}
 	if len(missingOutputErrors) > 0 {
 		// find all created files for making a more informative error message
-		createdFiles := findAllFilesUnder(tempDir)
+		createdFiles := findAllFilesUnder(outDir)
 
 		// build error message
 		errorMessage := "mismatch between declared and actual outputs\n"
 		errorMessage += "in sbox command(" + commandDescription + ")\n\n"
-		errorMessage += "in sandbox " + tempDir + ",\n"
+		errorMessage += "in sandbox " + outDir + ",\n"
 		errorMessage += fmt.Sprintf("failed to create %v files:\n", len(missingOutputErrors))
 		for _, missingOutputError := range missingOutputErrors {
 			errorMessage += "  " + missingOutputError + "\n"
@@ -254,9 +323,22 @@ func run() error {
 		keepOutDir = true
 		return errors.New(errorMessage)
 	}
+
+	if sandboxRoot != "" {
+		undeclared, err := undeclaredOutputs(outDir, allOutputs, sandboxRoot, staged)
+		if err != nil {
+			return err
+		}
+		if len(undeclared) > 0 {
+			keepOutDir = true
+			return fmt.Errorf("sbox command (%s) wrote undeclared outputs in sandbox %s:\n  %s",
+				commandDescription, tempDir, strings.Join(undeclared, "\n  "))
+		}
+	}
+
 	// the created files match the declared files; now move them
 	for _, filePath := range allOutputs {
-		tempPath := filepath.Join(tempDir, filePath)
+		tempPath := filepath.Join(outDir, filePath)
 		destPath := filePath
 		if len(outputRoot) != 0 {
 			destPath = filepath.Join(outputRoot, filePath)
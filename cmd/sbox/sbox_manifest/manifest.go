@@ -0,0 +1,83 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sbox_manifest contains the manifest that describes the inputs and outputs of a command run
+// by sbox.  It is shared between sbox and the modules that write manifests for it.
+package sbox_manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Manifest lists the files that sbox stages into the sandbox before running a command and the files
+// that it copies out of the sandbox afterwards.  Paths of inputs and tools are relative to the
+// directory sbox is run in, and are staged at the same relative path in the sandbox.
+type Manifest struct {
+	// Inputs are the files or directories read by the command.
+	Inputs []string `json:"inputs,omitempty"`
+
+	// Tools are the files or directories executed by the command.  They are staged the same way as
+	// Inputs.
+	Tools []string `json:"tools,omitempty"`
+
+	// OutputRoot is the directory that the outputs are copied into.  It is deleted before the command
+	// is run.
+	OutputRoot string `json:"output_root"`
+
+	// Outputs are the files written by the command, relative to OutputRoot.
+	Outputs []string `json:"outputs"`
+
+	// Depfile is the depfile written by the command, if any.  It must be in OutputRoot.
+	Depfile string `json:"depfile,omitempty"`
+}
+
+// Parse parses and validates a JSON encoded Manifest.
+func Parse(data []byte) (*Manifest, error) {
+	m := &Manifest{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(m); err != nil {
+		return nil, fmt.Errorf("failed to parse sbox manifest: %s", err)
+	}
+
+	if m.OutputRoot == "" {
+		return nil, fmt.Errorf("sbox manifest is missing output_root")
+	}
+	if len(m.Outputs) == 0 {
+		return nil, fmt.Errorf("sbox manifest must list at least one output")
+	}
+	for _, output := range m.Outputs {
+		if filepath.IsAbs(output) || output == ".." || strings.HasPrefix(filepath.Clean(output), "../") {
+			return nil, fmt.Errorf("sbox manifest output %q is not relative to output_root", output)
+		}
+	}
+
+	return m, nil
+}
+
+// String returns the JSON encoding of the Manifest on a single line.
+func (m *Manifest) String() string {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(m); err != nil {
+		panic(err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
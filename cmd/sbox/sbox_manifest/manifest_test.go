@@ -0,0 +1,115 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbox_manifest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		in       string
+		expected *Manifest
+		err      string
+	}{
+		{
+			name: "full",
+			in: `{"inputs": ["a", "b/c"], "tools": ["out/host/bin/tool"], "output_root": "out/gen",
+				"outputs": ["x", "y/z"], "depfile": "out/gen/x.d"}`,
+			expected: &Manifest{
+				Inputs:     []string{"a", "b/c"},
+				Tools:      []string{"out/host/bin/tool"},
+				OutputRoot: "out/gen",
+				Outputs:    []string{"x", "y/z"},
+				Depfile:    "out/gen/x.d",
+			},
+		},
+		{
+			name: "minimal",
+			in:   `{"output_root": "out/gen", "outputs": ["x"]}`,
+			expected: &Manifest{
+				OutputRoot: "out/gen",
+				Outputs:    []string{"x"},
+			},
+		},
+		{
+			name: "missing output_root",
+			in:   `{"outputs": ["x"]}`,
+			err:  "missing output_root",
+		},
+		{
+			name: "missing outputs",
+			in:   `{"output_root": "out/gen"}`,
+			err:  "at least one output",
+		},
+		{
+			name: "absolute output",
+			in:   `{"output_root": "out/gen", "outputs": ["/x"]}`,
+			err:  `output "/x" is not relative to output_root`,
+		},
+		{
+			name: "output outside output_root",
+			in:   `{"output_root": "out/gen", "outputs": ["a/../../x"]}`,
+			err:  `output "a/../../x" is not relative to output_root`,
+		},
+		{
+			name: "unknown field",
+			in:   `{"output_root": "out/gen", "outputs": ["x"], "command": "true"}`,
+			err:  `unknown field "command"`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			m, err := Parse([]byte(testCase.in))
+			if testCase.err != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.err) {
+					t.Errorf("expected error containing %q, got %v", testCase.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m, testCase.expected) {
+				t.Errorf("expected %#v, got %#v", testCase.expected, m)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	m := &Manifest{
+		Inputs:     []string{"a<b"},
+		OutputRoot: "out/gen",
+		Outputs:    []string{"x"},
+	}
+
+	s := m.String()
+	if strings.Contains(s, "\n") {
+		t.Errorf("expected a single line, got %q", s)
+	}
+
+	parsed, err := Parse([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, m) {
+		t.Errorf("expected %#v, got %#v", m, parsed)
+	}
+}
@@ -17,8 +17,10 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/blueprint/bootstrap"
 
@@ -26,11 +28,13 @@ import (
 )
 
 var (
-	docFile string
+	docFile         string
+	moduleGraphFile string
 )
 
 func init() {
 	flag.StringVar(&docFile, "soong_docs", "", "build documentation file to output")
+	flag.StringVar(&moduleGraphFile, "module_graph_file", "", "JSON module graph file to output")
 }
 
 func newNameResolver(config android.Config) *android.NameResolver {
@@ -49,12 +53,49 @@ func newNameResolver(config android.Config) *android.NameResolver {
 	return android.NewNameResolver(exportFilter)
 }
 
+// registerSoongConfigModuleTypes registers the module types defined by soong_config_module_type
+// modules in the Blueprints files listed in the module list file passed to bootstrap with -l.  It
+// must be called before the module types are registered with the Context.
+func registerSoongConfigModuleTypes() error {
+	moduleListFlag := flag.Lookup("l")
+	if moduleListFlag == nil || moduleListFlag.Value.String() == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(moduleListFlag.Value.String())
+	if err != nil {
+		return fmt.Errorf("couldn't read module list: %s", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+
+	if errs := android.RegisterSoongConfigModuleTypes(files); len(errs) > 0 {
+		var msgs []string
+		for _, err := range errs {
+			msgs = append(msgs, err.Error())
+		}
+		return fmt.Errorf("%s", strings.Join(msgs, "\n"))
+	}
+
+	return nil
+}
+
 func main() {
 	flag.Parse()
 
 	// The top-level Blueprints file is passed as the first argument.
 	srcDir := filepath.Dir(flag.Arg(0))
 
+	if err := registerSoongConfigModuleTypes(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
 	ctx := android.NewContext()
 	ctx.Register()
 
@@ -68,6 +109,16 @@ func main() {
 		configuration.SetStopBefore(bootstrap.StopBeforePrepareBuildActions)
 	}
 
+	if moduleGraphFile != "" {
+		configuration.SetModuleGraphFile(moduleGraphFile)
+	}
+
+	// soong_ui writes the list of neverallow.bp policy files next to the module list.
+	if moduleListFlag := flag.Lookup("l"); moduleListFlag != nil && moduleListFlag.Value.String() != "" {
+		configuration.SetNeverallowPolicyList(
+			filepath.Join(filepath.Dir(moduleListFlag.Value.String()), "neverallow.bp.list"))
+	}
+
 	ctx.SetNameInterface(newNameResolver(configuration))
 
 	ctx.SetAllowMissingDependencies(configuration.AllowMissingDependencies())
@@ -80,4 +131,19 @@ func main() {
 			os.Exit(1)
 		}
 	}
+
+	if err := android.WriteModuleGraph(ctx, configuration); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+
+	// Only record the metrics of the soong_build run that generates the build manifest.  soong_ui
+	// removes the metrics file before running it, and folds the new one into the build metrics.
+	if docFile == "" && moduleGraphFile == "" {
+		metricsFile := filepath.Join(bootstrap.BuildDir, "soong_build_metrics.pb")
+		if err := android.WriteMetrics(ctx, metricsFile); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+	}
 }
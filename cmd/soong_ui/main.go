@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -32,6 +33,13 @@ import (
 	"android/soong/ui/tracer"
 )
 
+// The default number of running actions to list below the status line.
+const defaultStatusTableHeight = 5
+
+// The number of the longest actions on the critical path to print at the end
+// of the build.
+const criticalPathPrintEntries = 10
+
 func indexList(s string, list []string) int {
 	for i, l := range list {
 		if l == s {
@@ -50,8 +58,8 @@ func main() {
 	var stdio terminal.StdioInterface
 	stdio = terminal.StdioImpl{}
 
-	// dumpvar uses stdout, everything else should be in stderr
-	if os.Args[1] == "--dumpvar-mode" || os.Args[1] == "--dumpvars-mode" {
+	// dumpvar and query use stdout, everything else should be in stderr
+	if os.Args[1] == "--dumpvar-mode" || os.Args[1] == "--dumpvars-mode" || os.Args[1] == "--query" {
 		stdio = terminal.NewCustomStdio(os.Stdin, os.Stderr, os.Stderr)
 	}
 
@@ -63,7 +71,8 @@ func main() {
 
 	if len(os.Args) < 2 || !(inList("--make-mode", os.Args) ||
 		os.Args[1] == "--dumpvars-mode" ||
-		os.Args[1] == "--dumpvar-mode") {
+		os.Args[1] == "--dumpvar-mode" ||
+		os.Args[1] == "--query") {
 
 		log.Fatalln("The `soong` native UI is not yet available.")
 	}
@@ -79,8 +88,9 @@ func main() {
 	stat := &status.Status{}
 	defer stat.Finish()
 	stat.AddOutput(terminal.NewStatusOutput(writer, os.Getenv("NINJA_STATUS"),
-		build.OsEnvironment().IsEnvTrue("ANDROID_QUIET_BUILD")))
+		build.OsEnvironment().IsEnvTrue("ANDROID_QUIET_BUILD"), statusTableHeight()))
 	stat.AddOutput(trace.StatusTracer())
+	stat.AddOutput(met.StatusOutput())
 
 	build.SetupSignals(log, cancel, func() {
 		trace.Close()
@@ -97,7 +107,7 @@ func main() {
 		Status:  stat,
 	}}
 	var config build.Config
-	if os.Args[1] == "--dumpvars-mode" || os.Args[1] == "--dumpvar-mode" {
+	if os.Args[1] == "--dumpvars-mode" || os.Args[1] == "--dumpvar-mode" || os.Args[1] == "--query" {
 		config = build.NewConfig(buildCtx)
 	} else {
 		config = build.NewConfig(buildCtx, os.Args[1:]...)
@@ -115,9 +125,17 @@ func main() {
 	trace.SetOutput(filepath.Join(logsDir, "build.trace"))
 	stat.AddOutput(status.NewVerboseLog(log, filepath.Join(logsDir, "verbose.log")))
 	stat.AddOutput(status.NewErrorLog(log, filepath.Join(logsDir, "error.log")))
+	if stream := config.BuildEventStream(); stream != "" {
+		stat.AddOutput(status.NewBuildEventStream(log, stream))
+	}
 
 	defer met.Dump(filepath.Join(logsDir, "build_metrics"))
 
+	// Deferred after the metrics are dumped, so that it runs before them.
+	criticalPath := status.NewCriticalPath()
+	stat.AddOutput(criticalPath)
+	defer reportCriticalPath(log, writer, met, trace, criticalPath)
+
 	if start, ok := os.LookupEnv("TRACE_BEGIN_SOONG"); ok {
 		if !strings.HasSuffix(start, "N") {
 			if start_time, err := strconv.ParseUint(start, 10, 64); err == nil {
@@ -140,6 +158,8 @@ func main() {
 		dumpVar(buildCtx, config, os.Args[2:])
 	} else if os.Args[1] == "--dumpvars-mode" {
 		dumpVars(buildCtx, config, os.Args[2:])
+	} else if os.Args[1] == "--query" {
+		query(buildCtx, config, os.Args[2:])
 	} else {
 		if config.IsVerbose() {
 			writer.Print("! The argument `showcommands` is no longer supported.")
@@ -152,6 +172,8 @@ func main() {
 			time.Sleep(5 * time.Second)
 		}
 
+		build.RecordMakeModuleTypes(buildCtx, f)
+
 		toBuild := build.BuildAll
 		if config.Checkbuild() {
 			toBuild |= build.RunBuildTests
@@ -277,3 +299,130 @@ func dumpVars(ctx build.Context, config build.Config, args []string) {
 		fmt.Printf("%s%s='%s'\n", *absVarPrefix, name, strings.Join(res, " "))
 	}
 }
+
+func query(ctx build.Context, config build.Config, args []string) {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s --query [flags] rdeps <MODULE>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s --query [flags] somepath <FROM> <TO>\n\n", os.Args[0])
+		fmt.Fprintln(os.Stderr, "In query mode, run Soong and print dependency paths between module variants to")
+		fmt.Fprintln(os.Stderr, "stdout.")
+		fmt.Fprintln(os.Stderr, "")
+
+		fmt.Fprintln(os.Stderr, "'rdeps' prints a path from every module variant that depends on MODULE, directly")
+		fmt.Fprintln(os.Stderr, "or transitively, to MODULE. 'somepath' prints one of the shortest paths from FROM")
+		fmt.Fprintln(os.Stderr, "to TO.")
+		fmt.Fprintln(os.Stderr, "")
+		flags.PrintDefaults()
+	}
+
+	var filter build.QueryFilter
+	flags.StringVar(&filter.Tag, "tag", "", "Only follow dependencies whose tag type contains this string")
+	flags.StringVar(&filter.Os, "os", "", "Only start and end paths at variants for this os, e.g. android")
+	flags.StringVar(&filter.Arch, "arch", "", "Only start and end paths at variants for this arch, e.g. arm64")
+	depth := flags.Int("depth", 0, "Maximum depth of reverse dependencies for rdeps, 0 for unlimited")
+
+	flags.Parse(args)
+
+	switch {
+	case flags.NArg() == 2 && flags.Arg(0) == "rdeps":
+	case flags.NArg() == 3 && flags.Arg(0) == "somepath":
+	default:
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	graph, err := build.LoadModuleGraph(build.DumpModuleGraph(ctx, config))
+	if err != nil {
+		ctx.Fatal(err)
+	}
+
+	switch flags.Arg(0) {
+	case "rdeps":
+		module := flags.Arg(1)
+		if len(graph.Variants(module, filter)) == 0 {
+			ctx.Fatalf("No variants of module %q match the query", module)
+		}
+		for _, path := range graph.ReverseDeps(module, filter, *depth) {
+			fmt.Println(path)
+		}
+	case "somepath":
+		from, to := flags.Arg(1), flags.Arg(2)
+		if len(graph.Variants(from, filter)) == 0 {
+			ctx.Fatalf("No variants of module %q match the query", from)
+		}
+		if path, ok := graph.SomePath(from, to, filter); ok {
+			fmt.Println(path)
+		} else {
+			fmt.Fprintf(os.Stderr, "No path from %s to %s\n", from, to)
+			os.Exit(1)
+		}
+	}
+}
+
+// reportCriticalPath prints the longest actions on the critical path of the
+// build, logs all of them, and records the critical path in the metrics and
+// the trace.
+func reportCriticalPath(log logger.Logger, writer terminal.Writer, met *metrics.Metrics,
+	trace tracer.Tracer, criticalPath *status.CriticalPath) {
+
+	path := criticalPath.Path()
+	if len(path) == 0 {
+		return
+	}
+	elapsed := criticalPath.Elapsed()
+
+	met.SetCriticalPath(path, elapsed)
+	trace.CriticalPath(path)
+
+	round := func(d time.Duration) time.Duration {
+		return d.Round(100 * time.Millisecond)
+	}
+	line := func(entry status.CriticalPathEntry) string {
+		desc := entry.Action.Description
+		if desc == "" {
+			desc = entry.Action.Command
+		}
+		return fmt.Sprintf("%10s %10s  %s", round(entry.Duration), round(entry.Cumulative), desc)
+	}
+
+	header := fmt.Sprintf("Critical path took %s of %s elapsed:",
+		round(path[len(path)-1].Cumulative), round(elapsed))
+	columns := fmt.Sprintf("%10s %10s  %s", "duration", "cumulative", "action")
+
+	log.Verbose(header)
+	log.Verbose(columns)
+	for _, entry := range path {
+		log.Verbose(line(entry))
+	}
+
+	// Only print the longest actions, in the order they ran.
+	longest := make([]int, len(path))
+	for i := range longest {
+		longest[i] = i
+	}
+	sort.SliceStable(longest, func(i, j int) bool {
+		return path[longest[i]].Duration > path[longest[j]].Duration
+	})
+	if len(longest) > criticalPathPrintEntries {
+		longest = longest[:criticalPathPrintEntries]
+	}
+	sort.Ints(longest)
+
+	writer.Print(header)
+	writer.Print(columns)
+	for _, i := range longest {
+		writer.Print(line(path[i]))
+	}
+}
+
+// statusTableHeight returns the number of running actions to list below the
+// status line, from SOONG_UI_TABLE_HEIGHT.  A height of 0 disables the table.
+func statusTableHeight() int {
+	if h, ok := os.LookupEnv("SOONG_UI_TABLE_HEIGHT"); ok {
+		if height, err := strconv.Atoi(h); err == nil && height >= 0 {
+			return height
+		}
+	}
+	return defaultStatusTableHeight
+}
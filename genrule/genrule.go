@@ -23,6 +23,7 @@ import (
 	"github.com/google/blueprint/proptools"
 
 	"android/soong/android"
+	"android/soong/cmd/sbox/sbox_manifest"
 	"android/soong/shared"
 	"path/filepath"
 )
@@ -93,6 +94,11 @@ type generatorProperties struct {
 
 	// input files to exclude
 	Exclude_srcs []string `android:"path,arch_variant"`
+
+	// Run the command in a sandbox that contains only the srcs, tools, tool_files and the shared libraries
+	// the tools load at runtime, and fail if it writes any files other than the outputs.  Can't be used with
+	// depfile.
+	Sandbox *bool
 }
 
 type Module struct {
@@ -224,6 +230,22 @@ func (g *Module) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 		return
 	}
 
+	if len(g.properties.Tools) > 0 && (Bool(g.properties.Sandbox) || ctx.Config().UseRemoteExec()) {
+		// Host tools may load files installed by their dependencies at runtime, e.g. shared libraries
+		// from lib64/ through $ORIGIN.  Stage them into the sandbox or the remote execution root.  Other
+		// genrules run the tools from their install paths, which already depend on these files.
+		var runtimeDeps android.Paths
+		ctx.WalkDeps(func(child, parent android.Module) bool {
+			if parent == ctx.Module() {
+				_, isTool := ctx.OtherModuleDependencyTag(child).(hostToolDependencyTag)
+				return isTool
+			}
+			runtimeDeps = append(runtimeDeps, child.FilesToInstall()...)
+			return true
+		})
+		g.deps = append(g.deps, android.FirstUniquePaths(runtimeDeps)...)
+	}
+
 	for _, toolFile := range g.properties.Tool_files {
 		paths := ctx.ExpandSources([]string{toolFile}, nil)
 		g.deps = append(g.deps, paths...)
@@ -317,40 +339,77 @@ func (g *Module) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 		ctx.PropertyErrorf("cmd", "specified depfile=true but did not include a reference to '${depfile}' in cmd")
 	}
 
+	sandbox := Bool(g.properties.Sandbox)
+	if sandbox && Bool(g.properties.Depfile) {
+		// Tools that write depfiles usually find some of their inputs at runtime, e.g. included headers,
+		// which would not be staged into the sandbox.
+		ctx.PropertyErrorf("sandbox", "can't be used with depfile")
+	}
+
 	// tell the sbox command which directory to use as its sandbox root
 	buildDir := android.PathForOutput(ctx).String()
 	sandboxPath := shared.TempDirForOutDir(buildDir)
 
-	// recall that Sprintf replaces percent sign expressions, whereas dollar signs expressions remain as written,
-	// to be replaced later by ninja_strings.go
-	depfilePlaceholder := ""
-	if Bool(g.properties.Depfile) {
-		depfilePlaceholder = "$depfileArgs"
-	}
-
-	genDir := android.PathForModuleGen(ctx)
 	// Escape the command for the shell
 	rawCommand = "'" + strings.Replace(rawCommand, "'", `'\''`, -1) + "'"
 	g.rawCommand = rawCommand
-	sandboxCommand := fmt.Sprintf("$sboxCmd --sandbox-path %s --output-root %s -c %s %s $allouts",
-		sandboxPath, genDir, rawCommand, depfilePlaceholder)
+
+	// recall that Sprintf replaces percent sign expressions, whereas dollar signs expressions remain as written,
+	// to be replaced later by ninja_strings.go
+	var sandboxCommand string
+	var manifestPath android.WritablePath
+	args := []string{"allouts"}
+	if sandbox {
+		// sbox stages only the inputs and tools listed in the manifest into the sandbox, and copies only the
+		// outputs listed in the manifest out of it
+		manifestPath = android.PathForModuleOut(ctx, "genrule.sbox.json")
+		sandboxCommand = fmt.Sprintf("$sboxCmd --sandbox-path %s --manifest %s -c %s",
+			sandboxPath, manifestPath, rawCommand)
+		args = nil
+	} else {
+		depfilePlaceholder := ""
+		if Bool(g.properties.Depfile) {
+			depfilePlaceholder = "$depfileArgs"
+			args = append(args, "depfileArgs")
+		}
+
+		genDir := android.PathForModuleGen(ctx)
+		sandboxCommand = fmt.Sprintf("$sboxCmd --sandbox-path %s --output-root %s -c %s %s $allouts",
+			sandboxPath, genDir, rawCommand, depfilePlaceholder)
+	}
 
 	ruleParams := blueprint.RuleParams{
 		Command:     sandboxCommand,
 		CommandDeps: []string{"$sboxCmd"},
 	}
-	args := []string{"allouts"}
 	if Bool(g.properties.Depfile) {
 		ruleParams.Deps = blueprint.DepsGCC
-		args = append(args, "depfileArgs")
+	}
+	if ctx.Config().UseRemoteExec() && len(task.out) > 0 {
+		outputs := task.out.Strings()
+		if Bool(g.properties.Depfile) {
+			outputs = append(outputs, android.PathForModuleGen(ctx, task.out[0].Rel()+".d").String())
+		}
+		// The remote execution client needs the inputs, the implicit dependencies, the sbox manifest if any
+		// and sbox to stage them.
+		deps := android.StagedInputs(g.deps.Strings())
+		if manifestPath != nil {
+			deps = append(deps, manifestPath.String())
+		}
+		rspfile := task.out[0].String() + ".rsp"
+		ruleParams.Command = android.RemoteExecCommand(ctx, sandboxCommand, outputs, proptools.NinjaEscape(rspfile))
+		ruleParams.CommandDeps = append(ruleParams.CommandDeps, android.RemoteExecTool(ctx).String())
+		ruleParams.Rspfile = rspfile
+		ruleParams.RspfileContent = strings.Join(append([]string{"$in"},
+			append(proptools.NinjaEscapeList(deps), "$sboxCmd")...), " ")
 	}
 	g.rule = ctx.Rule(pctx, "generator", ruleParams, args...)
 
-	g.generateSourceFile(ctx, task)
+	g.generateSourceFile(ctx, task, manifestPath)
 
 }
 
-func (g *Module) generateSourceFile(ctx android.ModuleContext, task generateTask) {
+func (g *Module) generateSourceFile(ctx android.ModuleContext, task generateTask, manifestPath android.WritablePath) {
 	desc := "generate"
 	if len(task.out) == 0 {
 		ctx.ModuleErrorf("must have at least one output file")
@@ -372,13 +431,36 @@ func (g *Module) generateSourceFile(ctx android.ModuleContext, task generateTask
 		ImplicitOutputs: task.out[1:],
 		Inputs:          task.in,
 		Implicits:       g.deps,
-		Args: map[string]string{
-			"allouts": strings.Join(task.sandboxOuts, " "),
-		},
 	}
-	if Bool(g.properties.Depfile) {
-		params.Depfile = android.PathForModuleGen(ctx, task.out[0].Rel()+".d")
-		params.Args["depfileArgs"] = "--depfile-out " + depFile.String()
+
+	if manifestPath != nil {
+		manifest := &sbox_manifest.Manifest{
+			Inputs:     task.in.Strings(),
+			Tools:      g.deps.Strings(),
+			OutputRoot: android.PathForModuleGen(ctx).String(),
+		}
+		for _, sandboxOut := range task.sandboxOuts {
+			manifest.Outputs = append(manifest.Outputs, strings.TrimPrefix(sandboxOut, "__SBOX_OUT_DIR__/"))
+		}
+
+		ctx.Build(pctx, android.BuildParams{
+			Rule:        android.WriteFile,
+			Description: "sbox manifest",
+			Output:      manifestPath,
+			Args: map[string]string{
+				"content": android.WriteFileContent(manifest.String()),
+			},
+		})
+
+		params.Implicits = append(android.Paths{manifestPath}, g.deps...)
+	} else {
+		params.Args = map[string]string{
+			"allouts": strings.Join(task.sandboxOuts, " "),
+		}
+		if Bool(g.properties.Depfile) {
+			params.Depfile = depFile
+			params.Args["depfileArgs"] = "--depfile-out " + depFile.String()
+		}
 	}
 
 	ctx.Build(pctx, params)
@@ -17,10 +17,14 @@ package genrule
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/google/blueprint"
+
 	"android/soong/android"
+	"android/soong/cmd/sbox/sbox_manifest"
 	"reflect"
 )
 
@@ -418,6 +422,16 @@ func TestGenruleCmd(t *testing.T) {
 			`,
 			err: "specified depfile=true but did not include a reference to '${depfile}' in cmd",
 		},
+		{
+			name: "error sandbox depfile",
+			prop: `
+				out: ["out"],
+				depfile: true,
+				sandbox: true,
+				cmd: "echo foo > $(out) && touch $(depfile)",
+			`,
+			err: "can't be used with depfile",
+		},
 		{
 			name: "error no out",
 			prop: `
@@ -508,19 +522,212 @@ func TestGenruleDefaults(t *testing.T) {
 	}
 }
 
+func TestGenruleSboxManifest(t *testing.T) {
+	config := android.TestArchConfig(buildDir, nil)
+	bp := `
+				genrule {
+					name: "gen",
+					tools: ["tool"],
+					tool_files: [":1tool_file"],
+					srcs: [":ins"],
+					out: ["out", "dir/out2"],
+					sandbox: true,
+					cmd: "$(location tool) $(in) $(out)",
+				}
+
+				genrule {
+					name: "gen_legacy",
+					tools: ["tool"],
+					srcs: [":ins"],
+					out: ["out"],
+					depfile: true,
+					cmd: "$(location tool) $(in) $(out) $(depfile)",
+				}
+			`
+	ctx := testContext(config, bp, nil)
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	if errs == nil {
+		_, errs = ctx.PrepareBuildActions(config)
+	}
+	if errs != nil {
+		t.Fatal(errs)
+	}
+
+	gen := ctx.ModuleForTests("gen", "")
+	manifestParams := gen.Output("genrule.sbox.json")
+	manifest, err := sbox_manifest.Parse([]byte(manifestParams.Args["content"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genDir := filepath.Join(buildDir, ".intermediates", "gen", "gen")
+
+	expected := &sbox_manifest.Manifest{
+		Inputs:     []string{"in1", "in2"},
+		Tools:      []string{"out/tool", "tool_file1"},
+		OutputRoot: genDir,
+		Outputs:    []string{"out", "dir/out2"},
+	}
+	if !reflect.DeepEqual(manifest, expected) {
+		t.Errorf("expected manifest:\n%s\ngot:\n%s", expected, manifest)
+	}
+
+	genParams := gen.Output("out")
+	if !strings.Contains(genParams.RuleParams.Command, " --manifest "+manifestParams.Output.String()+" ") {
+		t.Errorf("expected command %q to use manifest %q", genParams.RuleParams.Command, manifestParams.Output)
+	}
+	if !android.InList(manifestParams.Output.String(), genParams.Implicits.Strings()) {
+		t.Errorf("expected implicits %q to contain manifest %q", genParams.Implicits.Strings(), manifestParams.Output)
+	}
+
+	// Genrules that don't opt in to the sandbox, such as depfile genrules, still run in the output
+	// directory.
+	legacy := ctx.ModuleForTests("gen_legacy", "")
+	if m := legacy.MaybeOutput("genrule.sbox.json"); m.Rule != nil {
+		t.Errorf("expected no sbox manifest for gen_legacy")
+	}
+	legacyParams := legacy.Output("out")
+	if strings.Contains(legacyParams.RuleParams.Command, "--manifest") {
+		t.Errorf("expected command %q not to use a manifest", legacyParams.RuleParams.Command)
+	}
+	if g, w := legacyParams.Args["allouts"], "__SBOX_OUT_DIR__/out"; g != w {
+		t.Errorf("expected allouts %q, got %q", w, g)
+	}
+	if !strings.HasPrefix(legacyParams.Args["depfileArgs"], "--depfile-out ") {
+		t.Errorf("expected depfile args, got %q", legacyParams.Args["depfileArgs"])
+	}
+}
+
+func TestGenruleSboxManifestEscaping(t *testing.T) {
+	config := android.TestArchConfig(buildDir, nil)
+	bp := `
+				genrule {
+					name: "gen",
+					out: ["it's\nout"],
+					sandbox: true,
+					cmd: "echo > $(out)",
+				}
+			`
+	ctx := testContext(config, bp, nil)
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	if errs == nil {
+		_, errs = ctx.PrepareBuildActions(config)
+	}
+	if errs != nil {
+		t.Fatal(errs)
+	}
+
+	content := ctx.ModuleForTests("gen", "").Output("genrule.sbox.json").Args["content"]
+	if strings.Contains(content, "\n") {
+		t.Errorf("expected the WriteFile content not to contain a newline, got %q", content)
+	}
+
+	// Undo the escaping for the single quoted shell string and echo -e done by the WriteFile rule.
+	content = strings.NewReplacer(`'\''`, `'`, `\\`, `\`, `\n`, "\n").Replace(content)
+	manifest, err := sbox_manifest.Parse([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := manifest.Outputs, []string{"it's\nout"}; !reflect.DeepEqual(g, w) {
+		t.Errorf("expected outputs %q, got %q", w, g)
+	}
+}
+
+func TestGenruleToolRuntimeDeps(t *testing.T) {
+	config := android.TestArchConfig(buildDir, nil)
+	bp := `
+				tool {
+					name: "tool_with_lib",
+					deps: ["libtool"],
+				}
+
+				tool {
+					name: "libtool",
+					installed_lib: true,
+				}
+
+				genrule {
+					name: "gen",
+					tools: ["tool_with_lib"],
+					out: ["out"],
+					sandbox: true,
+					cmd: "$(location) > $(out)",
+				}
+
+				genrule {
+					name: "gen_plain",
+					tools: ["tool_with_lib"],
+					out: ["out"],
+					cmd: "$(location) > $(out)",
+				}
+			`
+	ctx := testContext(config, bp, nil)
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	if errs == nil {
+		_, errs = ctx.PrepareBuildActions(config)
+	}
+	if errs != nil {
+		t.Fatal(errs)
+	}
+
+	gen := ctx.ModuleForTests("gen", "")
+	manifest, err := sbox_manifest.Parse([]byte(gen.Output("genrule.sbox.json").Args["content"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(manifest.Tools) != 2 || manifest.Tools[0] != "out/tool_with_lib" ||
+		!strings.HasSuffix(manifest.Tools[1], "/lib64/libtool.so") {
+		t.Errorf("expected tools to be the tool and its shared library, got %q", manifest.Tools)
+	}
+
+	implicits := gen.Output("out").Implicits.Strings()
+	if len(manifest.Tools) == 2 && !android.InList(manifest.Tools[1], implicits) {
+		t.Errorf("expected implicits %q to contain %q", implicits, manifest.Tools[1])
+	}
+
+	// Genrules that are not sandboxed run the tool from its install path, which already depends on
+	// the shared library.
+	for _, implicit := range ctx.ModuleForTests("gen_plain", "").Output("out").Implicits.Strings() {
+		if strings.HasSuffix(implicit, "/lib64/libtool.so") {
+			t.Errorf("expected no dependency on the shared library of the tool, got %q", implicit)
+		}
+	}
+}
+
 type testTool struct {
 	android.ModuleBase
 	outputFile android.Path
+
+	properties struct {
+		// Modules that the tool needs at runtime.
+		Deps []string
+
+		// Install the tool as a shared library in lib64/.
+		Installed_lib *bool
+	}
+}
+
+type testToolDependencyTag struct {
+	blueprint.BaseDependencyTag
 }
 
 func toolFactory() android.Module {
 	module := &testTool{}
+	module.AddProperties(&module.properties)
 	android.InitAndroidArchModule(module, android.HostSupported, android.MultilibFirst)
 	return module
 }
 
+func (t *testTool) DepsMutator(ctx android.BottomUpMutatorContext) {
+	ctx.AddDependency(ctx.Module(), testToolDependencyTag{}, t.properties.Deps...)
+}
+
 func (t *testTool) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 	t.outputFile = android.PathForTesting("out", ctx.ModuleName())
+	if Bool(t.properties.Installed_lib) {
+		ctx.InstallFile(android.PathForModuleInstall(ctx, "lib64"), ctx.ModuleName()+".so", t.outputFile)
+	}
 }
 
 func (t *testTool) HostToolPath() android.OptionalPath {
@@ -76,6 +76,7 @@ type aapt struct {
 	manifestPath          android.Path
 	proguardOptionsFile   android.Path
 	rroDirs               android.Paths
+	resourceFiles         android.Paths
 	rTxt                  android.Path
 	extraAaptPackagesFile android.Path
 	isLibrary             bool
@@ -210,8 +211,10 @@ func (a *aapt) buildActions(ctx android.ModuleContext, sdkContext sdkContext, ex
 	extraPackages := android.PathForModuleOut(ctx, "extra_packages")
 
 	var compiledResDirs []android.Paths
+	var resourceFiles android.Paths
 	for _, dir := range resDirs {
 		compiledResDirs = append(compiledResDirs, aapt2Compile(ctx, dir.dir, dir.files).Paths())
+		resourceFiles = append(resourceFiles, dir.files...)
 	}
 
 	for i, zip := range resZips {
@@ -256,6 +259,7 @@ func (a *aapt) buildActions(ctx android.ModuleContext, sdkContext sdkContext, ex
 	a.manifestPath = manifestPath
 	a.proguardOptionsFile = proguardOptionsFile
 	a.rroDirs = rroDirs
+	a.resourceFiles = resourceFiles
 	a.extraAaptPackagesFile = extraPackages
 	a.rTxt = rTxt
 }
@@ -357,6 +361,11 @@ func (a *AndroidLibrary) GenerateAndroidBuildActions(ctx android.ModuleContext)
 
 	a.Module.compile(ctx, a.aaptSrcJar)
 
+	a.linter.manifest = a.manifestPath
+	a.linter.resources = a.resourceFiles
+	a.linter.library = true
+	a.linter.lint(ctx)
+
 	a.aarFile = android.PathForModuleOut(ctx, ctx.ModuleName()+".aar")
 	var res android.Paths
 	if a.androidLibraryProperties.BuildAAR {
@@ -391,7 +400,8 @@ func AndroidLibraryFactory() android.Module {
 		&module.Module.dexpreoptProperties,
 		&module.Module.protoProperties,
 		&module.aaptProperties,
-		&module.androidLibraryProperties)
+		&module.androidLibraryProperties,
+		&module.Module.linter.properties)
 
 	module.androidLibraryProperties.BuildAAR = true
 
@@ -204,7 +204,7 @@ func (app *AndroidApp) AndroidMk() android.AndroidMkData {
 		Extra: []android.AndroidMkExtraFunc{
 			func(w io.Writer, outputFile android.Path) {
 				// TODO(jungjw): This, outputting two LOCAL_MODULE lines, works, but is not ideal. Find a better solution.
-				if app.Name() != app.installApkName {
+				if app.appName() != app.installApkName {
 					fmt.Fprintln(w, "# Overridden by PRODUCT_PACKAGE_NAME_OVERRIDES")
 					fmt.Fprintln(w, "LOCAL_MODULE :=", app.installApkName)
 				}
@@ -221,6 +221,11 @@ func (app *AndroidApp) AndroidMk() android.AndroidMkData {
 				if app.bundleFile != nil {
 					fmt.Fprintln(w, "LOCAL_SOONG_BUNDLE :=", app.bundleFile.String())
 				}
+				if app.appBundleFile != nil {
+					// Ship the app bundle with unbundled app builds.
+					fmt.Fprintf(w, "$(call dist-for-goals,apps_only,%s:%s.aab)\n",
+						app.appBundleFile.String(), app.installApkName)
+				}
 				if app.jacocoReportClassesFile != nil {
 					fmt.Fprintln(w, "LOCAL_SOONG_JACOCO_REPORT_CLASSES_JAR :=", app.jacocoReportClassesFile.String())
 				}
@@ -269,15 +274,35 @@ func (app *AndroidApp) AndroidMk() android.AndroidMkData {
 
 func (a *AndroidApp) getOverriddenPackages() []string {
 	var overridden []string
-	if len(a.appProperties.Overrides) > 0 {
-		overridden = append(overridden, a.appProperties.Overrides...)
+	if len(a.overridableAppProperties.Overrides) > 0 {
+		overridden = append(overridden, a.overridableAppProperties.Overrides...)
 	}
-	if a.Name() != a.installApkName {
-		overridden = append(overridden, a.Name())
+	if a.appName() != a.installApkName {
+		overridden = append(overridden, a.appName())
 	}
 	return overridden
 }
 
+func (as *AndroidAppSet) AndroidMk() android.AndroidMkData {
+	return android.AndroidMkData{
+		Class:      "APPS",
+		OutputFile: android.OptionalPathForPath(as.outputFile),
+		Extra: []android.AndroidMkExtraFunc{
+			func(w io.Writer, outputFile android.Path) {
+				fmt.Fprintln(w, "LOCAL_CERTIFICATE := PRESIGNED")
+				fmt.Fprintln(w, "LOCAL_DEX_PREOPT := false")
+				if as.Privileged() {
+					fmt.Fprintln(w, "LOCAL_PRIVILEGED_MODULE := true")
+				}
+				fmt.Fprintln(w, "LOCAL_ADDITIONAL_DEPENDENCIES :=", as.splitsFile.String())
+				// Install the split APKs selected from the set next to the main APK.
+				fmt.Fprintln(w, "LOCAL_POST_INSTALL_CMD := unzip -qo", as.splitsFile.String(),
+					"-d $(dir $(LOCAL_INSTALLED_MODULE))")
+			},
+		},
+	}
+}
+
 func (a *AndroidTest) AndroidMk() android.AndroidMkData {
 	data := a.AndroidApp.AndroidMk()
 	data.Extra = append(data.Extra, func(w io.Writer, outputFile android.Path) {
@@ -33,16 +33,13 @@ func init() {
 	android.RegisterModuleType("android_test", AndroidTestFactory)
 	android.RegisterModuleType("android_test_helper_app", AndroidTestHelperAppFactory)
 	android.RegisterModuleType("android_app_certificate", AndroidAppCertificateFactory)
+	android.RegisterModuleType("override_android_app", OverrideAndroidAppModuleFactory)
 }
 
 // AndroidManifest.xml merging
 // package splits
 
 type appProperties struct {
-	// The name of a certificate in the default certificate directory, blank to use the default product certificate,
-	// or an android_app_certificate module name in the form ":module".
-	Certificate *string
-
 	// Names of extra android_app_certificate modules to sign the apk with in the form ":module".
 	Additional_certificates []string
 
@@ -58,13 +55,6 @@ type appProperties struct {
 	// list of resource labels to generate individual resource packages
 	Package_splits []string
 
-	// Names of modules to be overridden. Listed modules can only be other binaries
-	// (in Make or Soong).
-	// This does not completely prevent installation of the overridden binaries, but if both
-	// binaries would be installed by default (in PRODUCT_PACKAGES) the other binary will be removed
-	// from PRODUCT_PACKAGES.
-	Overrides []string
-
 	// list of native libraries that will be provided in or alongside the resulting jar
 	Jni_libs []string `android:"arch_variant"`
 
@@ -77,19 +67,44 @@ type appProperties struct {
 	// Store dex files uncompressed in the APK and set the android:useEmbeddedDex="true" manifest attribute so that
 	// they are used from inside the APK at runtime.
 	Use_embedded_dex *bool
+
+	// If set, also build an Android App Bundle `.aab` file from the app and dist it with unbundled
+	// app builds.  Ignored for test apps.
+	Build_app_bundle *bool
+}
+
+// android_app properties that can be overridden by override_android_app
+type overridableAppProperties struct {
+	// The name of a certificate in the default certificate directory, blank to use the default product certificate,
+	// or an android_app_certificate module name in the form ":module".
+	Certificate *string
+
+	// the package name of this app. The package name in the manifest file is used if one was not given.
+	Package_name *string
+
+	// Names of modules to be overridden. Listed modules can only be other binaries
+	// (in Make or Soong).
+	// This does not completely prevent installation of the overridden binaries, but if both
+	// binaries would be installed by default (in PRODUCT_PACKAGES) the other binary will be removed
+	// from PRODUCT_PACKAGES.
+	Overrides []string
 }
 
 type AndroidApp struct {
 	Library
 	aapt
+	android.OverridableModuleBase
 
 	certificate Certificate
 
 	appProperties appProperties
 
+	overridableAppProperties overridableAppProperties
+
 	installJniLibs []jniLib
 
-	bundleFile android.Path
+	bundleFile    android.Path
+	appBundleFile android.Path
 
 	// the install APK name is normally the same as the module name, but can be overridden with PRODUCT_PACKAGE_NAME_OVERRIDES.
 	installApkName string
@@ -107,6 +122,10 @@ func (a *AndroidApp) ExportedStaticPackages() android.Paths {
 
 var _ AndroidLibraryDependency = (*AndroidApp)(nil)
 
+// overrideBaseVariantTag is the dependency tag from the variants of an android_app created for
+// override_android_app modules to the original variant.
+var overrideBaseVariantTag = dependencyTag{name: "override base variant"}
+
 type Certificate struct {
 	Pem, Key android.Path
 }
@@ -143,6 +162,12 @@ func (a *AndroidApp) DepsMutator(ctx android.BottomUpMutatorContext) {
 				`must be names of android_app_certificate modules in the form ":module"`)
 		}
 	}
+
+	if a.GetOverriddenBy() != "" {
+		// Depend on the original variant of the module, which is earlier in the variant list, so that
+		// its compiled jars can be reused.
+		ctx.AddVariationDependencies(nil, overrideBaseVariantTag, ctx.ModuleName())
+	}
 }
 
 func (a *AndroidApp) GenerateAndroidBuildActions(ctx android.ModuleContext) {
@@ -219,9 +244,11 @@ func (a *AndroidApp) aaptBuildActions(ctx android.ModuleContext) {
 	// TODO: LOCAL_PACKAGE_OVERRIDES
 	//    $(addprefix --rename-manifest-package , $(PRIVATE_MANIFEST_PACKAGE_NAME)) \
 
-	manifestPackageName, overridden := ctx.DeviceConfig().OverrideManifestPackageNameFor(ctx.ModuleName())
+	manifestPackageName, overridden := ctx.DeviceConfig().OverrideManifestPackageNameFor(a.appName())
 	if overridden {
 		aaptLinkFlags = append(aaptLinkFlags, "--rename-manifest-package "+manifestPackageName)
+	} else if a.overridableAppProperties.Package_name != nil {
+		aaptLinkFlags = append(aaptLinkFlags, "--rename-manifest-package "+*a.overridableAppProperties.Package_name)
 	}
 
 	aaptLinkFlags = append(aaptLinkFlags, a.additionalAaptFlags...)
@@ -262,13 +289,59 @@ func (a *AndroidApp) dexBuildActions(ctx android.ModuleContext) android.Path {
 	a.dexpreopter.uncompressedDex = a.shouldUncompressDex(ctx)
 	a.deviceProperties.UncompressDex = a.dexpreopter.uncompressedDex
 
-	if ctx.ModuleName() != "framework-res" {
+	if base := a.overrideBaseVariant(ctx); base != nil {
+		a.reuseCompiledJars(ctx, base)
+	} else if ctx.ModuleName() != "framework-res" {
 		a.Module.compile(ctx, a.aaptSrcJar)
 	}
 
 	return a.maybeStrippedDexJarFile
 }
 
+// overrideBaseVariant returns the original variant of the module if this is a variant created for an
+// override_android_app module, and nil otherwise.
+func (a *AndroidApp) overrideBaseVariant(ctx android.ModuleContext) *AndroidApp {
+	var base *AndroidApp
+	ctx.VisitDirectDepsWithTag(overrideBaseVariantTag, func(m android.Module) {
+		// The dependency is resolved by module name, make sure that it reached the original variant
+		// and not this or another overridden variant.
+		if app, ok := m.(*AndroidApp); ok && app != a && app.GetOverriddenBy() == "" {
+			base = app
+		} else {
+			ctx.ModuleErrorf("override variant depends on %q, which is not the original variant",
+				ctx.OtherModuleName(m))
+		}
+	})
+	return base
+}
+
+// reuseCompiledJars uses the jars compiled by the original variant of the module instead of compiling
+// the sources again.  The renamed manifest package doesn't affect the R classes or the dex code, so
+// only dexpreopting, which depends on the install location of the app, is repeated.
+func (a *AndroidApp) reuseCompiledJars(ctx android.ModuleContext, base *AndroidApp) {
+	a.headerJarFile = base.headerJarFile
+	a.implementationJarFile = base.implementationJarFile
+	a.implementationAndResourcesJar = base.implementationAndResourcesJar
+	a.jacocoReportClassesFile = base.jacocoReportClassesFile
+	a.proguardDictionary = base.proguardDictionary
+	a.dexJarFile = base.dexJarFile
+
+	if base.dexJarFile == nil {
+		return
+	}
+
+	// The dex jar was aligned for the dex compression of the original variant.
+	a.dexpreopter.uncompressedDex = base.dexpreopter.uncompressedDex
+	a.deviceProperties.UncompressDex = base.deviceProperties.UncompressDex
+
+	dexJarFile, ok := base.dexJarFile.(android.ModuleOutPath)
+	if !ok {
+		ctx.ModuleErrorf("dex jar %q of the original variant is not a module output", base.dexJarFile)
+		return
+	}
+	a.maybeStrippedDexJarFile = a.dexpreopt(ctx, dexJarFile)
+}
+
 func (a *AndroidApp) jniBuildActions(jniLibs []jniLib, ctx android.ModuleContext) android.WritablePath {
 	var jniJarFile android.WritablePath
 	if len(jniLibs) > 0 {
@@ -318,7 +391,7 @@ func (a *AndroidApp) certificateBuildActions(certificateDeps []Certificate, ctx
 
 func (a *AndroidApp) generateAndroidBuildActions(ctx android.ModuleContext) {
 	// Check if the install APK name needs to be overridden.
-	a.installApkName = ctx.DeviceConfig().OverridePackageNameFor(ctx.ModuleName())
+	a.installApkName = ctx.DeviceConfig().OverridePackageNameFor(a.appName())
 
 	// Process all building blocks, from AAPT to certificates.
 	a.aaptBuildActions(ctx)
@@ -327,6 +400,10 @@ func (a *AndroidApp) generateAndroidBuildActions(ctx android.ModuleContext) {
 
 	dexJarFile := a.dexBuildActions(ctx)
 
+	a.linter.manifest = a.manifestPath
+	a.linter.resources = a.resourceFiles
+	a.linter.lint(ctx)
+
 	jniLibs, certificateDeps := a.collectAppDeps(ctx)
 	jniJarFile := a.jniBuildActions(jniLibs, ctx)
 
@@ -337,7 +414,7 @@ func (a *AndroidApp) generateAndroidBuildActions(ctx android.ModuleContext) {
 	certificates := a.certificateBuildActions(certificateDeps, ctx)
 
 	// Build a final signed app package.
-	packageFile := android.PathForModuleOut(ctx, ctx.ModuleName()+".apk")
+	packageFile := android.PathForModuleOut(ctx, a.appName()+".apk")
 	CreateAppPackage(ctx, packageFile, a.exportPackage, jniJarFile, dexJarFile, certificates)
 	a.outputFile = packageFile
 
@@ -346,6 +423,12 @@ func (a *AndroidApp) generateAndroidBuildActions(ctx android.ModuleContext) {
 	BuildBundleModule(ctx, bundleFile, a.exportPackage, jniJarFile, dexJarFile)
 	a.bundleFile = bundleFile
 
+	if Bool(a.appProperties.Build_app_bundle) && !a.dexpreopter.isTest {
+		appBundleFile := android.PathForModuleOut(ctx, a.appName()+".aab")
+		BuildAppBundle(ctx, appBundleFile, bundleFile)
+		a.appBundleFile = appBundleFile
+	}
+
 	// Install the app package.
 	if ctx.ModuleName() == "framework-res" {
 		// framework-res.apk is installed as system/framework/framework-res.apk
@@ -394,11 +477,20 @@ func (a *AndroidApp) collectAppDeps(ctx android.ModuleContext) ([]jniLib, []Cert
 }
 
 func (a *AndroidApp) getCertString(ctx android.BaseContext) string {
-	certificate, overridden := ctx.DeviceConfig().OverrideCertificateFor(ctx.ModuleName())
+	certificate, overridden := ctx.DeviceConfig().OverrideCertificateFor(a.appName())
 	if overridden {
 		return ":" + certificate
 	}
-	return String(a.appProperties.Certificate)
+	return String(a.overridableAppProperties.Certificate)
+}
+
+// appName returns the name of the app being built, which is the name of the override_android_app
+// module for the variants of an android_app created for them, and the module name otherwise.
+func (a *AndroidApp) appName() string {
+	if overriddenBy := a.GetOverriddenBy(); overriddenBy != "" {
+		return overriddenBy
+	}
+	return a.Name()
 }
 
 // android_app compiles sources and Android resources into an Android application package `.apk` file.
@@ -417,7 +509,9 @@ func AndroidAppFactory() android.Module {
 		&module.Module.dexpreoptProperties,
 		&module.Module.protoProperties,
 		&module.aaptProperties,
-		&module.appProperties)
+		&module.appProperties,
+		&module.overridableAppProperties,
+		&module.Module.linter.properties)
 
 	module.Prefer32(func(ctx android.BaseModuleContext, base *android.ModuleBase, class android.OsClass) bool {
 		return class == android.Device && ctx.Config().DevicePrefer32BitApps()
@@ -425,6 +519,8 @@ func AndroidAppFactory() android.Module {
 
 	android.InitAndroidMultiTargetsArchModule(module, android.DeviceSupported, android.MultilibCommon)
 	android.InitDefaultableModule(module)
+	android.InitOverridableModule(module, &module.overridableAppProperties.Overrides,
+		&module.overridableAppProperties)
 
 	return module
 }
@@ -479,6 +575,7 @@ func AndroidTestFactory() android.Module {
 	module.Module.properties.Installable = proptools.BoolPtr(true)
 	module.appProperties.Use_embedded_native_libs = proptools.BoolPtr(true)
 	module.Module.dexpreopter.isTest = true
+	module.Module.linter.test = true
 
 	module.AddProperties(
 		&module.Module.properties,
@@ -487,8 +584,10 @@ func AndroidTestFactory() android.Module {
 		&module.Module.protoProperties,
 		&module.aaptProperties,
 		&module.appProperties,
+		&module.overridableAppProperties,
 		&module.appTestProperties,
-		&module.testProperties)
+		&module.testProperties,
+		&module.Module.linter.properties)
 
 	android.InitAndroidMultiTargetsArchModule(module, android.DeviceSupported, android.MultilibCommon)
 	android.InitDefaultableModule(module)
@@ -526,7 +625,9 @@ func AndroidTestHelperAppFactory() android.Module {
 		&module.Module.protoProperties,
 		&module.aaptProperties,
 		&module.appProperties,
-		&module.appTestHelperAppProperties)
+		&module.overridableAppProperties,
+		&module.appTestHelperAppProperties,
+		&module.Module.linter.properties)
 
 	android.InitAndroidMultiTargetsArchModule(module, android.DeviceSupported, android.MultilibCommon)
 	android.InitDefaultableModule(module)
@@ -560,3 +661,25 @@ func (c *AndroidAppCertificate) GenerateAndroidBuildActions(ctx android.ModuleCo
 		android.PathForModuleSrc(ctx, cert+".pk8"),
 	}
 }
+
+type OverrideAndroidApp struct {
+	android.ModuleBase
+	android.OverrideModuleBase
+}
+
+func (i *OverrideAndroidApp) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	// All the overrides happen in the base module.
+}
+
+// override_android_app is used to create an android_app module based on another android_app by overriding
+// some of its properties.  A variant of the base module is created for it that reuses the jars compiled by
+// the base module, and only renames the manifest package, packages, signs and installs the app under the
+// name of the override_android_app.
+func OverrideAndroidAppModuleFactory() android.Module {
+	m := &OverrideAndroidApp{}
+	m.AddProperties(&overridableAppProperties{})
+
+	android.InitAndroidModule(m)
+	android.InitOverrideModule(m)
+	return m
+}
@@ -199,6 +199,22 @@ func BuildBundleModule(ctx android.ModuleContext, outputFile android.WritablePat
 	})
 }
 
+var buildAppBundle = pctx.AndroidStaticRule("buildAppBundle",
+	blueprint.RuleParams{
+		Command:     `${config.BuildAppBundleCmd} -o ${out} ${in}`,
+		CommandDeps: []string{"${config.BuildAppBundleCmd}"},
+	})
+
+// Builds an Android App Bundle (.aab) from the base module built by BuildBundleModule
+func BuildAppBundle(ctx android.ModuleContext, outputFile android.WritablePath, baseModule android.Path) {
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        buildAppBundle,
+		Input:       baseModule,
+		Output:      outputFile,
+		Description: "app bundle",
+	})
+}
+
 func TransformJniLibsToJar(ctx android.ModuleContext, outputFile android.WritablePath,
 	jniLibs []jniLib, uncompressJNI bool) {
 
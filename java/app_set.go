@@ -0,0 +1,121 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+// This file contains the module type for installing prebuilt apps from an APK set.
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"android/soong/android"
+)
+
+func init() {
+	android.RegisterModuleType("android_app_set", AndroidAppSetFactory)
+}
+
+var extractApks = pctx.AndroidStaticRule("extractApks",
+	blueprint.RuleParams{
+		Command: `${config.ExtractApksCmd} -o ${out} -splits ${splits} -stem ${stem} ` +
+			`-sdk-version ${sdkVersion} -abis "${abis}" -screen-density "${screenDensity}" ${in}`,
+		CommandDeps: []string{"${config.ExtractApksCmd}"},
+		Description: "extract APKs from APK set ${in}",
+	},
+	"splits", "stem", "sdkVersion", "abis", "screenDensity")
+
+type AndroidAppSetProperties struct {
+	// the APK set (.apks file) produced by bundletool build-apks that the app is installed from.
+	Set *string `android:"path"`
+
+	// Specifies that this app should be installed to the priv-app directory,
+	// where the system will grant it additional privileges not available to
+	// normal apps.
+	Privileged *bool
+}
+
+type AndroidAppSet struct {
+	android.ModuleBase
+
+	properties AndroidAppSetProperties
+
+	// The main APK selected from the set, and a zip of the split APKs installed alongside it.
+	outputFile android.WritablePath
+	splitsFile android.WritablePath
+}
+
+func (as *AndroidAppSet) Privileged() bool {
+	return Bool(as.properties.Privileged)
+}
+
+// deviceAbis returns the ABIs supported by the device in order of preference, the ABIs of the
+// primary architecture first.
+func deviceAbis(config android.Config) []string {
+	var abis []string
+	for _, target := range config.Targets[android.Android] {
+		abis = append(abis, target.Arch.Abi...)
+	}
+	return android.FirstUniqueStrings(abis)
+}
+
+func (as *AndroidAppSet) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	if String(as.properties.Set) == "" {
+		ctx.PropertyErrorf("set", "missing the APK set")
+		return
+	}
+	set := ctx.ExpandSource(String(as.properties.Set), "set")
+	if set.Ext() != ".apks" {
+		ctx.PropertyErrorf("set", "APK set %q does not have a .apks extension", set.String())
+		return
+	}
+
+	as.outputFile = android.PathForModuleOut(ctx, ctx.ModuleName()+".apk")
+	as.splitsFile = android.PathForModuleOut(ctx, ctx.ModuleName()+"-splits.zip")
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:           extractApks,
+		Input:          set,
+		Output:         as.outputFile,
+		ImplicitOutput: as.splitsFile,
+		Args: map[string]string{
+			"splits":        as.splitsFile.String(),
+			"stem":          ctx.ModuleName(),
+			"sdkVersion":    strconv.Itoa(ctx.Config().PlatformSdkVersionInt()),
+			"abis":          strings.Join(deviceAbis(ctx.Config()), ","),
+			"screenDensity": ctx.Config().ProductAAPTPreferredConfig(),
+		},
+	})
+
+	// Make unpacks the split APKs next to the installed main APK, see AndroidMk.
+	dir := "app"
+	if as.Privileged() {
+		dir = "priv-app"
+	}
+	ctx.InstallFile(android.PathForModuleInstall(ctx, dir, ctx.ModuleName()),
+		ctx.ModuleName()+".apk", as.outputFile)
+}
+
+// android_app_set installs a prebuilt app from an APK set (.apks file) produced by bundletool.  The
+// APKs matching the ABIs and screen density of the device are extracted from the set and installed:
+// the system APK of the set if it has one, the master split APK and the matching configuration
+// splits, or the best matching standalone APK.  The APKs must already be signed.
+func AndroidAppSetFactory() android.Module {
+	module := &AndroidAppSet{}
+	module.AddProperties(&module.properties)
+	android.InitAndroidArchModule(module, android.DeviceSupported, android.MultilibCommon)
+	return module
+}
@@ -106,6 +106,77 @@ func TestApp(t *testing.T) {
 	}
 }
 
+func TestAppBundle(t *testing.T) {
+	ctx := testApp(t, `
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+			build_app_bundle: true,
+		}
+
+		android_app {
+			name: "bar",
+			srcs: ["a.java"],
+		}
+
+		android_test {
+			name: "baz",
+			srcs: ["a.java"],
+			build_app_bundle: true,
+		}
+	`)
+
+	foo := ctx.ModuleForTests("foo", "android_common")
+	bundle := foo.Output("base.zip")
+	aab := foo.Output("foo.aab")
+	if len(aab.Inputs) != 1 || aab.Inputs[0].String() != bundle.Output.String() {
+		t.Errorf("expected app bundle input %q, got %q", bundle.Output.String(), aab.Inputs.Strings())
+	}
+
+	for _, name := range []string{"bar", "baz"} {
+		if aab := ctx.ModuleForTests(name, "android_common").MaybeOutput(name + ".aab"); aab.Rule != nil {
+			t.Errorf("expected no app bundle for %q", name)
+		}
+	}
+}
+
+func TestAndroidAppSet(t *testing.T) {
+	ctx := testJava(t, `
+		android_app_set {
+			name: "foo",
+			set: "prebuilts/apks/app.apks",
+			privileged: true,
+		}`)
+
+	foo := ctx.ModuleForTests("foo", "android_common")
+	extract := foo.Output("foo.apk")
+	if extract.Input.String() != "prebuilts/apks/app.apks" {
+		t.Errorf("expected APK set input %q, got %q", "prebuilts/apks/app.apks", extract.Input.String())
+	}
+	if abis := extract.Args["abis"]; abis != "arm64-v8a,armeabi-v7a" {
+		t.Errorf("expected ABIs %q, got %q", "arm64-v8a,armeabi-v7a", abis)
+	}
+	if density := extract.Args["screenDensity"]; density != "xhdpi" {
+		t.Errorf("expected screen density %q, got %q", "xhdpi", density)
+	}
+	if splits := extract.ImplicitOutput.String(); !strings.HasSuffix(splits, "foo-splits.zip") {
+		t.Errorf("expected the split APKs zip as an implicit output, got %q", splits)
+	}
+
+	install := buildDir + "/target/product/test_device/system/priv-app/foo/foo.apk"
+	if !android.InList(install, foo.AllOutputs()) {
+		t.Errorf("expected %q to be installed, outputs: %q", install, foo.AllOutputs())
+	}
+}
+
+func TestAndroidAppSetExtension(t *testing.T) {
+	testJavaError(t, `APK set "a.java" does not have a .apks extension`, `
+		android_app_set {
+			name: "foo",
+			set: "a.java",
+		}`)
+}
+
 func TestResourceDirs(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -774,3 +845,168 @@ func TestInstrumentationTargetOverridden(t *testing.T) {
 		t.Errorf("target package renaming flag, %q is missing in aapt2 link flags, %q", e, aapt2Flags)
 	}
 }
+
+func TestOverrideAndroidApp(t *testing.T) {
+	ctx := testJava(t, `
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+			certificate: "expiredkey",
+			overrides: ["baz"],
+		}
+
+		override_android_app {
+			name: "bar",
+			base: "foo",
+			certificate: ":new_certificate",
+		}
+
+		android_app_certificate {
+			name: "new_certificate",
+			certificate: "cert/new_cert",
+		}
+
+		override_android_app {
+			name: "baz",
+			base: "foo",
+			package_name: "org.dandroid.bp",
+		}
+		`)
+
+	expectedVariants := []struct {
+		variantName string
+		apkName     string
+		apkPath     string
+		signFlag    string
+		overrides   []string
+		aaptFlag    string
+	}{
+		{
+			variantName: "android_common",
+			apkName:     "foo.apk",
+			apkPath:     "/target/product/test_device/system/app/foo/foo.apk",
+			signFlag:    "build/target/product/security/expiredkey.x509.pem build/target/product/security/expiredkey.pk8",
+			overrides:   []string{"baz"},
+			aaptFlag:    "",
+		},
+		{
+			variantName: "bar_android_common",
+			apkName:     "bar.apk",
+			apkPath:     "/target/product/test_device/system/app/bar/bar.apk",
+			signFlag:    "cert/new_cert.x509.pem cert/new_cert.pk8",
+			overrides:   []string{"baz", "foo"},
+			aaptFlag:    "",
+		},
+		{
+			variantName: "baz_android_common",
+			apkName:     "baz.apk",
+			apkPath:     "/target/product/test_device/system/app/baz/baz.apk",
+			signFlag:    "build/target/product/security/expiredkey.x509.pem build/target/product/security/expiredkey.pk8",
+			overrides:   []string{"baz", "foo"},
+			aaptFlag:    "--rename-manifest-package org.dandroid.bp",
+		},
+	}
+	for _, expected := range expectedVariants {
+		variant := ctx.ModuleForTests("foo", expected.variantName)
+
+		// Check the final apk name
+		outputs := variant.AllOutputs()
+		expectedApkPath := buildDir + expected.apkPath
+		found := false
+		for _, o := range outputs {
+			if o == expectedApkPath {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Can't find %q in output files.\nAll outputs:%v", expectedApkPath, outputs)
+		}
+
+		// Check the certificate paths
+		signapk := variant.Output(expected.apkName)
+		signFlag := signapk.Args["certificates"]
+		if expected.signFlag != signFlag {
+			t.Errorf("Incorrect signing flags, expected: %q, got: %q", expected.signFlag, signFlag)
+		}
+
+		// Check if the overrides field values are correctly aggregated.
+		mod := variant.Module().(*AndroidApp)
+		if !reflect.DeepEqual(expected.overrides, mod.overridableAppProperties.Overrides) {
+			t.Errorf("Incorrect overrides property value, expected: %q, got: %q",
+				expected.overrides, mod.overridableAppProperties.Overrides)
+		}
+
+		// Check the package renaming flag, if exists.
+		res := variant.Output("package-res.apk")
+		aapt2Flags := res.Args["flags"]
+		if !strings.Contains(aapt2Flags, expected.aaptFlag) {
+			t.Errorf("package renaming flag, %q is missing in aapt2 link flags, %q", expected.aaptFlag, aapt2Flags)
+		}
+	}
+}
+
+func TestOverrideAndroidAppCompilesOnce(t *testing.T) {
+	ctx := testJava(t, `
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+		}
+
+		override_android_app {
+			name: "bar",
+			base: "foo",
+		}
+
+		override_android_app {
+			name: "baz",
+			base: "foo",
+			package_name: "org.dandroid.bp",
+		}
+		`)
+
+	foo := ctx.ModuleForTests("foo", "android_common")
+	fooDexJar := foo.Module().(*AndroidApp).dexJarFile
+
+	javacRules := 0
+	for _, variantName := range []string{"android_common", "bar_android_common", "baz_android_common"} {
+		variant := ctx.ModuleForTests("foo", variantName)
+		if variant.MaybeRule("javac").Rule != nil {
+			javacRules++
+		}
+
+		if dexJar := variant.Module().(*AndroidApp).dexJarFile; dexJar != fooDexJar {
+			t.Errorf("%s: expected dex jar %q, got %q", variantName, fooDexJar, dexJar)
+		}
+	}
+
+	if javacRules != 1 {
+		t.Errorf("expected 1 javac rule across the variants, got %d", javacRules)
+	}
+}
+
+func TestOverrideAndroidAppVariantOrder(t *testing.T) {
+	ctx := testJava(t, `
+		override_android_app {
+			name: "qux",
+			base: "foo",
+		}
+
+		override_android_app {
+			name: "bar",
+			base: "foo",
+		}
+
+		android_app {
+			name: "foo",
+			srcs: ["a.java"],
+		}
+		`)
+
+	// The variants are sorted by the name of the override module, not by declaration order, so that
+	// the build manifest is the same on every run.
+	expected := []string{"android_common", "bar_android_common", "qux_android_common"}
+	if variants := ctx.ModuleVariantsForTests("foo"); !reflect.DeepEqual(expected, variants) {
+		t.Errorf("expected variants %q, got %q", expected, variants)
+	}
+}
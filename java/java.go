@@ -319,6 +319,7 @@ type Module struct {
 
 	hiddenAPI
 	dexpreopter
+	linter
 }
 
 func (j *Module) Srcs() android.Paths {
@@ -391,6 +392,19 @@ var (
 	instrumentationForTag = dependencyTag{name: "instrumentation_for"}
 )
 
+var _ android.LicensePropagatingDependencyTag = dependencyTag{}
+var _ android.LicensePropagatingDependencyTag = jniDependencyTag{}
+
+// PropagatesLicenses returns true for the static libraries that are combined into the module.
+func (d dependencyTag) PropagatesLicenses() bool {
+	return d == staticLibTag
+}
+
+// PropagatesLicenses returns true as JNI libraries are packaged into the app.
+func (d jniDependencyTag) PropagatesLicenses() bool {
+	return true
+}
+
 type sdkDep struct {
 	useModule, useFiles, useDefaultLibs, invalidVersion bool
 
@@ -1031,6 +1045,12 @@ func (j *Module) compile(ctx android.ModuleContext, extraSrcJars ...android.Path
 	j.compiledJavaSrcs = uniqueSrcFiles
 	j.compiledSrcJars = srcJars
 
+	// Store the inputs for lint, which is run by the module types that support it
+	j.linter.srcs = append(append(android.Paths(nil), uniqueSrcFiles...), srcFiles.FilterByExt(".kt")...)
+	j.linter.srcJars = srcJars
+	j.linter.classpath = append(append(android.Paths(nil), flags.bootClasspath...), flags.classpath...)
+	j.linter.javaLanguageLevel = flags.javaVersion
+
 	enable_sharding := false
 	if ctx.Device() && !ctx.Config().IsEnvFalse("TURBINE_ENABLED") && !deps.disableTurbine {
 		if j.properties.Javac_shard_size != nil && *(j.properties.Javac_shard_size) > 0 {
@@ -1199,6 +1219,7 @@ func (j *Module) compile(ctx android.ModuleContext, extraSrcJars ...android.Path
 		}
 	}
 	j.implementationJarFile = outputFile
+	j.linter.classes = j.implementationJarFile
 	if j.headerJarFile == nil {
 		j.headerJarFile = j.implementationJarFile
 	}
@@ -1456,6 +1477,9 @@ func (j *Library) GenerateAndroidBuildActions(ctx android.ModuleContext) {
 	j.deviceProperties.UncompressDex = j.dexpreopter.uncompressedDex
 	j.compile(ctx)
 
+	j.linter.library = true
+	j.linter.lint(ctx)
+
 	if (Bool(j.properties.Installable) || ctx.Host()) && !android.DirectlyInAnyApex(ctx, ctx.ModuleName()) {
 		j.installFile = ctx.InstallFile(android.PathForModuleInstall(ctx, "framework"),
 			ctx.ModuleName()+".jar", j.outputFile)
@@ -1484,7 +1508,8 @@ func LibraryFactory() android.Module {
 		&module.Module.properties,
 		&module.Module.deviceProperties,
 		&module.Module.dexpreoptProperties,
-		&module.Module.protoProperties)
+		&module.Module.protoProperties,
+		&module.Module.linter.properties)
 
 	InitJavaModule(module, android.HostAndDeviceSupported)
 	return module
@@ -1958,10 +1983,12 @@ func DefaultsFactory(props ...interface{}) android.Module {
 		&aaptProperties{},
 		&androidLibraryProperties{},
 		&appProperties{},
+		&overridableAppProperties{},
 		&appTestProperties{},
 		&ImportProperties{},
 		&AARImportProperties{},
 		&sdkLibraryProperties{},
+		&LintProperties{},
 	)
 
 	android.InitDefaultsModule(module)
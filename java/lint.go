@@ -0,0 +1,323 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint"
+
+	"android/soong/android"
+)
+
+func init() {
+	android.RegisterSingletonType("lint", lintSingletonFactory)
+}
+
+const (
+	lintToolPath      = "prebuilts/cmdline-tools/tools/bin/lint"
+	lintClasspathPath = "prebuilts/cmdline-tools/tools/lib/lint-classpath.jar"
+
+	// Scratch files and directories written by the lint rule into the root of its sandbox, relative
+	// to the directory the commands run in.  They are deleted before the rule finishes.
+	lintProjectXML = "lint-project.xml"
+	lintConfigXML  = "lint-config.xml"
+	lintCacheDir   = "lint-cache"
+	lintHomeDir    = "lint-home"
+	lintSrcJarDir  = "lint-srcjars"
+)
+
+// lintFileList writes a whitespace separated list of its inputs to its output, for lists that may be
+// too long to put on a command line.
+var lintFileList = pctx.AndroidStaticRule("lintFileList",
+	blueprint.RuleParams{
+		Command:        `cp -f $out.rsp $out`,
+		Rspfile:        "$out.rsp",
+		RspfileContent: "$in",
+	})
+
+type LintProperties struct {
+	// Controls for running Android Lint on the module.
+	Lint struct {
+		// If true, run Android Lint on the module.  Defaults to true.
+		Enabled *bool
+
+		// Flags to pass to the Android Lint tool.
+		Flags []string
+
+		// Checks that should be treated as fatal, failing the lint rule of the module.
+		Fatal_checks []string
+
+		// Checks that should be treated as errors.
+		Error_checks []string
+
+		// Checks that should be treated as warnings.
+		Warning_checks []string
+
+		// Checks that should be skipped.
+		Disabled_checks []string
+
+		// Name of the file in the module directory that lists the issues lint should not report,
+		// created with lint --baseline.  Defaults to lint-baseline.xml, which is used if it exists.
+		Baseline_filename *string
+	}
+}
+
+// linter holds the inputs of the lint rule of a module, which are filled in while the module
+// compiles its sources and resources, and the reports lint produces.
+type linter struct {
+	manifest          android.Path
+	srcs              android.Paths
+	srcJars           android.Paths
+	resources         android.Paths
+	classpath         android.Paths
+	classes           android.Path
+	library           bool
+	test              bool
+	javaLanguageLevel string
+
+	outputs lintOutputs
+
+	properties LintProperties
+}
+
+type lintOutputs struct {
+	html android.Path
+	text android.Path
+	xml  android.Path
+}
+
+type lintOutputsIntf interface {
+	lintOutputs() *lintOutputs
+}
+
+var _ lintOutputsIntf = (*linter)(nil)
+
+func (l *linter) lintOutputs() *lintOutputs {
+	return &l.outputs
+}
+
+func (l *linter) enabled() bool {
+	return BoolDefault(l.properties.Lint.Enabled, true)
+}
+
+// baseline returns the lint baseline file of the module, if it has one.
+func (l *linter) baseline(ctx android.ModuleContext) android.OptionalPath {
+	if l.properties.Lint.Baseline_filename != nil {
+		return android.OptionalPathForPath(
+			android.PathForModuleSrc(ctx, *l.properties.Lint.Baseline_filename))
+	}
+	return android.ExistentPathForSource(ctx, ctx.ModuleDir(), "lint-baseline.xml")
+}
+
+// lintTool returns the path to a source file used by the lint rule.  Not every tree has the lint
+// prebuilts, if the file does not exist it is added to missingDeps, and building the lint reports
+// of the module will fail with an error that names the missing file.
+func lintTool(ctx android.ModuleContext, path string, missingDeps *[]string) android.Path {
+	if p := android.ExistentPathForSource(ctx, path); p.Valid() {
+		return p.Path()
+	}
+	*missingDeps = append(*missingDeps, path)
+	return android.PathForOutput(ctx, "missing", path)
+}
+
+// writeFileList writes the list of files to a file in the module output directory.
+func writeFileList(ctx android.ModuleContext, name string, files android.Paths) android.Path {
+	list := android.PathForModuleOut(ctx, name)
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        lintFileList,
+		Description: "lint " + name,
+		Inputs:      files,
+		Output:      list,
+	})
+	return list
+}
+
+// lint adds a rule that runs Android Lint on the sources and resources of the module.  The rule
+// runs in a sandbox that contains only its declared inputs, and writes the HTML, text and XML
+// reports to the lint directory of the module.  The reports are only built when they are
+// requested, either directly or through the lint-check target of lintSingleton.
+func (l *linter) lint(ctx android.ModuleContext) {
+	if !l.enabled() || !ctx.Device() {
+		return
+	}
+	if len(l.srcs) == 0 && len(l.srcJars) == 0 && len(l.resources) == 0 {
+		return
+	}
+
+	var missingDeps []string
+	tool := lintTool(ctx, lintToolPath, &missingDeps)
+	toolClasspath := lintTool(ctx, lintClasspathPath, &missingDeps)
+	projectXMLTool := lintTool(ctx, "build/soong/scripts/lint_project_xml.py", &missingDeps)
+
+	lintDir := android.PathForModuleOut(ctx, "lint")
+	html := android.PathForModuleOut(ctx, "lint", "lint-report.html")
+	text := android.PathForModuleOut(ctx, "lint", "lint-report.txt")
+	xml := android.PathForModuleOut(ctx, "lint", "lint-report.xml")
+
+	rule := android.NewRuleBuilder().Sbox(lintDir)
+	rule.MissingDeps(missingDeps)
+
+	rule.Command().Text("rm -rf").
+		Text(lintCacheDir).Text(lintHomeDir).Text(lintSrcJarDir).
+		Text("&& mkdir -p").
+		Text(lintCacheDir).Text(lintHomeDir).Text(lintSrcJarDir)
+
+	if len(l.srcJars) > 0 {
+		// The sources in srcjars are extracted into the sandbox, zipsync writes the list of the
+		// extracted files.
+		rule.Command().
+			Tool(ctx.Config().HostToolPath(ctx, "zipsync")).
+			FlagWithArg("-d ", lintSrcJarDir).
+			FlagWithArg("-l ", lintSrcJarDir+"/list").
+			FlagWithArg("-f ", `"*.java"`).
+			Inputs(l.srcJars)
+	}
+
+	projectCmd := rule.Command().
+		Tool(projectXMLTool).
+		FlagWithArg("--project_out ", lintProjectXML).
+		FlagWithArg("--config_out ", lintConfigXML).
+		FlagWithArg("--name ", ctx.ModuleName()).
+		FlagWithArg("--cache_dir ", lintCacheDir)
+
+	if len(l.srcs) > 0 {
+		projectCmd.FlagWithInput("--srcs ", writeFileList(ctx, "lint-srcs.list", l.srcs)).
+			Implicits(l.srcs)
+	}
+	if len(l.srcJars) > 0 {
+		projectCmd.FlagWithArg("--generated_srcs ", lintSrcJarDir+"/list")
+	}
+	if len(l.resources) > 0 {
+		projectCmd.FlagWithInput("--resources ", writeFileList(ctx, "lint-resources.list", l.resources)).
+			Implicits(l.resources)
+	}
+	if l.manifest != nil {
+		projectCmd.FlagWithInput("--manifest ", l.manifest)
+	}
+	if l.classes != nil {
+		projectCmd.FlagWithInput("--classes ", l.classes)
+	}
+	projectCmd.FlagForEachInput("--classpath ", l.classpath)
+	if l.library {
+		projectCmd.Flag("--library")
+	}
+	if l.test {
+		projectCmd.Flag("--test")
+	}
+	projectCmd.FlagForEachArg("--disable_check ", l.properties.Lint.Disabled_checks)
+	projectCmd.FlagForEachArg("--warning_check ", l.properties.Lint.Warning_checks)
+	projectCmd.FlagForEachArg("--error_check ", l.properties.Lint.Error_checks)
+	projectCmd.FlagForEachArg("--fatal_check ", l.properties.Lint.Fatal_checks)
+
+	lintCmd := rule.Command().
+		Text("(").
+		FlagWithArg("ANDROID_SDK_HOME=", lintHomeDir).
+		Tool(tool).
+		Implicit(toolClasspath).
+		Flag("--quiet").
+		FlagWithArg("--project ", lintProjectXML).
+		FlagWithArg("--config ", lintConfigXML).
+		FlagWithOutput("--html ", html).
+		FlagWithOutput("--text ", text).
+		FlagWithOutput("--xml ", xml)
+	if l.javaLanguageLevel != "" {
+		lintCmd.FlagWithArg("--java-language-level ", l.javaLanguageLevel)
+	}
+	if baseline := l.baseline(ctx); baseline.Valid() {
+		lintCmd.FlagWithInput("--baseline ", baseline.Path())
+	}
+	for _, flag := range l.properties.Lint.Flags {
+		lintCmd.Flag(flag)
+	}
+	// lint exits with an error if it finds fatal issues, print the text report before failing
+	// as the sandboxed reports are not kept.
+	lintCmd.Flag("--exitcode").
+		Text("|| (cat").Output(text).Text("; exit 7)").
+		Text(")")
+
+	rule.Command().Text("rm -rf").
+		Text(lintCacheDir).Text(lintHomeDir).Text(lintSrcJarDir).
+		Text(lintProjectXML).Text(lintConfigXML)
+
+	rule.Build(pctx, ctx, "lint", "lint")
+
+	l.outputs = lintOutputs{
+		html: html,
+		text: text,
+		xml:  xml,
+	}
+}
+
+func lintSingletonFactory() android.Singleton {
+	return &lintSingleton{}
+}
+
+// lintSingleton zips the lint reports of all modules into out/soong/lint-report-{html,text,xml}.zip,
+// and makes them buildable with "m lint-check".
+type lintSingleton struct {
+	zips android.Paths
+}
+
+func (l *lintSingleton) GenerateBuildActions(ctx android.SingletonContext) {
+	var outputs []*lintOutputs
+	ctx.VisitAllModules(func(m android.Module) {
+		if !m.Enabled() {
+			return
+		}
+		if lint, ok := m.(lintOutputsIntf); ok && lint.lintOutputs().html != nil {
+			outputs = append(outputs, lint.lintOutputs())
+		}
+	})
+
+	l.zips = nil
+	if len(outputs) == 0 {
+		return
+	}
+
+	zipReports := func(name string, get func(*lintOutputs) android.Path) {
+		var reports android.Paths
+		for _, output := range outputs {
+			reports = append(reports, get(output))
+		}
+		sort.Slice(reports, func(i, j int) bool { return reports[i].String() < reports[j].String() })
+
+		output := android.PathForOutput(ctx, "lint-report-"+name+".zip")
+		rule := android.NewRuleBuilder()
+		rule.Command().
+			Tool(ctx.Config().HostToolPath(ctx, "soong_zip")).
+			FlagWithOutput("-o ", output).
+			FlagWithArg("-C ", android.PathForIntermediates(ctx).String()).
+			FlagForEachInput("-f ", reports)
+		rule.Build(pctx, ctx, "lint_report_"+name, "zip lint "+name+" reports")
+
+		l.zips = append(l.zips, output)
+	}
+
+	zipReports("html", func(o *lintOutputs) android.Path { return o.html })
+	zipReports("text", func(o *lintOutputs) android.Path { return o.text })
+	zipReports("xml", func(o *lintOutputs) android.Path { return o.xml })
+
+	ctx.Build(pctx, android.BuildParams{
+		Rule:      blueprint.Phony,
+		Output:    android.PathForPhony(ctx, "lint-check"),
+		Implicits: l.zips,
+	})
+}
+
+func (l *lintSingleton) MakeVars(ctx android.MakeVarsContext) {
+	ctx.Strict("SOONG_LINT_REPORTS", strings.Join(l.zips.Strings(), " "))
+}
@@ -0,0 +1,160 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package java
+
+import (
+	"strings"
+	"testing"
+
+	"android/soong/android"
+)
+
+func testLint(t *testing.T, bp string) *android.TestContext {
+	t.Helper()
+	config := testConfig(nil)
+	ctx := testContext(config, bp, map[string][]byte{
+		lintToolPath:      nil,
+		lintClasspathPath: nil,
+		"build/soong/scripts/lint_project_xml.py": nil,
+
+		"res/values/strings.xml": nil,
+		"my-baseline.xml":        nil,
+	})
+	ctx.RegisterSingletonType("lint", android.SingletonFactoryAdaptor(lintSingletonFactory))
+	run(t, ctx, config)
+
+	return ctx
+}
+
+func TestLint(t *testing.T) {
+	ctx := testLint(t, `
+		java_library {
+			name: "foo",
+			srcs: ["a.java", "b.kt"],
+			lint: {
+				fatal_checks: ["NewApi"],
+				disabled_checks: ["Typos"],
+				baseline_filename: "my-baseline.xml",
+				flags: ["--disable-foo"],
+			},
+		}
+
+		android_library {
+			name: "bar",
+			srcs: ["b.java"],
+			resource_dirs: ["res"],
+		}
+
+		android_app {
+			name: "baz",
+			srcs: ["c.java"],
+			resource_dirs: ["res"],
+			static_libs: ["bar"],
+		}
+
+		java_library {
+			name: "qux",
+			srcs: ["c.java"],
+			lint: {
+				enabled: false,
+			},
+		}
+	`)
+
+	foo := ctx.ModuleForTests("foo", "android_common")
+	fooLint := foo.Output("lint/lint-report.xml")
+	fooCmd := fooLint.RuleParams.Command
+	for _, want := range []string{
+		"--library",
+		"--fatal_check NewApi",
+		"--disable_check Typos",
+		"--srcs " + foo.Output("lint-srcs.list").Output.String(),
+		"--classes " + foo.Module().(*Library).implementationJarFile.String(),
+		"--baseline my-baseline.xml",
+		"--disable-foo",
+		"--exitcode",
+	} {
+		if !strings.Contains(fooCmd, want) {
+			t.Errorf("expected foo lint command to contain %q, got %q", want, fooCmd)
+		}
+	}
+	if strings.Contains(fooCmd, "--manifest") {
+		t.Errorf("expected foo lint command not to have a manifest, got %q", fooCmd)
+	}
+	if srcs := foo.Output("lint-srcs.list").Inputs.Strings(); len(srcs) != 2 ||
+		srcs[0] != "a.java" || srcs[1] != "b.kt" {
+		t.Errorf(`expected foo lint srcs ["a.java" "b.kt"], got %q`, srcs)
+	}
+
+	bar := ctx.ModuleForTests("bar", "android_common")
+	barCmd := bar.Output("lint/lint-report.xml").RuleParams.Command
+	for _, want := range []string{
+		"--library",
+		"--manifest " + bar.Module().(*AndroidLibrary).manifestPath.String(),
+		"--resources " + bar.Output("lint-resources.list").Output.String(),
+		"--generated_srcs",
+	} {
+		if !strings.Contains(barCmd, want) {
+			t.Errorf("expected bar lint command to contain %q, got %q", want, barCmd)
+		}
+	}
+
+	baz := ctx.ModuleForTests("baz", "android_common")
+	bazCmd := baz.Output("lint/lint-report.xml").RuleParams.Command
+	if !strings.Contains(bazCmd, "--manifest "+baz.Module().(*AndroidApp).manifestPath.String()) {
+		t.Errorf("expected baz lint command to use the merged manifest, got %q", bazCmd)
+	}
+	if strings.Contains(bazCmd, "--library") {
+		t.Errorf("expected baz lint command not to be a library, got %q", bazCmd)
+	}
+	if res := baz.Output("lint-resources.list").Inputs.Strings(); len(res) != 1 ||
+		res[0] != "res/values/strings.xml" {
+		t.Errorf(`expected baz lint resources ["res/values/strings.xml"], got %q`, res)
+	}
+
+	qux := ctx.ModuleForTests("qux", "android_common")
+	if qux.MaybeOutput("lint/lint-report.xml").Rule != nil {
+		t.Errorf("expected no lint rule for qux with lint disabled")
+	}
+
+	lintZip := ctx.SingletonForTests("lint").Output("lint-report-xml.zip")
+	zipCmd := lintZip.RuleParams.Command
+	for _, module := range []android.TestingModule{foo, bar, baz} {
+		report := module.Output("lint/lint-report.xml").Output.String()
+		if !strings.Contains(zipCmd, "-f "+report) {
+			t.Errorf("expected lint report zip to contain %q, got %q", report, zipCmd)
+		}
+	}
+	if strings.Contains(zipCmd, "/qux/") {
+		t.Errorf("expected lint report zip not to contain the reports of qux, got %q", zipCmd)
+	}
+}
+
+func TestLintMissingPrebuilts(t *testing.T) {
+	ctx := testJava(t, `
+		java_library {
+			name: "foo",
+			srcs: ["a.java"],
+		}
+	`)
+
+	lint := ctx.ModuleForTests("foo", "android_common").Output("lint/lint-report.xml")
+	if lint.Rule != android.ErrorRule {
+		t.Errorf("expected the lint rule to be an error rule without the lint prebuilts")
+	}
+	if !strings.Contains(lint.Args["error"], lintToolPath) {
+		t.Errorf("expected the lint error to name %q, got %q", lintToolPath, lint.Args["error"])
+	}
+}
@@ -0,0 +1,647 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoteexec
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"android/soong/remoteexec/reapi_proto"
+)
+
+const (
+	// maxBatchSize is the maximum total size of the blobs in a batch CAS request, which keeps the
+	// requests below the default 4MiB gRPC message size limit.
+	maxBatchSize = 2 * 1024 * 1024
+
+	// byteStreamChunkSize is the size of the chunks that blobs that don't fit in a batch request
+	// are written in.
+	byteStreamChunkSize = 1024 * 1024
+)
+
+// Client talks to a remote execution service through the REAPI gRPC services.
+type Client struct {
+	// Instance is the name of the instance on the server to use, which allows a server to be shared
+	// between different build configurations.  It may be empty.
+	Instance string
+
+	conn        *grpc.ClientConn
+	cas         reapi_proto.ContentAddressableStorageClient
+	actionCache reapi_proto.ActionCacheClient
+	execution   reapi_proto.ExecutionClient
+	byteStream  bytestream.ByteStreamClient
+}
+
+// Dial connects to a remote execution service.  The server is a gRPC target, e.g. localhost:8980
+// or unix:///path/to/socket, and the connection uses TLS if it is prefixed with grpcs://.
+func Dial(server, instance string) (*Client, error) {
+	creds := grpc.WithInsecure()
+	if strings.HasPrefix(server, "grpcs://") {
+		server = strings.TrimPrefix(server, "grpcs://")
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	} else {
+		server = strings.TrimPrefix(server, "grpc://")
+	}
+
+	conn, err := grpc.Dial(server, creds)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn, instance), nil
+}
+
+// NewClient returns a Client that uses an existing connection to a remote execution service.
+func NewClient(conn *grpc.ClientConn, instance string) *Client {
+	return &Client{
+		Instance:    instance,
+		conn:        conn,
+		cas:         reapi_proto.NewContentAddressableStorageClient(conn),
+		actionCache: reapi_proto.NewActionCacheClient(conn),
+		execution:   reapi_proto.NewExecutionClient(conn),
+		byteStream:  bytestream.NewByteStreamClient(conn),
+	}
+}
+
+// Close closes the connection to the remote execution service.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// FindMissingBlobs returns the digests that are not present in the CAS.
+func (c *Client) FindMissingBlobs(ctx context.Context, digests []*reapi_proto.Digest) ([]*reapi_proto.Digest, error) {
+	resp, err := c.cas.FindMissingBlobs(ctx, &reapi_proto.FindMissingBlobsRequest{
+		InstanceName: c.Instance,
+		BlobDigests:  digests,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.MissingBlobDigests, nil
+}
+
+// batchUpdateBlobs uploads blobs whose total size is at most maxBatchSize to the CAS in a single
+// request.
+func (c *Client) batchUpdateBlobs(ctx context.Context, requests []*reapi_proto.BatchUpdateBlobsRequest_Request) error {
+	resp, err := c.cas.BatchUpdateBlobs(ctx, &reapi_proto.BatchUpdateBlobsRequest{
+		InstanceName: c.Instance,
+		Requests:     requests,
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range resp.Responses {
+		if err := status.ErrorProto(r.Status); err != nil {
+			return fmt.Errorf("failed to upload blob %s: %s", digestKey(r.Digest), err)
+		}
+	}
+	return nil
+}
+
+// writeBlob uploads a blob to the CAS through the ByteStream service.
+func (c *Client) writeBlob(ctx context.Context, digest *reapi_proto.Digest, r io.Reader) error {
+	uuid, err := newUUID()
+	if err != nil {
+		return err
+	}
+
+	stream, err := c.byteStream.Write(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := &bytestream.WriteRequest{
+		ResourceName: writeResourceName(c.Instance, uuid, digest),
+	}
+	buf := make([]byte, byteStreamChunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			req.FinishWrite = true
+		} else if err != nil {
+			return err
+		}
+
+		req.Data = buf[:n]
+		if err := stream.Send(req); err != nil {
+			if err == io.EOF {
+				// The server closed the stream, the error is returned by CloseAndRecv.
+				break
+			}
+			return err
+		}
+		if req.FinishWrite {
+			break
+		}
+
+		req.WriteOffset += int64(n)
+		// The resource name is only required in the first request.
+		req.ResourceName = ""
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return fmt.Errorf("failed to upload blob %s: %s", digestKey(digest), err)
+	}
+	if resp.CommittedSize != digest.SizeBytes {
+		return fmt.Errorf("failed to upload blob %s: server committed %d bytes",
+			digestKey(digest), resp.CommittedSize)
+	}
+	return nil
+}
+
+// ReadBlob downloads a blob from the CAS into w.
+func (c *Client) ReadBlob(ctx context.Context, digest *reapi_proto.Digest, w io.Writer) error {
+	if digest.GetSizeBytes() == 0 {
+		return nil
+	}
+
+	stream, err := c.byteStream.Read(ctx, &bytestream.ReadRequest{
+		ResourceName: readResourceName(c.Instance, digest),
+	})
+	if err != nil {
+		return err
+	}
+
+	var n int64
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to read blob %s: %s", digestKey(digest), err)
+		}
+		if _, err := w.Write(resp.Data); err != nil {
+			return err
+		}
+		n += int64(len(resp.Data))
+	}
+
+	if n != digest.SizeBytes {
+		return fmt.Errorf("failed to read blob %s: expected %d bytes, got %d",
+			digestKey(digest), digest.SizeBytes, n)
+	}
+	return nil
+}
+
+// GetActionResult returns the cached result of an action, or nil if it is not in the action cache.
+func (c *Client) GetActionResult(ctx context.Context, actionDigest *reapi_proto.Digest) (*reapi_proto.ActionResult, error) {
+	result, err := c.actionCache.GetActionResult(ctx, &reapi_proto.GetActionResultRequest{
+		InstanceName: c.Instance,
+		ActionDigest: actionDigest,
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	return result, err
+}
+
+// Execute executes an action whose Command and input root have already been uploaded to the CAS,
+// and waits for it to complete.
+func (c *Client) Execute(ctx context.Context, actionDigest *reapi_proto.Digest, skipCacheLookup bool) (*reapi_proto.ActionResult, error) {
+	stream, err := c.execution.Execute(ctx, &reapi_proto.ExecuteRequest{
+		InstanceName:    c.Instance,
+		ActionDigest:    actionDigest,
+		SkipCacheLookup: skipCacheLookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var op *reapi_proto.Operation
+	for op == nil || !op.Done {
+		op, err = stream.Recv()
+		if err == io.EOF {
+			return nil, fmt.Errorf("failed to execute action %s: stream ended before the action completed",
+				digestKey(actionDigest))
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := status.ErrorProto(op.GetError()); err != nil {
+		return nil, fmt.Errorf("failed to execute action %s: %s", digestKey(actionDigest), err)
+	}
+
+	var resp reapi_proto.ExecuteResponse
+	if err := ptypes.UnmarshalAny(op.GetResponse(), &resp); err != nil {
+		return nil, fmt.Errorf("failed to execute action %s: %s", digestKey(actionDigest), err)
+	}
+	if err := status.ErrorProto(resp.Status); err != nil {
+		return nil, fmt.Errorf("failed to execute action %s: %s", digestKey(actionDigest), err)
+	}
+	if resp.Result == nil {
+		return nil, fmt.Errorf("failed to execute action %s: no result", digestKey(actionDigest))
+	}
+	return resp.Result, nil
+}
+
+// Request describes a command to run remotely.
+type Request struct {
+	// ExecRoot is the local directory that Inputs and Outputs are relative to.  It is the working
+	// directory of the command when it runs remotely.
+	ExecRoot string
+
+	// Inputs are the files and directories that are staged into the input root.  Directories are
+	// staged recursively.
+	Inputs []string
+
+	// Outputs are the files the command must produce, which are downloaded into ExecRoot.
+	Outputs []string
+
+	Arguments   []string
+	Environment []*reapi_proto.Command_EnvironmentVariable
+
+	// DoNotCache prevents the result from being looked up in or stored in the action cache.
+	DoNotCache bool
+
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Run runs a command remotely, or fetches its result from the action cache, and downloads its
+// outputs.  It returns the exit code of the command, or an error if the command could not be run.
+func (c *Client) Run(ctx context.Context, req Request) (int, error) {
+	inputRoot, blobs, err := buildInputRoot(req.ExecRoot, req.Inputs)
+	if err != nil {
+		return 0, err
+	}
+
+	outputs := append([]string(nil), req.Outputs...)
+	sort.Strings(outputs)
+
+	env := append([]*reapi_proto.Command_EnvironmentVariable(nil), req.Environment...)
+	sort.Slice(env, func(i, j int) bool { return env[i].Name < env[j].Name })
+
+	commandBlob, commandDigest, err := marshalMessage(&reapi_proto.Command{
+		Arguments:            req.Arguments,
+		EnvironmentVariables: env,
+		OutputFiles:          outputs,
+	})
+	if err != nil {
+		return 0, err
+	}
+	blobs.addBlob(commandDigest, commandBlob)
+
+	actionBlob, actionDigest, err := marshalMessage(&reapi_proto.Action{
+		CommandDigest:   commandDigest,
+		InputRootDigest: inputRoot,
+		DoNotCache:      req.DoNotCache,
+	})
+	if err != nil {
+		return 0, err
+	}
+	blobs.addBlob(actionDigest, actionBlob)
+
+	var result *reapi_proto.ActionResult
+	if !req.DoNotCache {
+		result, err = c.GetActionResult(ctx, actionDigest)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if result == nil {
+		if err := c.uploadMissingBlobs(ctx, blobs); err != nil {
+			return 0, err
+		}
+
+		result, err = c.Execute(ctx, actionDigest, true)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := c.downloadResult(ctx, req, result); err != nil {
+		return 0, err
+	}
+
+	return int(result.ExitCode), nil
+}
+
+func (c *Client) uploadMissingBlobs(ctx context.Context, blobs *blobSet) error {
+	var digests []*reapi_proto.Digest
+	for _, source := range blobs.sources {
+		digests = append(digests, source.digest)
+	}
+	sort.Slice(digests, func(i, j int) bool { return digests[i].Hash < digests[j].Hash })
+
+	missing, err := c.FindMissingBlobs(ctx, digests)
+	if err != nil {
+		return err
+	}
+
+	var batch []*reapi_proto.BatchUpdateBlobsRequest_Request
+	batchSize := int64(0)
+	for _, digest := range missing {
+		source, ok := blobs.sources[digestKey(digest)]
+		if !ok {
+			return fmt.Errorf("server requested unknown blob %s", digestKey(digest))
+		}
+
+		if digest.SizeBytes > maxBatchSize {
+			r, err := source.open()
+			if err != nil {
+				return err
+			}
+			err = c.writeBlob(ctx, digest, r)
+			r.Close()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if batchSize+digest.SizeBytes > maxBatchSize {
+			if err := c.batchUpdateBlobs(ctx, batch); err != nil {
+				return err
+			}
+			batch, batchSize = nil, 0
+		}
+
+		data, err := source.read()
+		if err != nil {
+			return err
+		}
+		batch = append(batch, &reapi_proto.BatchUpdateBlobsRequest_Request{Digest: digest, Data: data})
+		batchSize += digest.SizeBytes
+	}
+
+	if len(batch) > 0 {
+		return c.batchUpdateBlobs(ctx, batch)
+	}
+	return nil
+}
+
+func (c *Client) downloadResult(ctx context.Context, req Request, result *reapi_proto.ActionResult) error {
+	stdout, stderr := req.Stdout, req.Stderr
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+	if err := c.readOutputStream(ctx, result.StdoutRaw, result.StdoutDigest, stdout); err != nil {
+		return err
+	}
+	if err := c.readOutputStream(ctx, result.StderrRaw, result.StderrDigest, stderr); err != nil {
+		return err
+	}
+
+	if result.ExitCode != 0 {
+		return nil
+	}
+
+	found := make(map[string]bool)
+	for _, output := range result.OutputFiles {
+		found[output.Path] = true
+		if err := c.downloadOutput(ctx, req.ExecRoot, output); err != nil {
+			return err
+		}
+	}
+
+	for _, output := range req.Outputs {
+		if !found[filepath.Clean(output)] {
+			return fmt.Errorf("command did not produce output %q", output)
+		}
+	}
+
+	return nil
+}
+
+// readOutputStream writes the stdout or stderr of an action to w, either from the inlined raw
+// contents or from the CAS.
+func (c *Client) readOutputStream(ctx context.Context, raw []byte, digest *reapi_proto.Digest, w io.Writer) error {
+	if len(raw) > 0 {
+		_, err := w.Write(raw)
+		return err
+	}
+	return c.ReadBlob(ctx, digest, w)
+}
+
+func (c *Client) downloadOutput(ctx context.Context, execRoot string, output *reapi_proto.OutputFile) error {
+	path := filepath.Join(execRoot, output.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = c.ReadBlob(ctx, output.Digest, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download output %q: %s", output.Path, err)
+	}
+
+	mode := os.FileMode(0666)
+	if output.IsExecutable {
+		mode = 0777
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// newUUID returns a random version 4 UUID for a ByteStream upload resource name.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// blobSet holds the blobs that may need to be uploaded for an action, either in memory or as a
+// path to a local file, keyed by digestKey.
+type blobSet struct {
+	sources map[string]blobSource
+}
+
+type blobSource struct {
+	digest *reapi_proto.Digest
+	path   string
+	data   []byte
+}
+
+func (s blobSource) open() (io.ReadCloser, error) {
+	if s.path == "" {
+		return ioutil.NopCloser(bytes.NewReader(s.data)), nil
+	}
+	return os.Open(s.path)
+}
+
+func (s blobSource) read() ([]byte, error) {
+	if s.path == "" {
+		return s.data, nil
+	}
+	return ioutil.ReadFile(s.path)
+}
+
+func (b *blobSet) addBlob(digest *reapi_proto.Digest, data []byte) {
+	b.sources[digestKey(digest)] = blobSource{digest: digest, data: data}
+}
+
+func (b *blobSet) addFile(digest *reapi_proto.Digest, path string) {
+	b.sources[digestKey(digest)] = blobSource{digest: digest, path: path}
+}
+
+type inputTreeNode struct {
+	files map[string]*reapi_proto.FileNode
+	dirs  map[string]*inputTreeNode
+}
+
+func newInputTreeNode() *inputTreeNode {
+	return &inputTreeNode{
+		files: make(map[string]*reapi_proto.FileNode),
+		dirs:  make(map[string]*inputTreeNode),
+	}
+}
+
+// buildInputRoot computes the Merkle tree of Directory messages for the inputs and returns the
+// Digest of the root Directory, and the blobs for all the Directory messages and files.
+func buildInputRoot(execRoot string, inputs []string) (*reapi_proto.Digest, *blobSet, error) {
+	blobs := &blobSet{sources: make(map[string]blobSource)}
+	root := newInputTreeNode()
+
+	addFile := func(rel string, info os.FileInfo) error {
+		path := filepath.Join(execRoot, rel)
+		digest, err := DigestForFile(path)
+		if err != nil {
+			return err
+		}
+		blobs.addFile(digest, path)
+
+		node := root
+		parts := strings.Split(rel, "/")
+		for _, dir := range parts[:len(parts)-1] {
+			child := node.dirs[dir]
+			if child == nil {
+				child = newInputTreeNode()
+				node.dirs[dir] = child
+			}
+			node = child
+		}
+		name := parts[len(parts)-1]
+		node.files[name] = &reapi_proto.FileNode{
+			Name:         name,
+			Digest:       digest,
+			IsExecutable: info.Mode()&0111 != 0,
+		}
+		return nil
+	}
+
+	for _, input := range inputs {
+		rel := filepath.ToSlash(filepath.Clean(input))
+		if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			return nil, nil, fmt.Errorf("input %q is not relative to the exec root", input)
+		}
+
+		info, err := os.Stat(filepath.Join(execRoot, rel))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !info.IsDir() {
+			if err := addFile(rel, info); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		err = filepath.Walk(filepath.Join(execRoot, rel), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			// Follow symlinks so that the contents of the target are staged.
+			if info.Mode()&os.ModeSymlink != 0 {
+				if info, err = os.Stat(path); err != nil {
+					return err
+				}
+				if info.IsDir() {
+					return fmt.Errorf("input %q contains a symlink to a directory", input)
+				}
+			}
+			fileRel, err := filepath.Rel(execRoot, path)
+			if err != nil {
+				return err
+			}
+			return addFile(filepath.ToSlash(fileRel), info)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var marshalNode func(node *inputTreeNode) (*reapi_proto.Digest, error)
+	marshalNode = func(node *inputTreeNode) (*reapi_proto.Digest, error) {
+		dir := &reapi_proto.Directory{}
+		for _, file := range node.files {
+			dir.Files = append(dir.Files, file)
+		}
+		sort.Slice(dir.Files, func(i, j int) bool { return dir.Files[i].Name < dir.Files[j].Name })
+
+		for name, child := range node.dirs {
+			digest, err := marshalNode(child)
+			if err != nil {
+				return nil, err
+			}
+			dir.Directories = append(dir.Directories, &reapi_proto.DirectoryNode{Name: name, Digest: digest})
+		}
+		sort.Slice(dir.Directories, func(i, j int) bool {
+			return dir.Directories[i].Name < dir.Directories[j].Name
+		})
+
+		blob, digest, err := marshalMessage(dir)
+		if err != nil {
+			return nil, err
+		}
+		blobs.addBlob(digest, blob)
+		return digest, nil
+	}
+
+	rootDigest, err := marshalNode(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rootDigest, blobs, nil
+}
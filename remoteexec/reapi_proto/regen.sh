@@ -0,0 +1,3 @@
+#!/bin/bash
+
+aprotoc --go_out=plugins=grpc,paths=source_relative:. operations.proto remote_execution.proto
@@ -0,0 +1,122 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remoteexec implements a client and a local stand-in server for executing build commands
+// remotely through the Remote Execution API (REAPI) v2.
+//
+// A Command and a Merkle tree of Directory messages describing the input root are uploaded to the
+// ContentAddressableStorage (CAS) service, an Action referencing them by Digest is looked up in the
+// ActionCache service and executed by the Execution service if it was not cached, and the outputs
+// listed in the resulting ActionResult are downloaded from the CAS.  Small blobs are transferred
+// with the batch CAS methods, and larger ones with the ByteStream service.  The messages and
+// services are defined in reapi_proto, and are compatible with any REAPI v2 server.
+package remoteexec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"android/soong/remoteexec/reapi_proto"
+)
+
+// digestKey returns a string that uniquely identifies a Digest, for use as a map key.
+func digestKey(d *reapi_proto.Digest) string {
+	return d.GetHash() + "/" + strconv.FormatInt(d.GetSizeBytes(), 10)
+}
+
+var hashRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// parseDigest parses the hash and size components of a blob resource name.
+func parseDigest(hash, size string) (*reapi_proto.Digest, error) {
+	if !hashRegexp.MatchString(hash) {
+		return nil, fmt.Errorf("invalid hash %q", hash)
+	}
+	sizeBytes, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || sizeBytes < 0 {
+		return nil, fmt.Errorf("invalid size %q", size)
+	}
+	return &reapi_proto.Digest{Hash: hash, SizeBytes: sizeBytes}, nil
+}
+
+// readResourceName returns the ByteStream resource name to read a blob from the CAS.
+func readResourceName(instance string, digest *reapi_proto.Digest) string {
+	name := "blobs/" + digestKey(digest)
+	if instance != "" {
+		name = instance + "/" + name
+	}
+	return name
+}
+
+// writeResourceName returns the ByteStream resource name to write a blob to the CAS.
+func writeResourceName(instance, uuid string, digest *reapi_proto.Digest) string {
+	name := "uploads/" + uuid + "/blobs/" + digestKey(digest)
+	if instance != "" {
+		name = instance + "/" + name
+	}
+	return name
+}
+
+// parseResourceName returns the Digest of the blob in a ByteStream resource name of either form.
+// The instance name may contain slashes, so the blob is found from the end of the name, and any
+// trailing metadata is not allowed.
+func parseResourceName(name string) (*reapi_proto.Digest, error) {
+	parts := strings.Split(name, "/")
+	if len(parts) < 3 || parts[len(parts)-3] != "blobs" {
+		return nil, fmt.Errorf("invalid resource name %q", name)
+	}
+	digest, err := parseDigest(parts[len(parts)-2], parts[len(parts)-1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource name %q: %s", name, err)
+	}
+	return digest, nil
+}
+
+// DigestForBlob returns the Digest of a blob.
+func DigestForBlob(blob []byte) *reapi_proto.Digest {
+	sum := sha256.Sum256(blob)
+	return &reapi_proto.Digest{Hash: hex.EncodeToString(sum[:]), SizeBytes: int64(len(blob))}
+}
+
+// DigestForFile returns the Digest of the contents of a file.
+func DigestForFile(path string) (*reapi_proto.Digest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+	return &reapi_proto.Digest{Hash: hex.EncodeToString(h.Sum(nil)), SizeBytes: size}, nil
+}
+
+// marshalMessage returns the binary encoding of a message and its Digest.
+func marshalMessage(message proto.Message) ([]byte, *reapi_proto.Digest, error) {
+	blob, err := proto.Marshal(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	return blob, DigestForBlob(blob), nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoteexec
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"android/soong/remoteexec/reapi_proto"
+)
+
+func setUpTest(t *testing.T) (client *Client, server *LocalServer, execRoot string, cleanup func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "remoteexec_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err = NewLocalServer(filepath.Join(dir, "server"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+	go grpcServer.Serve(listener)
+
+	execRoot = filepath.Join(dir, "root")
+	writeFile(t, filepath.Join(execRoot, "a/input.txt"), "hello", 0666)
+	writeFile(t, filepath.Join(execRoot, "a/undeclared.txt"), "secret", 0666)
+	writeFile(t, filepath.Join(execRoot, "tools/tool.sh"), "#!/bin/sh\ncat $1 $1 > $2\n", 0777)
+
+	client, err = Dial(listener.Addr().String(), "test")
+	if err != nil {
+		grpcServer.Stop()
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return client, server, execRoot, func() {
+		client.Close()
+		grpcServer.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func writeFile(t *testing.T, path, contents string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(contents), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	client, server, execRoot, cleanup := setUpTest(t)
+	defer cleanup()
+
+	req := Request{
+		ExecRoot:  execRoot,
+		Inputs:    []string{"a/input.txt", "tools"},
+		Outputs:   []string{"out/output.txt"},
+		Arguments: []string{"/bin/sh", "-c", "tools/tool.sh a/input.txt out/output.txt && echo done"},
+	}
+
+	for i := 0; i < 2; i++ {
+		var stdout bytes.Buffer
+		req.Stdout = &stdout
+
+		exitCode, err := client.Run(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exitCode != 0 {
+			t.Errorf("expected exit code 0, got %d", exitCode)
+		}
+		if g, w := stdout.String(), "done\n"; g != w {
+			t.Errorf("expected stdout %q, got %q", w, g)
+		}
+
+		output, err := ioutil.ReadFile(filepath.Join(execRoot, "out/output.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g, w := string(output), "hellohello"; g != w {
+			t.Errorf("expected output %q, got %q", w, g)
+		}
+
+		os.Remove(filepath.Join(execRoot, "out/output.txt"))
+	}
+
+	if len(server.actionResults) != 1 {
+		t.Errorf("expected 1 cached action result, got %d", len(server.actionResults))
+	}
+}
+
+func TestRunUndeclaredInput(t *testing.T) {
+	client, _, execRoot, cleanup := setUpTest(t)
+	defer cleanup()
+
+	var stderr bytes.Buffer
+	exitCode, err := client.Run(context.Background(), Request{
+		ExecRoot:  execRoot,
+		Inputs:    []string{"a/input.txt"},
+		Outputs:   []string{"out/output.txt"},
+		Arguments: []string{"/bin/sh", "-c", "cat a/undeclared.txt > out/output.txt"},
+		Stderr:    &stderr,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode == 0 {
+		t.Errorf("expected non-zero exit code reading undeclared input")
+	}
+	if !strings.Contains(stderr.String(), "undeclared.txt") {
+		t.Errorf("expected stderr to mention undeclared.txt, got %q", stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(execRoot, "out/output.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected no output for a failed command, got %v", err)
+	}
+}
+
+func TestRunMissingOutput(t *testing.T) {
+	client, _, execRoot, cleanup := setUpTest(t)
+	defer cleanup()
+
+	_, err := client.Run(context.Background(), Request{
+		ExecRoot:  execRoot,
+		Inputs:    []string{"a/input.txt"},
+		Outputs:   []string{"out/output.txt"},
+		Arguments: []string{"/bin/true"},
+	})
+	if err == nil || !strings.Contains(err.Error(), `did not produce output "out/output.txt"`) {
+		t.Errorf("expected missing output error, got %v", err)
+	}
+}
+
+func TestRunInputOutsideExecRoot(t *testing.T) {
+	client, _, execRoot, cleanup := setUpTest(t)
+	defer cleanup()
+
+	_, err := client.Run(context.Background(), Request{
+		ExecRoot:  execRoot,
+		Inputs:    []string{"../server"},
+		Arguments: []string{"/bin/true"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "is not relative to the exec root") {
+		t.Errorf("expected exec root error, got %v", err)
+	}
+}
+
+func TestRunSystemTool(t *testing.T) {
+	client, _, execRoot, cleanup := setUpTest(t)
+	defer cleanup()
+
+	cp, err := exec.LookPath("cp")
+	if err != nil {
+		t.Skip("cp not found")
+	}
+
+	// Tools that are not in the exec root, like cp, are found through PATH on the server instead
+	// of being staged as inputs, as in the commands written by android.RemoteExecCommand.
+	exitCode, err := client.Run(context.Background(), Request{
+		ExecRoot:  execRoot,
+		Inputs:    []string{"a/input.txt"},
+		Outputs:   []string{"out/output.txt"},
+		Arguments: []string{"/bin/bash", "-c", "cp a/input.txt out/output.txt"},
+		Environment: []*reapi_proto.Command_EnvironmentVariable{
+			{Name: "PATH", Value: filepath.Dir(cp)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+
+	output, err := ioutil.ReadFile(filepath.Join(execRoot, "out/output.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := string(output), "hello"; g != w {
+		t.Errorf("expected output %q, got %q", w, g)
+	}
+}
+
+func TestRunLargeBlobs(t *testing.T) {
+	client, _, execRoot, cleanup := setUpTest(t)
+	defer cleanup()
+
+	// Larger than maxBatchSize so that it is uploaded and downloaded through the ByteStream
+	// service in multiple chunks.
+	large := strings.Repeat("0123456789abcdef", 3*maxBatchSize/16+1)
+	writeFile(t, filepath.Join(execRoot, "a/large.txt"), large, 0666)
+
+	exitCode, err := client.Run(context.Background(), Request{
+		ExecRoot:  execRoot,
+		Inputs:    []string{"a/large.txt"},
+		Outputs:   []string{"out/large.txt"},
+		Arguments: []string{"/bin/sh", "-c", "cat a/large.txt > out/large.txt"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exitCode != 0 {
+		t.Errorf("expected exit code 0, got %d", exitCode)
+	}
+
+	output, err := ioutil.ReadFile(filepath.Join(execRoot, "out/large.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != large {
+		t.Errorf("expected output of %d bytes to match input, got %d bytes", len(large), len(output))
+	}
+}
+
+func TestParseResourceName(t *testing.T) {
+	digest := DigestForBlob([]byte("hello"))
+
+	for _, name := range []string{
+		readResourceName("", digest),
+		readResourceName("projects/test/instances/default", digest),
+		writeResourceName("test", "4a1c3e0e-1b9f-4f6e-9c4d-4f0e3e2a1b7c", digest),
+	} {
+		parsed, err := parseResourceName(name)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if digestKey(parsed) != digestKey(digest) {
+			t.Errorf("%s: expected %s, got %s", name, digestKey(digest), digestKey(parsed))
+		}
+	}
+
+	for _, name := range []string{
+		"blobs/abc/5",
+		"test/blobs/" + digest.Hash + "/-1",
+		"test/uploads/uuid/" + digest.Hash + "/5",
+	} {
+		if _, err := parseResourceName(name); err == nil {
+			t.Errorf("%s: expected error for invalid resource name", name)
+		}
+	}
+}
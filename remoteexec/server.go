@@ -0,0 +1,490 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remoteexec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/bytestream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"android/soong/remoteexec/reapi_proto"
+)
+
+// LocalServer is a stand-in for a remote execution service that stores blobs in a local directory
+// and executes actions on the local machine, each in a new directory containing only its input
+// root.  It serves all instance names from the same CAS and action cache.  It is intended for
+// tests and for trying out remote execution without a remote execution service.
+type LocalServer struct {
+	dir string
+
+	lock          sync.Mutex
+	actionResults map[string]*reapi_proto.ActionResult
+}
+
+// NewLocalServer returns a LocalServer that stores its blobs and execution directories in dir.
+func NewLocalServer(dir string) (*LocalServer, error) {
+	for _, d := range []string{"cas", "exec"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0777); err != nil {
+			return nil, err
+		}
+	}
+	return &LocalServer{
+		dir:           dir,
+		actionResults: make(map[string]*reapi_proto.ActionResult),
+	}, nil
+}
+
+// Register registers the Execution, ActionCache, ContentAddressableStorage and ByteStream services
+// of the LocalServer with a gRPC server.
+func (s *LocalServer) Register(grpcServer *grpc.Server) {
+	reapi_proto.RegisterExecutionServer(grpcServer, s)
+	reapi_proto.RegisterActionCacheServer(grpcServer, s)
+	reapi_proto.RegisterContentAddressableStorageServer(grpcServer, s)
+	bytestream.RegisterByteStreamServer(grpcServer, s)
+}
+
+func (s *LocalServer) blobPath(digest *reapi_proto.Digest) string {
+	return filepath.Join(s.dir, "cas", digest.GetHash())
+}
+
+func (s *LocalServer) hasBlob(digest *reapi_proto.Digest) bool {
+	info, err := os.Stat(s.blobPath(digest))
+	return err == nil && info.Size() == digest.GetSizeBytes()
+}
+
+// FindMissingBlobs implements reapi_proto.ContentAddressableStorageServer.
+func (s *LocalServer) FindMissingBlobs(ctx context.Context, req *reapi_proto.FindMissingBlobsRequest) (*reapi_proto.FindMissingBlobsResponse, error) {
+	resp := &reapi_proto.FindMissingBlobsResponse{}
+	for _, digest := range req.BlobDigests {
+		if !s.hasBlob(digest) {
+			resp.MissingBlobDigests = append(resp.MissingBlobDigests, digest)
+		}
+	}
+	return resp, nil
+}
+
+// BatchUpdateBlobs implements reapi_proto.ContentAddressableStorageServer.
+func (s *LocalServer) BatchUpdateBlobs(ctx context.Context, req *reapi_proto.BatchUpdateBlobsRequest) (*reapi_proto.BatchUpdateBlobsResponse, error) {
+	resp := &reapi_proto.BatchUpdateBlobsResponse{}
+	for _, r := range req.Requests {
+		st := status.New(codes.OK, "")
+		if err := s.storeBlob(r.Digest, bytes.NewReader(r.Data)); err != nil {
+			st = status.New(codes.InvalidArgument, err.Error())
+		}
+		resp.Responses = append(resp.Responses, &reapi_proto.BatchUpdateBlobsResponse_Response{
+			Digest: r.Digest,
+			Status: st.Proto(),
+		})
+	}
+	return resp, nil
+}
+
+// BatchReadBlobs implements reapi_proto.ContentAddressableStorageServer.
+func (s *LocalServer) BatchReadBlobs(ctx context.Context, req *reapi_proto.BatchReadBlobsRequest) (*reapi_proto.BatchReadBlobsResponse, error) {
+	resp := &reapi_proto.BatchReadBlobsResponse{}
+	for _, digest := range req.Digests {
+		st := status.New(codes.OK, "")
+		data, err := ioutil.ReadFile(s.blobPath(digest))
+		if os.IsNotExist(err) {
+			st = status.New(codes.NotFound, "blob "+digestKey(digest)+" not found")
+		} else if err != nil {
+			st = status.New(codes.Internal, err.Error())
+		}
+		resp.Responses = append(resp.Responses, &reapi_proto.BatchReadBlobsResponse_Response{
+			Digest: digest,
+			Data:   data,
+			Status: st.Proto(),
+		})
+	}
+	return resp, nil
+}
+
+// Read implements bytestream.ByteStreamServer.
+func (s *LocalServer) Read(req *bytestream.ReadRequest, stream bytestream.ByteStream_ReadServer) error {
+	digest, err := parseResourceName(req.ResourceName)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	f, err := os.Open(s.blobPath(digest))
+	if os.IsNotExist(err) {
+		return status.Error(codes.NotFound, "blob "+digestKey(digest)+" not found")
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if req.ReadOffset > 0 {
+		if _, err := f.Seek(req.ReadOffset, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	var r io.Reader = f
+	if req.ReadLimit > 0 {
+		r = io.LimitReader(f, req.ReadLimit)
+	}
+
+	buf := make([]byte, byteStreamChunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&bytestream.ReadResponse{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// Write implements bytestream.ByteStreamServer.
+func (s *LocalServer) Write(stream bytestream.ByteStream_WriteServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	digest, err := parseResourceName(req.ResourceName)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	pr, pw := io.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		err := s.storeBlob(digest, pr)
+		// Unblock the writes below if storeBlob failed before reading all the data.
+		pr.CloseWithError(err)
+		errCh <- err
+	}()
+
+	offset := int64(0)
+	for {
+		if req.WriteOffset != offset {
+			err = status.Errorf(codes.InvalidArgument, "expected write offset %d, got %d", offset, req.WriteOffset)
+			break
+		}
+		if _, err = pw.Write(req.Data); err != nil {
+			break
+		}
+		offset += int64(len(req.Data))
+		if req.FinishWrite {
+			break
+		}
+		if req, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+
+	pw.CloseWithError(err)
+	if storeErr := <-errCh; storeErr != nil {
+		return status.Error(codes.InvalidArgument, storeErr.Error())
+	} else if err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(&bytestream.WriteResponse{CommittedSize: offset})
+}
+
+// QueryWriteStatus implements bytestream.ByteStreamServer.  Uploads cannot be resumed, so it is
+// not supported.
+func (s *LocalServer) QueryWriteStatus(ctx context.Context, req *bytestream.QueryWriteStatusRequest) (*bytestream.QueryWriteStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "resumable uploads are not supported")
+}
+
+// storeBlob writes a blob to the CAS after verifying that its contents match the digest.
+func (s *LocalServer) storeBlob(digest *reapi_proto.Digest, r io.Reader) error {
+	tmp, err := ioutil.TempFile(filepath.Join(s.dir, "cas"), ".upload")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	actual, err := DigestForFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if digestKey(actual) != digestKey(digest) {
+		return fmt.Errorf("blob contents have digest %s, expected %s", digestKey(actual), digestKey(digest))
+	}
+
+	return os.Rename(tmp.Name(), s.blobPath(digest))
+}
+
+func (s *LocalServer) storeBlobBytes(blob []byte) (*reapi_proto.Digest, error) {
+	digest := DigestForBlob(blob)
+	if s.hasBlob(digest) {
+		return digest, nil
+	}
+	return digest, s.storeBlob(digest, bytes.NewReader(blob))
+}
+
+func (s *LocalServer) readMessage(digest *reapi_proto.Digest, message proto.Message) error {
+	blob, err := ioutil.ReadFile(s.blobPath(digest))
+	if err != nil {
+		return fmt.Errorf("missing blob %s: %s", digestKey(digest), err)
+	}
+	return proto.Unmarshal(blob, message)
+}
+
+// GetActionResult implements reapi_proto.ActionCacheServer.
+func (s *LocalServer) GetActionResult(ctx context.Context, req *reapi_proto.GetActionResultRequest) (*reapi_proto.ActionResult, error) {
+	s.lock.Lock()
+	result := s.actionResults[digestKey(req.ActionDigest)]
+	s.lock.Unlock()
+
+	if result == nil {
+		return nil, status.Error(codes.NotFound, "action "+digestKey(req.ActionDigest)+" not found")
+	}
+	return result, nil
+}
+
+// UpdateActionResult implements reapi_proto.ActionCacheServer.
+func (s *LocalServer) UpdateActionResult(ctx context.Context, req *reapi_proto.UpdateActionResultRequest) (*reapi_proto.ActionResult, error) {
+	if req.ActionResult == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing action result")
+	}
+
+	s.lock.Lock()
+	s.actionResults[digestKey(req.ActionDigest)] = req.ActionResult
+	s.lock.Unlock()
+
+	return req.ActionResult, nil
+}
+
+// Execute implements reapi_proto.ExecutionServer.  The action is run synchronously, so the only Operation
+// sent on the stream is the completed one.
+func (s *LocalServer) Execute(req *reapi_proto.ExecuteRequest, stream reapi_proto.Execution_ExecuteServer) error {
+	resp := s.execute(req)
+
+	response, err := ptypes.MarshalAny(resp)
+	if err != nil {
+		return err
+	}
+
+	return stream.Send(&reapi_proto.Operation{
+		Name:   "operations/" + digestKey(req.ActionDigest),
+		Done:   true,
+		Result: &reapi_proto.Operation_Response{Response: response},
+	})
+}
+
+func (s *LocalServer) execute(req *reapi_proto.ExecuteRequest) *reapi_proto.ExecuteResponse {
+	if !req.SkipCacheLookup {
+		s.lock.Lock()
+		result := s.actionResults[digestKey(req.ActionDigest)]
+		s.lock.Unlock()
+		if result != nil {
+			return &reapi_proto.ExecuteResponse{Result: result, CachedResult: true}
+		}
+	}
+
+	action := &reapi_proto.Action{}
+	if err := s.readMessage(req.ActionDigest, action); err != nil {
+		return &reapi_proto.ExecuteResponse{Status: status.New(codes.FailedPrecondition, err.Error()).Proto()}
+	}
+
+	result, err := s.runAction(action)
+	if err != nil {
+		return &reapi_proto.ExecuteResponse{Status: status.New(codes.Internal, err.Error()).Proto()}
+	}
+
+	if result.ExitCode == 0 && !action.DoNotCache {
+		s.lock.Lock()
+		s.actionResults[digestKey(req.ActionDigest)] = result
+		s.lock.Unlock()
+	}
+
+	return &reapi_proto.ExecuteResponse{Result: result}
+}
+
+func (s *LocalServer) runAction(action *reapi_proto.Action) (*reapi_proto.ActionResult, error) {
+	command := &reapi_proto.Command{}
+	if err := s.readMessage(action.CommandDigest, command); err != nil {
+		return nil, err
+	}
+	if len(command.Arguments) == 0 {
+		return nil, fmt.Errorf("command has no arguments")
+	}
+
+	execRoot, err := ioutil.TempDir(filepath.Join(s.dir, "exec"), "action")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(execRoot)
+
+	if err := s.stageDirectory(action.InputRootDigest, execRoot); err != nil {
+		return nil, err
+	}
+
+	for _, output := range command.OutputFiles {
+		if err := os.MkdirAll(filepath.Join(execRoot, filepath.Dir(output)), 0777); err != nil {
+			return nil, err
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(command.Arguments[0], command.Arguments[1:]...)
+	cmd.Dir = filepath.Join(execRoot, command.WorkingDirectory)
+	cmd.Env = []string{}
+	for _, env := range command.EnvironmentVariables {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	result := &reapi_proto.ActionResult{}
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = 1
+			if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Exited() {
+				result.ExitCode = int32(ws.ExitStatus())
+			}
+		} else {
+			return nil, err
+		}
+	}
+
+	if result.StdoutDigest, err = s.storeBlobBytes(stdout.Bytes()); err != nil {
+		return nil, err
+	}
+	if result.StderrDigest, err = s.storeBlobBytes(stderr.Bytes()); err != nil {
+		return nil, err
+	}
+
+	if result.ExitCode != 0 {
+		return result, nil
+	}
+
+	for _, output := range command.OutputFiles {
+		path := filepath.Join(execRoot, output)
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		digest, err := DigestForFile(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		err = s.storeBlob(digest, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		result.OutputFiles = append(result.OutputFiles, &reapi_proto.OutputFile{
+			Path:         filepath.Clean(output),
+			Digest:       digest,
+			IsExecutable: info.Mode()&0111 != 0,
+		})
+	}
+
+	return result, nil
+}
+
+// stageDirectory copies the files in the Merkle tree rooted at digest into dir.
+func (s *LocalServer) stageDirectory(digest *reapi_proto.Digest, dir string) error {
+	directory := &reapi_proto.Directory{}
+	if err := s.readMessage(digest, directory); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	for _, file := range directory.Files {
+		if err := checkNodeName(file.Name); err != nil {
+			return err
+		}
+		mode := os.FileMode(0666)
+		if file.IsExecutable {
+			mode = 0777
+		}
+		if err := copyFile(s.blobPath(file.Digest), filepath.Join(dir, file.Name), mode); err != nil {
+			return fmt.Errorf("failed to stage %q: %s", file.Name, err)
+		}
+	}
+
+	for _, child := range directory.Directories {
+		if err := checkNodeName(child.Name); err != nil {
+			return err
+		}
+		if err := s.stageDirectory(child.Digest, filepath.Join(dir, child.Name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkNodeName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid name %q in input root", name)
+	}
+	return nil
+}
+
+func copyFile(from, to string, mode os.FileMode) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
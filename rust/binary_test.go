@@ -0,0 +1,77 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rust
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test that the prefer_dynamic property is handled correctly.
+func TestPreferDynamicBinary(t *testing.T) {
+	ctx := testRust(t, `
+		rust_binary_host {
+			name: "fizz-buzz-dynamic",
+			srcs: ["foo.rs"],
+			prefer_dynamic: true,
+		}
+
+		rust_binary_host {
+			name: "fizz-buzz",
+			srcs: ["foo.rs"],
+		}`)
+
+	fizzBuzz := ctx.ModuleForTests("fizz-buzz", "linux_glibc_x86_64").Output("fizz-buzz")
+	fizzBuzzDynamic := ctx.ModuleForTests("fizz-buzz-dynamic", "linux_glibc_x86_64").Output("fizz-buzz-dynamic")
+
+	if !strings.Contains(fizzBuzzDynamic.Args["rustcFlags"], "prefer-dynamic") {
+		t.Errorf("missing prefer-dynamic flag, rustcFlags: %#v", fizzBuzzDynamic.Args["rustcFlags"])
+	}
+
+	if strings.Contains(fizzBuzz.Args["rustcFlags"], "prefer-dynamic") {
+		t.Errorf("unexpected prefer-dynamic flag, rustcFlags: %#v", fizzBuzz.Args["rustcFlags"])
+	}
+}
+
+// Test that device binaries link against bionic and are installed.
+func TestDeviceBinary(t *testing.T) {
+	ctx := testRust(t, `
+		rust_binary {
+			name: "fizz-buzz",
+			srcs: ["foo.rs"],
+		}`)
+
+	module := ctx.ModuleForTests("fizz-buzz", "android_arm64_armv8-a")
+	rustc := module.Rule("rustc")
+
+	if !strings.Contains(rustc.Args["rustcFlags"], "--target=aarch64-linux-android") {
+		t.Errorf("missing target triple, rustcFlags: %#v", rustc.Args["rustcFlags"])
+	}
+	if !strings.Contains(rustc.Args["linkFlags"], "-pie") {
+		t.Errorf("missing -pie, linkFlags: %#v", rustc.Args["linkFlags"])
+	}
+	if !strings.Contains(rustc.Args["crtBegin"], "crtbegin_dynamic") ||
+		!strings.Contains(rustc.Args["crtEnd"], "crtend_android") {
+		t.Errorf("expected crtbegin_dynamic and crtend_android, got %q and %q",
+			rustc.Args["crtBegin"], rustc.Args["crtEnd"])
+	}
+
+	rust := module.Module().(*Module)
+	for _, lib := range []string{"libc", "libm", "libdl"} {
+		if !strings.Contains(strings.Join(rust.Properties.AndroidMkSharedLibs, " "), lib) {
+			t.Errorf("expected %q in shared libs, got %q", lib, rust.Properties.AndroidMkSharedLibs)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rust
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test that variants are being generated correctly, and that crate-types are correct.
+func TestLibraryVariants(t *testing.T) {
+	ctx := testRust(t, `
+		rust_library_host {
+			name: "libfoo",
+			srcs: ["foo.rs"],
+			crate_name: "foo",
+		}`)
+
+	for _, tc := range []struct {
+		variant   string
+		output    string
+		crateType string
+	}{
+		{"linux_glibc_x86_64_rlib", "libfoo.rlib", "--crate-type=rlib"},
+		{"linux_glibc_x86_64_dylib", "libfoo.dylib.so", "--crate-type=dylib"},
+		{"linux_glibc_x86_64_static", "libfoo.a", "--crate-type=staticlib"},
+		{"linux_glibc_x86_64_shared", "libfoo.so", "--crate-type=cdylib"},
+	} {
+		rustc := ctx.ModuleForTests("libfoo", tc.variant).Output(tc.output)
+		if !strings.Contains(rustc.Args["rustcFlags"], tc.crateType) {
+			t.Errorf("missing %q for %s variant, rustcFlags: %#v", tc.crateType, tc.variant,
+				rustc.Args["rustcFlags"])
+		}
+	}
+}
+
+// Test that the dylib and shared library variants set their soname.
+func TestSharedLibrarySoname(t *testing.T) {
+	ctx := testRust(t, `
+		rust_library {
+			name: "libfoo",
+			srcs: ["foo.rs"],
+			crate_name: "foo",
+		}`)
+
+	for variant, soname := range map[string]string{
+		"android_arm64_armv8-a_dylib":  "libfoo.dylib.so",
+		"android_arm64_armv8-a_shared": "libfoo.so",
+	} {
+		rustc := ctx.ModuleForTests("libfoo", variant).Rule("rustc")
+		if !strings.Contains(rustc.Args["linkFlags"], "-Wl,-soname="+soname) {
+			t.Errorf("expected %s variant to have soname %q, linkFlags: %#v", variant, soname,
+				rustc.Args["linkFlags"])
+		}
+		if !strings.Contains(rustc.Args["crtBegin"], "crtbegin_so") {
+			t.Errorf("expected %s variant to link crtbegin_so, got %q", variant, rustc.Args["crtBegin"])
+		}
+	}
+}
+
+// Test that libraries require a crate name.
+func TestLibraryCrateName(t *testing.T) {
+	testRustError(t, "crate_name must be defined for rust libraries", `
+		rust_library_host_rlib {
+			name: "libfoo",
+			srcs: ["foo.rs"],
+		}`)
+}
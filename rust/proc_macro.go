@@ -0,0 +1,70 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rust
+
+import (
+	"android/soong/android"
+)
+
+func init() {
+	android.RegisterModuleType("rust_proc_macro", ProcMacroFactory)
+}
+
+type procMacroDecorator struct {
+	*baseCompiler
+}
+
+var _ compiler = (*procMacroDecorator)(nil)
+
+// rust_proc_macro produces a procedural macro crate, a compiler plugin that is loaded by rustc
+// on the host when compiling the crates that depend on it.
+func ProcMacroFactory() android.Module {
+	module, _ := NewProcMacro(android.HostSupportedNoCross)
+	return module.Init()
+}
+
+func NewProcMacro(hod android.HostOrDeviceSupported) (*Module, *procMacroDecorator) {
+	module := newModule(hod, android.MultilibFirst)
+
+	procMacro := &procMacroDecorator{
+		baseCompiler: NewBaseCompiler("lib", "lib64", InstallInSystem),
+	}
+
+	module.compiler = procMacro
+
+	return module, procMacro
+}
+
+func (procMacro *procMacroDecorator) compile(ctx ModuleContext, flags Flags, deps PathDeps) android.Path {
+	if procMacro.crateName() == "" {
+		ctx.PropertyErrorf("crate_name", "crate_name must be defined for rust proc macros")
+		return nil
+	}
+
+	srcPath := srcPathFromModuleSrcs(ctx, procMacro.baseCompiler.Properties.Srcs)
+	if srcPath == nil {
+		return nil
+	}
+
+	fileName := procMacro.getStem(ctx) + ctx.toolchain().ProcMacroSuffix()
+	outputFile := android.PathForModuleOut(ctx, fileName)
+
+	TransformSrctoProcMacro(ctx, srcPath, deps, flags, outputFile)
+	return outputFile
+}
+
+func (procMacro *procMacroDecorator) install(ctx ModuleContext, file android.Path) {
+	// Proc macros are only loaded by rustc during the build, there is nothing to install.
+}
@@ -0,0 +1,219 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rust
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"android/soong/android"
+)
+
+var (
+	buildDir string
+)
+
+func setUp() {
+	var err error
+	buildDir, err = ioutil.TempDir("", "soong_rust_test")
+	if err != nil {
+		panic(err)
+	}
+}
+
+func tearDown() {
+	os.RemoveAll(buildDir)
+}
+
+func TestMain(m *testing.M) {
+	run := func() int {
+		setUp()
+		defer tearDown()
+
+		return m.Run()
+	}
+
+	os.Exit(run())
+}
+
+func testRustContext(t *testing.T, bp string) (*android.TestContext, android.Config) {
+	t.Helper()
+	config := android.TestArchConfig(buildDir, nil)
+
+	ctx := CreateTestContext(bp)
+	ctx.MockFileSystem(map[string][]byte{
+		"Android.bp": []byte(bp + GatherRequiredDepsForTest()),
+		"foo.rs":     nil,
+		"src/bar.rs": nil,
+		"liby.so":    nil,
+		"libz.so":    nil,
+		"foo.c":      nil,
+	})
+
+	return ctx, config
+}
+
+func testRust(t *testing.T, bp string) *android.TestContext {
+	t.Helper()
+	ctx, config := testRustContext(t, bp)
+
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	android.FailIfErrored(t, errs)
+	_, errs = ctx.PrepareBuildActions(config)
+	android.FailIfErrored(t, errs)
+
+	return ctx
+}
+
+func testRustError(t *testing.T, pattern string, bp string) {
+	t.Helper()
+	ctx, config := testRustContext(t, bp)
+
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	if len(errs) > 0 {
+		android.FailIfNoMatchingErrors(t, pattern, errs)
+		return
+	}
+
+	_, errs = ctx.PrepareBuildActions(config)
+	if len(errs) > 0 {
+		android.FailIfNoMatchingErrors(t, pattern, errs)
+		return
+	}
+
+	t.Fatalf("missing expected error %q (0 errors are returned)", pattern)
+}
+
+// Test that we can extract the lib name from a lib path.
+func TestLibNameFromFilePath(t *testing.T) {
+	barPath := android.PathForTesting("out/soong/.intermediates/external/libbar/libbar/linux_glibc_x86_64_shared/libbar.so")
+	libName := libNameFromFilePath(barPath)
+	expectedResult := "bar"
+
+	if libName != expectedResult {
+		t.Errorf("libNameFromFilePath returned the wrong name; expected '%#v', got '%#v'", expectedResult, libName)
+	}
+}
+
+// Test that we can extract the link path from a lib path.
+func TestLinkPathFromFilePath(t *testing.T) {
+	barPath := android.PathForTesting("out/soong/.intermediates/external/libbar/libbar/linux_glibc_x86_64_shared/libbar.so")
+	libName := linkPathFromFilePath(barPath)
+	expectedResult := "out/soong/.intermediates/external/libbar/libbar/linux_glibc_x86_64_shared/"
+
+	if libName != expectedResult {
+		t.Errorf("libNameFromFilePath returned the wrong name; expected '%#v', got '%#v'", expectedResult, libName)
+	}
+}
+
+// Test that the dependencies of a rust module are tracked for Make and passed to rustc.
+func TestDepsTracking(t *testing.T) {
+	ctx := testRust(t, `
+		rust_library_dylib {
+			name: "libfoo",
+			srcs: ["foo.rs"],
+			crate_name: "foo",
+		}
+		rust_library_rlib {
+			name: "libbar",
+			srcs: ["foo.rs"],
+			crate_name: "bar",
+		}
+		rust_proc_macro {
+			name: "libpm",
+			srcs: ["foo.rs"],
+			crate_name: "pm",
+		}
+		cc_library {
+			name: "libcfoo",
+			srcs: ["foo.c"],
+		}
+		rust_binary {
+			name: "fizz-buzz",
+			dylibs: ["libfoo"],
+			rlibs: ["libbar"],
+			proc_macros: ["libpm"],
+			static_libs: ["libcfoo"],
+			shared_libs: ["libcfoo"],
+			srcs: ["foo.rs"],
+		}
+	`)
+	module := ctx.ModuleForTests("fizz-buzz", "android_arm64_armv8-a")
+	rust := module.Module().(*Module)
+
+	if !android.InList("libfoo", rust.Properties.AndroidMkDylibs) {
+		t.Errorf("Dylib dependency not detected (dependency missing from AndroidMkDylibs)")
+	}
+	if !android.InList("libbar", rust.Properties.AndroidMkRlibs) {
+		t.Errorf("Rlib dependency not detected (dependency missing from AndroidMkRlibs)")
+	}
+	if !android.InList("libpm", rust.Properties.AndroidMkProcMacroLibs) {
+		t.Errorf("Proc macro dependency not detected (dependency missing from AndroidMkProcMacroLibs)")
+	}
+	if !android.InList("libcfoo", rust.Properties.AndroidMkSharedLibs) {
+		t.Errorf("Shared library dependency not detected (dependency missing from AndroidMkSharedLibs)")
+	}
+	if !android.InList("libcfoo", rust.Properties.AndroidMkStaticLibs) {
+		t.Errorf("Static library dependency not detected (dependency missing from AndroidMkStaticLibs)")
+	}
+
+	libFlags := module.Rule("rustc").Args["libFlags"]
+	for _, want := range []string{
+		"--extern foo=",
+		"--extern bar=",
+		"--extern pm=",
+		"-lstatic=cfoo",
+		"-ldylib=cfoo",
+	} {
+		if !strings.Contains(libFlags, want) {
+			t.Errorf("expected libFlags to contain %q, got %q", want, libFlags)
+		}
+	}
+}
+
+// Test that proc macros are built for the host when used by a device module.
+func TestProcMacroDeviceDeps(t *testing.T) {
+	ctx := testRust(t, `
+		rust_proc_macro {
+			name: "libpm",
+			srcs: ["foo.rs"],
+			crate_name: "pm",
+		}
+		rust_binary {
+			name: "fizz-buzz",
+			proc_macros: ["libpm"],
+			srcs: ["foo.rs"],
+		}
+	`)
+
+	pm := ctx.ModuleForTests("libpm", android.BuildOs.String()+"_x86_64").Output("libpm.so")
+	rustc := ctx.ModuleForTests("fizz-buzz", "android_arm64_armv8-a").Rule("rustc")
+	if !android.InList(pm.Output.String(), rustc.Implicits.Strings()) {
+		t.Errorf("expected device binary to depend on the host proc macro %q, got %q",
+			pm.Output.String(), rustc.Implicits.Strings())
+	}
+}
+
+// Test that the crate root must be a rust source file.
+func TestCrateRootExtension(t *testing.T) {
+	testRustError(t, `srcs: the crate root ".*foo.c" must be a .rs file`, `
+		rust_binary {
+			name: "fizz-buzz",
+			srcs: ["foo.c", "foo.rs"],
+		}
+	`)
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rust
+
+import (
+	"strings"
+	"testing"
+
+	"android/soong/android"
+)
+
+// Test that rust_test modules are built with the test harness and installed as native tests.
+func TestRustTest(t *testing.T) {
+	ctx := testRust(t, `
+		rust_test {
+			name: "my_test",
+			srcs: ["foo.rs"],
+		}`)
+
+	module := ctx.ModuleForTests("my_test", "android_arm64_armv8-a")
+	rustc := module.Rule("rustc")
+	if !strings.Contains(rustc.Args["rustcFlags"], "--test") {
+		t.Errorf("missing --test flag, rustcFlags: %#v", rustc.Args["rustcFlags"])
+	}
+
+	test := module.Module().(*Module).compiler.(*testDecorator)
+	if !strings.HasSuffix(test.path.String(), "data/nativetest64/my_test/my_test") {
+		t.Errorf("wrong install path %q", test.path.String())
+	}
+	if test.testConfig == nil {
+		t.Errorf("expected a test config to be autogenerated")
+	}
+}
+
+// Test that rust_test modules are built for both device arches but only the first host arch.
+func TestRustTestMultilib(t *testing.T) {
+	ctx := testRust(t, `
+		rust_test {
+			name: "my_test",
+			srcs: ["foo.rs"],
+			host_supported: true,
+		}`)
+
+	variants := ctx.ModuleVariantsForTests("my_test")
+	for _, v := range []string{"android_arm64_armv8-a", "android_arm_armv7-a-neon", "linux_glibc_x86_64"} {
+		if !android.InList(v, variants) {
+			t.Errorf("missing variant %q, variants: %q", v, variants)
+		}
+	}
+	if android.InList("linux_glibc_x86", variants) {
+		t.Errorf("unexpected 32-bit host variant, variants: %q", variants)
+	}
+}
@@ -0,0 +1,101 @@
+#!/bin/bash -eu
+
+# Copyright 2019 Google Inc. All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Script to print a human readable summary of an ABI diff report produced by header-abi-diff, listing
+# the changed, added and removed elements grouped by kind.
+# Inputs:
+#  Arguments:
+#   -l ${lib}: name of the library (required)
+#   -a ${arch}: architecture of the library (required)
+#   -r ${file}: the reference dump the library was compared against
+#   ${file}: the ABI diff report
+
+OPTSTRING=l:a:r:
+
+usage() {
+    cat <<EOF
+Usage: abi_diff_summary.sh -l lib -a arch [-r reference-dump] abidiff-report
+EOF
+    exit 1
+}
+
+lib=
+arch=
+reference=
+
+while getopts $OPTSTRING opt; do
+    case "$opt" in
+        l) lib="${OPTARG}" ;;
+        a) arch="${OPTARG}" ;;
+        r) reference="${OPTARG}" ;;
+        *) usage ;;
+    esac
+done
+shift $((OPTIND-1))
+
+if [ -z "${lib}" ] || [ -z "${arch}" ] || [ $# -ne 1 ]; then
+    usage
+fi
+report="$1"
+
+echo "error: ABI of ${lib} (${arch}) is not compatible with the reference dump${reference:+ ${reference}}" >&2
+
+if [ ! -f "${report}" ]; then
+    echo "    no ABI diff report was produced" >&2
+    exit 0
+fi
+
+# The report is a text protobuf whose top level repeated fields are the differences, e.g.
+#   function_diffs {
+#     name: "foo"
+#     ...
+#   }
+# Each difference is identified by its first name, linker_set_key or symbol_name field.
+awk '
+/^compatibility_status:/ {
+    status = $2
+    next
+}
+/^[a-z_]+ \{$/ {
+    kind = $1
+    key = ""
+    next
+}
+kind != "" && key == "" && /^  (name|linker_set_key|symbol_name): / {
+    key = $0
+    sub(/^  [a-z_]+: /, "", key)
+    gsub(/"/, "", key)
+    next
+}
+/^\}$/ {
+    if (kind != "") {
+        if (!(kind in count)) {
+            kinds[n++] = kind
+        }
+        count[kind]++
+        items[kind] = items[kind] "        " (key == "" ? "<unnamed>" : key) "\n"
+        kind = ""
+    }
+}
+END {
+    if (status != "") {
+        printf "    compatibility status: %s\n", status
+    }
+    for (i = 0; i < n; i++) {
+        printf "    %s (%d):\n%s", kinds[i], count[kinds[i]], items[kinds[i]]
+    }
+}
+' "${report}" >&2
@@ -0,0 +1,77 @@
+#!/bin/bash -eu
+
+# Copyright 2019 Google Inc. All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Script to check that a module only uses the symbols in the versions of the stubs of the shared
+# libraries it links against
+# Inputs:
+#  Environment:
+#   CROSS_COMPILE: prefix added to nm tool
+#  Arguments:
+#   -m ${name}: name of the module (required)
+#   -o ${file}: output file, written if the check passes (required)
+#   -s ${lib}:${version}:${file}: a stubs library the module links against, and the file listing
+#      the symbols of the library that are not in that version of its stubs
+#   ${files}: object files and static libraries linked into the module
+
+OPTSTRING=m:o:s:
+
+usage() {
+    cat <<EOF
+Usage: check_stub_symbols.sh -m module -o out-file [-s lib:version:omitted-symbols]... files...
+EOF
+    exit 1
+}
+
+module=
+outfile=
+stubs=()
+
+while getopts $OPTSTRING opt; do
+    case "$opt" in
+        m) module="${OPTARG}" ;;
+        o) outfile="${OPTARG}" ;;
+        s) stubs+=("${OPTARG}") ;;
+        *) usage ;;
+    esac
+done
+shift $((OPTIND-1))
+
+if [ -z "${module}" ] || [ -z "${outfile}" ]; then
+    usage
+fi
+
+undefined=$("${CROSS_COMPILE}nm" -u --format=posix "$@" | awk '{print $1}' | sort -u)
+
+failed=
+for stub in ${stubs[@]+"${stubs[@]}"}; do
+    lib="${stub%%:*}"
+    rest="${stub#*:}"
+    version="${rest%%:*}"
+    omitted="${rest#*:}"
+
+    used=$(comm -12 <(echo "${undefined}") <(sort -u "${omitted}"))
+    if [ -n "${used}" ]; then
+        echo "error: ${module} uses symbols of ${lib} that are not in version ${version} of its stubs:" >&2
+        echo "${used}" | sed 's/^/    /' >&2
+        failed=1
+    fi
+done
+
+if [ -n "${failed}" ]; then
+    exit 1
+fi
+
+touch "${outfile}"
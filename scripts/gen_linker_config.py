@@ -0,0 +1,289 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2019 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""A tool for generating the linker namespace configuration of the device.
+
+The configuration is generated from the linker metadata written by Soong, which lists the LLNDK,
+VNDK-SP and VNDK-core libraries and the libraries provided and required by each APEX.  It produces
+the ld.config.txt of the system partition, with a section for the binaries of the system partition
+and one for the binaries of the vendor partition, and an ld.config.txt for the binaries of each
+APEX.
+
+Extra links between namespaces can be added with JSON files of the form:
+
+  {
+    "links": [
+      {
+        "section": "vendor",
+        "from": "default",
+        "to": "system",
+        "shared_libs": ["libfoo.so"]
+      },
+      {
+        "section": "com.android.foo",
+        "from": "default",
+        "to": "platform",
+        "allow_all_shared_libs": true
+      }
+    ]
+  }
+
+where "section" is "system", "vendor" or the name of an APEX, and "from" and "to" are the names of
+namespaces in that section.  The namespace of an APEX in the system and vendor sections is named
+after the APEX, with dots replaced by underscores.
+"""
+
+from __future__ import print_function
+import argparse
+import collections
+import json
+import sys
+
+
+HEADER = '# This file is generated by Soong from the module graph. DO NOT EDIT.'
+
+
+class LinkerConfigError(Exception):
+  """An error in the linker metadata or in the extra links."""
+
+
+class Link(object):
+  """A link from a namespace to another namespace."""
+
+  def __init__(self):
+    self.shared_libs = []
+    self.allow_all_shared_libs = False
+
+  def add_shared_libs(self, libs):
+    for lib in libs:
+      if lib not in self.shared_libs:
+        self.shared_libs.append(lib)
+
+
+class Namespace(object):
+  """A linker namespace."""
+
+  def __init__(self, name, search_paths, permitted_paths=None, isolated=True, visible=False):
+    self.name = name
+    self.search_paths = search_paths
+    self.permitted_paths = permitted_paths if permitted_paths is not None else search_paths
+    self.isolated = isolated
+    self.visible = visible
+    self.links = collections.OrderedDict()
+
+  def link(self, to):
+    """Returns the link to the namespace named to, adding it if necessary."""
+    if to not in self.links:
+      self.links[to] = Link()
+    return self.links[to]
+
+  def add_link(self, to, shared_libs):
+    """Adds a link to the namespace named to for shared_libs, if there are any."""
+    if shared_libs:
+      self.link(to).add_shared_libs(shared_libs)
+
+  def lines(self):
+    prefix = 'namespace.%s.' % self.name
+    lines = [prefix + 'isolated = ' + ('true' if self.isolated else 'false')]
+    if self.visible:
+      lines.append(prefix + 'visible = true')
+    lines.append(prefix + 'search.paths = ' + ':'.join(self.search_paths))
+    if self.permitted_paths:
+      lines.append(prefix + 'permitted.paths = ' + ':'.join(self.permitted_paths))
+    if self.links:
+      lines.append(prefix + 'links = ' + ','.join(self.links))
+    for to, link in self.links.items():
+      if link.allow_all_shared_libs:
+        lines.append(prefix + 'link.%s.allow_all_shared_libs = true' % to)
+      elif link.shared_libs:
+        lines.append(prefix + 'link.%s.shared_libs = %s' % (to, ':'.join(link.shared_libs)))
+    return lines
+
+
+class Section(object):
+  """A section of an ld.config.txt, which applies to the binaries in dirs."""
+
+  def __init__(self, name, dirs):
+    self.name = name
+    self.dirs = dirs
+    self.namespaces = collections.OrderedDict()
+
+  def add_namespace(self, namespace):
+    self.namespaces[namespace.name] = namespace
+    return namespace
+
+  def namespace(self, name):
+    if name not in self.namespaces:
+      raise LinkerConfigError('section %s has no namespace %s' % (self.name, name))
+    return self.namespaces[name]
+
+  def lines(self):
+    lines = ['[%s]' % self.name]
+    additional = [name for name in self.namespaces if name != 'default']
+    if additional:
+      lines.append('additional.namespaces = ' + ','.join(additional))
+    for namespace in self.namespaces.values():
+      lines.append('')
+      lines.extend(namespace.lines())
+    return lines
+
+
+def apex_namespace_name(apex_name):
+  """Returns the name of the namespace of an APEX."""
+  return apex_name.replace('.', '_')
+
+
+def add_apex_namespaces(section, platform, apexes):
+  """Adds a namespace for each APEX to section, linked with the platform namespace."""
+  for apex in apexes:
+    lib_dir = '/apex/%s/${LIB}' % apex['name']
+    namespace = section.add_namespace(Namespace(apex_namespace_name(apex['name']), [lib_dir],
+                                                visible=True))
+    namespace.add_link(platform, apex.get('require_libs'))
+    section.namespace(platform).add_link(namespace.name, apex.get('provide_libs'))
+
+
+def system_sections(metadata):
+  """Returns the sections of the ld.config.txt of the system partition."""
+  vndk_ver = metadata.get('vndk_version') or ''
+  if vndk_ver and vndk_ver != 'current':
+    vndk_ver = '-' + vndk_ver
+  else:
+    vndk_ver = ''
+  vndk_dir = '/system/${LIB}/vndk' + vndk_ver
+  vndk_sp_dir = '/system/${LIB}/vndk-sp' + vndk_ver
+
+  llndk = metadata.get('llndk') or []
+  vndk_sp = metadata.get('vndk_sp') or []
+  apexes = metadata.get('apexes') or []
+
+  system = Section('system', ['/system/bin/', '/system/xbin/'])
+  system.add_namespace(Namespace('default', ['/system/${LIB}']))
+  add_apex_namespaces(system, 'default', apexes)
+
+  # Same process HALs are loaded by system processes from the vendor partition.
+  sphal = system.add_namespace(Namespace(
+      'sphal', ['/odm/${LIB}', '/vendor/${LIB}'], visible=True))
+  sphal.add_link('default', llndk)
+  sphal.add_link('vndk', vndk_sp)
+
+  vndk = system.add_namespace(Namespace(
+      'vndk', ['/odm/${LIB}/vndk-sp', '/vendor/${LIB}/vndk-sp', vndk_sp_dir],
+      ['/odm/${LIB}/hw', '/vendor/${LIB}/hw', vndk_sp_dir], visible=True))
+  vndk.add_link('default', llndk)
+
+  vendor = Section('vendor', ['/odm/bin/', '/vendor/bin/'])
+  vendor.add_namespace(Namespace(
+      'default', ['/odm/${LIB}', '/vendor/${LIB}', vndk_dir, vndk_sp_dir],
+      ['/odm', '/vendor', vndk_dir, vndk_sp_dir]))
+  vendor.add_namespace(Namespace('system', ['/system/${LIB}'], isolated=False))
+  vendor.namespace('default').add_link('system', llndk)
+  add_apex_namespaces(vendor, 'system', apexes)
+
+  return collections.OrderedDict([('system', system), ('vendor', vendor)])
+
+
+def apex_section(apex):
+  """Returns the section of the ld.config.txt of an APEX."""
+  lib_dir = '/apex/%s/${LIB}' % apex['name']
+  section = Section(apex_namespace_name(apex['name']), ['/apex/%s/bin/' % apex['name']])
+  default = section.add_namespace(Namespace('default', [lib_dir]))
+  platform = section.add_namespace(Namespace('platform', ['/system/${LIB}']))
+  default.add_link('platform', apex.get('require_libs'))
+  platform.add_link('default', apex.get('provide_libs'))
+  return section
+
+
+def add_extra_links(sections, extra_links):
+  """Adds the links from the extra links files to the sections, which are keyed by name."""
+  for link in extra_links.get('links') or []:
+    section = link.get('section')
+    if section not in sections:
+      raise LinkerConfigError('unknown section %s' % section)
+    from_namespace = sections[section].namespace(link.get('from'))
+    to_namespace = sections[section].namespace(link.get('to'))
+    l = from_namespace.link(to_namespace.name)
+    l.add_shared_libs(link.get('shared_libs') or [])
+    if link.get('allow_all_shared_libs'):
+      l.allow_all_shared_libs = True
+
+
+def write_config(out, sections):
+  """Writes an ld.config.txt containing sections to out."""
+  lines = [HEADER]
+  for section in sections:
+    for d in section.dirs:
+      lines.append('dir.%s = %s' % (section.name, d))
+  for section in sections:
+    lines.append('')
+    lines.extend(section.lines())
+  out.write('\n'.join(lines) + '\n')
+
+
+def parse_args():
+  """Parse commandline arguments."""
+
+  parser = argparse.ArgumentParser()
+  parser.add_argument('--metadata', required=True,
+                      help='file containing the linker metadata written by Soong')
+  parser.add_argument('--extra_links', action='append', default=[],
+                      help='file containing extra links between namespaces')
+  parser.add_argument('--system_out', required=True,
+                      help='file to write the ld.config.txt of the system partition to')
+  parser.add_argument('--apex_out', action='append', default=[],
+                      help='<apex>=<file> to write the ld.config.txt of an APEX to')
+  return parser.parse_args()
+
+
+def main():
+  """Program entry point."""
+  args = parse_args()
+
+  with open(args.metadata) as f:
+    metadata = json.load(f)
+
+  apexes = collections.OrderedDict()
+  for apex in metadata.get('apexes') or []:
+    apexes[apex['name']] = apex
+
+  try:
+    sections = system_sections(metadata)
+    for name, apex in apexes.items():
+      sections[name] = apex_section(apex)
+
+    for extra_links_file in args.extra_links:
+      with open(extra_links_file) as f:
+        add_extra_links(sections, json.load(f))
+
+    apex_outs = {}
+    for apex_out in args.apex_out:
+      name, _, out = apex_out.partition('=')
+      if name not in apexes:
+        raise LinkerConfigError('unknown APEX %s' % name)
+      apex_outs[name] = out
+  except LinkerConfigError as e:
+    print('error: %s' % e, file=sys.stderr)
+    sys.exit(1)
+
+  with open(args.system_out, 'w') as f:
+    write_config(f, [sections['system'], sections['vendor']])
+  for name, out in apex_outs.items():
+    with open(out, 'w') as f:
+      write_config(f, [sections[name]])
+
+
+if __name__ == '__main__':
+  main()
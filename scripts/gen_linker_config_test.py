@@ -0,0 +1,176 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2019 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for gen_linker_config.py."""
+
+import sys
+import unittest
+
+try:
+  from StringIO import StringIO
+except ImportError:
+  from io import StringIO
+
+import gen_linker_config
+
+sys.dont_write_bytecode = True
+
+
+METADATA = {
+    'vndk_version': '29',
+    'llndk': ['libc.so', 'libm.so'],
+    'vndk_sp': ['libcutils.so'],
+    'vndk_core': ['libfoo.so'],
+    'apexes': [
+        {
+            'name': 'com.android.foo',
+            'provide_libs': ['libfooapex.so'],
+            'require_libs': ['libc.so'],
+        },
+    ],
+}
+
+
+def config_lines(sections):
+  out = StringIO()
+  gen_linker_config.write_config(out, sections)
+  return out.getvalue().splitlines()
+
+
+class SystemSectionsTest(unittest.TestCase):
+  """Unit tests for system_sections function."""
+
+  def test_system(self):
+    """Test the namespaces of the binaries of the system partition."""
+    lines = config_lines(gen_linker_config.system_sections(METADATA).values())
+
+    for expected in [
+        'dir.system = /system/bin/',
+        'dir.vendor = /vendor/bin/',
+        '[system]',
+        'additional.namespaces = com_android_foo,sphal,vndk',
+        'namespace.default.links = com_android_foo',
+        'namespace.default.link.com_android_foo.shared_libs = libfooapex.so',
+        'namespace.com_android_foo.search.paths = /apex/com.android.foo/${LIB}',
+        'namespace.com_android_foo.link.default.shared_libs = libc.so',
+        'namespace.sphal.link.default.shared_libs = libc.so:libm.so',
+        'namespace.sphal.link.vndk.shared_libs = libcutils.so',
+        'namespace.vndk.search.paths = /odm/${LIB}/vndk-sp:/vendor/${LIB}/vndk-sp:'
+        '/system/${LIB}/vndk-sp-29',
+    ]:
+      self.assertIn(expected, lines)
+
+  def test_vendor(self):
+    """Test the namespaces of the binaries of the vendor partition."""
+    sections = gen_linker_config.system_sections(METADATA)
+    lines = config_lines([sections['vendor']])
+
+    for expected in [
+        '[vendor]',
+        'additional.namespaces = system,com_android_foo',
+        'namespace.default.search.paths = /odm/${LIB}:/vendor/${LIB}:/system/${LIB}/vndk-29:'
+        '/system/${LIB}/vndk-sp-29',
+        'namespace.default.link.system.shared_libs = libc.so:libm.so',
+        'namespace.system.isolated = false',
+        'namespace.system.link.com_android_foo.shared_libs = libfooapex.so',
+    ]:
+      self.assertIn(expected, lines)
+
+  def test_current_vndk(self):
+    """Test that the current VNDK has no version suffix."""
+    metadata = dict(METADATA, vndk_version='current')
+    sections = gen_linker_config.system_sections(metadata)
+    self.assertIn('/system/${LIB}/vndk-sp', sections['system'].namespace('vndk').search_paths)
+
+  def test_no_empty_links(self):
+    """Test that links without shared libraries are omitted."""
+    sections = gen_linker_config.system_sections({})
+    self.assertEqual(list(sections['system'].namespace('sphal').links), [])
+
+
+class ApexSectionTest(unittest.TestCase):
+  """Unit tests for apex_section function."""
+
+  def test_apex(self):
+    lines = config_lines([gen_linker_config.apex_section(METADATA['apexes'][0])])
+
+    self.assertEqual(lines, [
+        gen_linker_config.HEADER,
+        'dir.com_android_foo = /apex/com.android.foo/bin/',
+        '',
+        '[com_android_foo]',
+        'additional.namespaces = platform',
+        '',
+        'namespace.default.isolated = true',
+        'namespace.default.search.paths = /apex/com.android.foo/${LIB}',
+        'namespace.default.permitted.paths = /apex/com.android.foo/${LIB}',
+        'namespace.default.links = platform',
+        'namespace.default.link.platform.shared_libs = libc.so',
+        '',
+        'namespace.platform.isolated = true',
+        'namespace.platform.search.paths = /system/${LIB}',
+        'namespace.platform.permitted.paths = /system/${LIB}',
+        'namespace.platform.links = default',
+        'namespace.platform.link.default.shared_libs = libfooapex.so',
+    ])
+
+
+class AddExtraLinksTest(unittest.TestCase):
+  """Unit tests for add_extra_links function."""
+
+  def setUp(self):
+    self.sections = gen_linker_config.system_sections(METADATA)
+    self.sections['com.android.foo'] = gen_linker_config.apex_section(METADATA['apexes'][0])
+
+  def test_add_libs(self):
+    """Test that extra libraries are added to existing and new links."""
+    gen_linker_config.add_extra_links(self.sections, {'links': [
+        {'section': 'vendor', 'from': 'default', 'to': 'system', 'shared_libs': ['libbar.so']},
+        {'section': 'system', 'from': 'sphal', 'to': 'com_android_foo',
+         'shared_libs': ['libfooapex.so']},
+    ]})
+
+    vendor = self.sections['vendor'].namespace('default')
+    self.assertEqual(vendor.links['system'].shared_libs, ['libc.so', 'libm.so', 'libbar.so'])
+    sphal = self.sections['system'].namespace('sphal')
+    self.assertEqual(sphal.links['com_android_foo'].shared_libs, ['libfooapex.so'])
+
+  def test_allow_all(self):
+    """Test that links can allow all shared libraries."""
+    gen_linker_config.add_extra_links(self.sections, {'links': [
+        {'section': 'com.android.foo', 'from': 'default', 'to': 'platform',
+         'allow_all_shared_libs': True},
+    ]})
+
+    lines = config_lines([self.sections['com.android.foo']])
+    self.assertIn('namespace.default.link.platform.allow_all_shared_libs = true', lines)
+    self.assertNotIn('namespace.default.link.platform.shared_libs = libc.so', lines)
+
+  def test_unknown_section(self):
+    with self.assertRaises(gen_linker_config.LinkerConfigError):
+      gen_linker_config.add_extra_links(self.sections, {'links': [
+          {'section': 'product', 'from': 'default', 'to': 'system', 'shared_libs': ['a.so']},
+      ]})
+
+  def test_unknown_namespace(self):
+    with self.assertRaises(gen_linker_config.LinkerConfigError):
+      gen_linker_config.add_extra_links(self.sections, {'links': [
+          {'section': 'vendor', 'from': 'default', 'to': 'sphal', 'shared_libs': ['a.so']},
+      ]})
+
+
+if __name__ == '__main__':
+  unittest.main(verbosity=2)
@@ -0,0 +1,155 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2019 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""A tool for aggregating the license metadata of installed files into NOTICE.html and NOTICE.xml.
+
+The files have the same format as the NOTICE files generated by Make: the installed files are
+grouped by the text of their licenses, and each group lists the files followed by the license text.
+"""
+
+from __future__ import print_function
+import argparse
+import hashlib
+import json
+import sys
+from xml.sax.saxutils import escape, quoteattr
+
+
+HTML_HEADER = '''<html><head>
+<style type="text/css">
+body { padding: 0; font-family: sans-serif; }
+.same-license { background-color: #eeeeee; border-top: 20px solid white; padding: 10px; }
+.label { font-weight: bold; }
+.file-list { margin-left: 1em; color: blue; }
+</style>
+</head>
+<body topmargin="0" leftmargin="0" rightmargin="0" bottommargin="0">
+'''
+
+
+def parse_args():
+  """Parse commandline arguments."""
+
+  parser = argparse.ArgumentParser()
+  parser.add_argument('--metadata_list', required=True,
+                      help='file containing a whitespace separated list of license metadata files')
+  parser.add_argument('--html_out', required=True,
+                      help='file to write the NOTICE.html to')
+  parser.add_argument('--xml_out', required=True,
+                      help='file to write the NOTICE.xml to')
+  return parser.parse_args()
+
+
+def read_metadata(metadata_list):
+  """Reads the license metadata files listed in metadata_list."""
+
+  with open(metadata_list) as f:
+    files = f.read().split()
+
+  metadata = []
+  for metadata_file in files:
+    with open(metadata_file) as f:
+      metadata.append(json.load(f))
+  return metadata
+
+
+def group_by_license_text(metadata):
+  """Groups the installed files by the text of their licenses.
+
+  Returns a list of (content id, license text, installed files) tuples sorted by the first
+  installed file of each group.  Files without license texts are skipped.
+  """
+
+  groups = {}
+  for m in metadata:
+    texts = []
+    for license_text in m.get('license_texts') or []:
+      with open(license_text) as f:
+        texts.append(f.read())
+    if not texts:
+      continue
+    text = '\n'.join(texts)
+    data = text if isinstance(text, bytes) else text.encode('utf-8')
+    content_id = hashlib.md5(data).hexdigest()
+    group = groups.setdefault(content_id, (content_id, text, []))
+    group[2].append(m['installed'])
+
+  for group in groups.values():
+    group[2].sort()
+  return sorted(groups.values(), key=lambda group: group[2][0])
+
+
+def write_html(f, groups):
+  """Writes the NOTICE.html for the groups of files to f."""
+
+  f.write(HTML_HEADER)
+  f.write('<div class="toc">\n')
+  f.write('<ul>\n')
+  for content_id, _, files in groups:
+    for installed in files:
+      f.write('<li><a href="#id%s">%s</a></li>\n' % (content_id, escape(installed)))
+  f.write('</ul>\n')
+  f.write('</div><!-- table of contents -->\n')
+  f.write('<table cellpadding="0" cellspacing="0" border="0">\n')
+  for content_id, text, files in groups:
+    f.write('<tr id="id%s"><td class="same-license">\n' % content_id)
+    f.write('<div class="label">Notices for file(s):</div>\n')
+    f.write('<div class="file-list">\n')
+    for installed in files:
+      f.write('%s <br/>\n' % escape(installed))
+    f.write('</div><!-- file-list -->\n')
+    f.write('<pre class="license-text">\n')
+    f.write(escape(text))
+    f.write('</pre><!-- license-text -->\n')
+    f.write('</td></tr><!-- same-license -->\n')
+  f.write('</table>\n')
+  f.write('</body></html>\n')
+
+
+def write_xml(f, groups):
+  """Writes the NOTICE.xml for the groups of files to f."""
+
+  f.write('<?xml version="1.0" encoding="utf-8"?>\n')
+  f.write('<licenses>\n')
+  for content_id, _, files in groups:
+    for installed in files:
+      f.write('<file-name contentId=%s>%s</file-name>\n' % (quoteattr(content_id), escape(installed)))
+  for content_id, text, _ in groups:
+    f.write('<file-content contentId=%s><![CDATA[%s]]></file-content>\n' % (
+        quoteattr(content_id), text.replace(']]>', ']]]]><![CDATA[>')))
+  f.write('</licenses>\n')
+
+
+def main():
+  """Program entry point."""
+  try:
+    args = parse_args()
+
+    groups = group_by_license_text(read_metadata(args.metadata_list))
+
+    with open(args.html_out, 'w') as f:
+      write_html(f, groups)
+
+    with open(args.xml_out, 'w') as f:
+      write_xml(f, groups)
+
+  # pylint: disable=broad-except
+  except Exception as err:
+    print('error: ' + str(err), file=sys.stderr)
+    sys.exit(-1)
+
+if __name__ == '__main__':
+  main()
@@ -0,0 +1,101 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2019 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for generate_notice_files.py."""
+
+import os
+import shutil
+import sys
+import tempfile
+import unittest
+
+try:
+  from StringIO import StringIO
+except ImportError:
+  from io import StringIO
+
+import generate_notice_files
+
+sys.dont_write_bytecode = True
+
+
+class GroupByLicenseTextTest(unittest.TestCase):
+  """Unit tests for group_by_license_text function."""
+
+  def setUp(self):
+    self.tmp_dir = tempfile.mkdtemp()
+
+  def tearDown(self):
+    shutil.rmtree(self.tmp_dir)
+
+  def write_text(self, name, text):
+    path = os.path.join(self.tmp_dir, name)
+    with open(path, 'w') as f:
+      f.write(text)
+    return path
+
+  def test_group(self):
+    """Test that files with the same license texts are grouped together."""
+    apache = self.write_text('apache', 'Apache License')
+    mit = self.write_text('mit', 'MIT License')
+    metadata = [
+        {'installed': '/system/lib/libfoo.so', 'license_texts': [apache]},
+        {'installed': '/system/bin/bar', 'license_texts': [mit]},
+        {'installed': '/system/bin/baz', 'license_texts': [apache]},
+        {'installed': '/system/bin/qux', 'license_texts': None},
+    ]
+
+    groups = generate_notice_files.group_by_license_text(metadata)
+
+    self.assertEqual([(text, files) for _, text, files in groups], [
+        ('MIT License', ['/system/bin/bar']),
+        ('Apache License', ['/system/bin/baz', '/system/lib/libfoo.so']),
+    ])
+
+
+class WriteXmlTest(unittest.TestCase):
+  """Unit tests for write_xml function."""
+
+  def test_xml(self):
+    """Test the format of NOTICE.xml."""
+    output = StringIO()
+    generate_notice_files.write_xml(output, [('1234', 'License <text>', ['/system/bin/foo'])])
+
+    expected = ('<?xml version="1.0" encoding="utf-8"?>\n'
+                '<licenses>\n'
+                '<file-name contentId="1234">/system/bin/foo</file-name>\n'
+                '<file-content contentId="1234"><![CDATA[License <text>]]></file-content>\n'
+                '</licenses>\n')
+    self.assertEqual(output.getvalue(), expected)
+
+
+class WriteHtmlTest(unittest.TestCase):
+  """Unit tests for write_html function."""
+
+  def test_html(self):
+    """Test that NOTICE.html lists the files and escapes the license text."""
+    output = StringIO()
+    generate_notice_files.write_html(output, [('1234', 'License <text>', ['/system/bin/foo'])])
+
+    html = output.getvalue()
+    self.assertIn('<li><a href="#id1234">/system/bin/foo</a></li>\n', html)
+    self.assertIn('<tr id="id1234"><td class="same-license">\n', html)
+    self.assertIn('/system/bin/foo <br/>\n', html)
+    self.assertIn('License &lt;text&gt;</pre>', html)
+
+
+if __name__ == '__main__':
+  unittest.main(verbosity=2)
@@ -0,0 +1,141 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2019 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""A tool for generating the project.xml and lint.xml files passed to Android Lint."""
+
+from __future__ import print_function
+import argparse
+import sys
+from xml.sax.saxutils import escape, quoteattr
+
+
+def parse_args():
+  """Parse commandline arguments."""
+
+  parser = argparse.ArgumentParser()
+  parser.add_argument('--project_out', required=True,
+                      help='file to write the project.xml to')
+  parser.add_argument('--config_out', required=True,
+                      help='file to write the lint.xml configuration to')
+  parser.add_argument('--name', required=True,
+                      help='name of the module')
+  parser.add_argument('--srcs', dest='srcs', action='append', default=[],
+                      help='file containing a whitespace separated list of source files')
+  parser.add_argument('--generated_srcs', dest='generated_srcs', action='append', default=[],
+                      help='file containing a whitespace separated list of generated source files')
+  parser.add_argument('--resources', dest='resources', action='append', default=[],
+                      help='file containing a whitespace separated list of resource files')
+  parser.add_argument('--classes', dest='classes', action='append', default=[],
+                      help='jar file containing the classes compiled from the sources')
+  parser.add_argument('--classpath', dest='classpath', action='append', default=[],
+                      help='jar file on the classpath of the module')
+  parser.add_argument('--manifest', help='AndroidManifest.xml of the module')
+  parser.add_argument('--library', action='store_true',
+                      help='mark the module as a library')
+  parser.add_argument('--test', action='store_true',
+                      help='mark the module as a test')
+  parser.add_argument('--cache_dir', help='directory lint uses for its caches')
+  parser.add_argument('--disable_check', dest='checks', action=check_action('ignore'),
+                      default=[], help='disable a lint check')
+  parser.add_argument('--warning_check', dest='checks', action=check_action('warning'),
+                      help='make a lint check a warning')
+  parser.add_argument('--error_check', dest='checks', action=check_action('error'),
+                      help='make a lint check an error')
+  parser.add_argument('--fatal_check', dest='checks', action=check_action('fatal'),
+                      help='make a lint check fatal, failing the build')
+  return parser.parse_args()
+
+
+def check_action(severity):
+  """Returns an argparse action that records a (check, severity) pair."""
+
+  class CheckAction(argparse.Action):
+    def __call__(self, parser, namespace, values, option_string=None):
+      checks = getattr(namespace, self.dest) or []
+      setattr(namespace, self.dest, checks + [(values, severity)])
+
+  return CheckAction
+
+
+def read_lists(list_files):
+  """Reads the whitespace separated lists of files from list_files."""
+
+  files = []
+  for list_file in list_files:
+    with open(list_file) as f:
+      files.extend(f.read().split())
+  return files
+
+
+def write_project_xml(f, args):
+  """Writes the project.xml describing the module to f."""
+
+  android = args.manifest is not None
+
+  f.write('<?xml version="1.0" encoding="utf-8"?>\n')
+  f.write('<!-- THIS FILE WAS AUTOMATICALLY GENERATED; DO NOT EDIT. -->\n')
+  f.write('<project>\n')
+  f.write('<root dir="." />\n')
+  f.write('<module name=%s android="%s" library="%s"%s>\n' % (
+      quoteattr(args.name), str(android).lower(), str(args.library).lower(),
+      ' test="true"' if args.test else ''))
+  if args.manifest:
+    f.write('  <manifest file=%s />\n' % quoteattr(args.manifest))
+  for src in read_lists(args.srcs):
+    f.write('  <src file=%s />\n' % quoteattr(src))
+  for src in read_lists(args.generated_srcs):
+    f.write('  <src file=%s generated="true" />\n' % quoteattr(src))
+  for resource in read_lists(args.resources):
+    f.write('  <resource file=%s />\n' % quoteattr(resource))
+  for classes in args.classes:
+    f.write('  <classes jar=%s />\n' % quoteattr(classes))
+  for classpath in args.classpath:
+    f.write('  <classpath jar=%s />\n' % quoteattr(classpath))
+  if args.cache_dir:
+    f.write('  <cache dir=%s />\n' % quoteattr(args.cache_dir))
+  f.write('</module>\n')
+  f.write('</project>\n')
+
+
+def write_config_xml(f, checks):
+  """Writes the lint.xml setting the severity of checks to f."""
+
+  f.write('<?xml version="1.0" encoding="utf-8"?>\n')
+  f.write('<!-- THIS FILE WAS AUTOMATICALLY GENERATED; DO NOT EDIT. -->\n')
+  f.write('<lint>\n')
+  for check, severity in checks:
+    f.write('  <issue id=%s severity="%s" />\n' % (quoteattr(check), escape(severity)))
+  f.write('</lint>\n')
+
+
+def main():
+  """Program entry point."""
+  try:
+    args = parse_args()
+
+    with open(args.project_out, 'w') as f:
+      write_project_xml(f, args)
+
+    with open(args.config_out, 'w') as f:
+      write_config_xml(f, args.checks)
+
+  # pylint: disable=broad-except
+  except Exception as err:
+    print('error: ' + str(err), file=sys.stderr)
+    sys.exit(-1)
+
+if __name__ == '__main__':
+  main()
@@ -0,0 +1,125 @@
+#!/usr/bin/env python
+#
+# Copyright (C) 2019 The Android Open Source Project
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#      http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+#
+"""Unit tests for lint_project_xml.py."""
+
+import argparse
+import os
+import shutil
+import sys
+import tempfile
+import unittest
+
+try:
+  from StringIO import StringIO
+except ImportError:
+  from io import StringIO
+
+import lint_project_xml
+
+sys.dont_write_bytecode = True
+
+
+class WriteProjectXmlTest(unittest.TestCase):
+  """Unit tests for write_project_xml function."""
+
+  def setUp(self):
+    self.tmp_dir = tempfile.mkdtemp()
+
+  def tearDown(self):
+    shutil.rmtree(self.tmp_dir)
+
+  def write_list(self, name, files):
+    path = os.path.join(self.tmp_dir, name)
+    with open(path, 'w') as f:
+      f.write(' '.join(files))
+    return path
+
+  def project_args(self, **kwargs):
+    args = argparse.Namespace(name='foo', srcs=[], generated_srcs=[], resources=[],
+                              classes=[], classpath=[], manifest=None, library=False,
+                              test=False, cache_dir=None)
+    for key, value in kwargs.items():
+      setattr(args, key, value)
+    return args
+
+  def test_java_library(self):
+    """Test a module without a manifest."""
+    args = self.project_args(
+        srcs=[self.write_list('srcs.list', ['a.java', 'b.java'])],
+        classes=['foo.jar'], classpath=['bar.jar'], library=True)
+
+    output = StringIO()
+    lint_project_xml.write_project_xml(output, args)
+
+    expected = ('<?xml version="1.0" encoding="utf-8"?>\n'
+                '<!-- THIS FILE WAS AUTOMATICALLY GENERATED; DO NOT EDIT. -->\n'
+                '<project>\n'
+                '<root dir="." />\n'
+                '<module name="foo" android="false" library="true">\n'
+                '  <src file="a.java" />\n'
+                '  <src file="b.java" />\n'
+                '  <classes jar="foo.jar" />\n'
+                '  <classpath jar="bar.jar" />\n'
+                '</module>\n'
+                '</project>\n')
+    self.assertEqual(output.getvalue(), expected)
+
+  def test_android_app(self):
+    """Test a module with a manifest and resources."""
+    args = self.project_args(
+        srcs=[self.write_list('srcs.list', ['a.java'])],
+        generated_srcs=[self.write_list('gen.list', ['R.java'])],
+        resources=[self.write_list('res.list', ['res/values/strings.xml'])],
+        manifest='AndroidManifest.xml', cache_dir='cache')
+
+    output = StringIO()
+    lint_project_xml.write_project_xml(output, args)
+
+    expected = ('<?xml version="1.0" encoding="utf-8"?>\n'
+                '<!-- THIS FILE WAS AUTOMATICALLY GENERATED; DO NOT EDIT. -->\n'
+                '<project>\n'
+                '<root dir="." />\n'
+                '<module name="foo" android="true" library="false">\n'
+                '  <manifest file="AndroidManifest.xml" />\n'
+                '  <src file="a.java" />\n'
+                '  <src file="R.java" generated="true" />\n'
+                '  <resource file="res/values/strings.xml" />\n'
+                '  <cache dir="cache" />\n'
+                '</module>\n'
+                '</project>\n')
+    self.assertEqual(output.getvalue(), expected)
+
+
+class WriteConfigXmlTest(unittest.TestCase):
+  """Unit tests for write_config_xml function."""
+
+  def test_checks(self):
+    """Test setting the severity of checks."""
+    output = StringIO()
+    lint_project_xml.write_config_xml(output, [('NewApi', 'fatal'), ('Typos', 'ignore')])
+
+    expected = ('<?xml version="1.0" encoding="utf-8"?>\n'
+                '<!-- THIS FILE WAS AUTOMATICALLY GENERATED; DO NOT EDIT. -->\n'
+                '<lint>\n'
+                '  <issue id="NewApi" severity="fatal" />\n'
+                '  <issue id="Typos" severity="ignore" />\n'
+                '</lint>\n')
+    self.assertEqual(output.getvalue(), expected)
+
+
+if __name__ == '__main__':
+  unittest.main(verbosity=2)
@@ -0,0 +1,352 @@
+// Copyright (C) 2019 The Android Open Source Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdk
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/blueprint"
+	"github.com/google/blueprint/proptools"
+
+	"android/soong/android"
+	"android/soong/cc"
+	"android/soong/java"
+)
+
+var pctx = android.NewPackageContext("android/soong/sdk")
+
+func init() {
+	android.RegisterModuleType("sdk", ModuleFactory)
+}
+
+type dependencyTag struct {
+	blueprint.BaseDependencyTag
+	name string
+}
+
+// The members of an sdk are packaged into its snapshot, so their licenses apply to it.
+func (d dependencyTag) PropagatesLicenses() bool {
+	return true
+}
+
+var (
+	nativeSharedLibTag = dependencyTag{name: "nativeSharedLib"}
+	javaHeaderLibTag   = dependencyTag{name: "javaHeaderLib"}
+	stubLibTag         = dependencyTag{name: "stubLib"}
+)
+
+type sdkProperties struct {
+	// List of native shared libraries whose shared objects, for each device architecture, and
+	// exported headers are included in the snapshot.
+	Native_shared_libs []string
+
+	// List of java libraries whose header jars are included in the snapshot.
+	Java_header_libs []string
+
+	// List of java_sdk_library modules whose public API stubs are included in the snapshot.
+	Stub_libs []string
+
+	// The version of the snapshot, used in the name of the snapshot zip.  Defaults to "current".
+	Version *string
+}
+
+type sdk struct {
+	android.ModuleBase
+
+	properties sdkProperties
+
+	snapshotFile android.WritablePath
+}
+
+// nativeLibInfo holds the files of a native shared library that are packaged into the snapshot.
+type nativeLibInfo struct {
+	name string
+
+	// The architectures the library was built for, in the order of the device targets.
+	archs []string
+
+	// The shared object built for each architecture.
+	archOutputs map[string]android.Path
+
+	// The source directories of the headers exported by the library.
+	includeDirs android.Paths
+}
+
+// javaLibInfo holds the files of a java library that are packaged into the snapshot.
+type javaLibInfo struct {
+	name string
+	jars android.Paths
+}
+
+func (s *sdk) version() string {
+	return proptools.StringDefault(s.properties.Version, "current")
+}
+
+func (s *sdk) DepsMutator(ctx android.BottomUpMutatorContext) {
+	// Use *FarVariation* to depend on the shared, non-stub variant of the native libraries for each
+	// device architecture, as the sdk module itself has a single common variant.
+	for _, target := range ctx.Config().Targets[android.Android] {
+		ctx.AddFarVariationDependencies([]blueprint.Variation{
+			{Mutator: "arch", Variation: target.String()},
+			{Mutator: "image", Variation: "core"},
+			{Mutator: "link", Variation: "shared"},
+			{Mutator: "version", Variation: ""}, // "" is the non-stub variant
+		}, nativeSharedLibTag, s.properties.Native_shared_libs...)
+	}
+
+	ctx.AddVariationDependencies(nil, javaHeaderLibTag, s.properties.Java_header_libs...)
+	ctx.AddVariationDependencies(nil, stubLibTag, s.properties.Stub_libs...)
+}
+
+func (s *sdk) GenerateAndroidBuildActions(ctx android.ModuleContext) {
+	nativeLibs := make(map[string]*nativeLibInfo)
+	var javaLibs []javaLibInfo
+
+	ctx.VisitDirectDeps(func(module android.Module) {
+		name := ctx.OtherModuleName(module)
+
+		switch ctx.OtherModuleDependencyTag(module) {
+		case nativeSharedLibTag:
+			ccModule, ok := module.(*cc.Module)
+			if !ok || !ccModule.OutputFile().Valid() {
+				ctx.PropertyErrorf("native_shared_libs", "%q is not a cc shared library", name)
+				return
+			}
+			lib := nativeLibs[name]
+			if lib == nil {
+				lib = &nativeLibInfo{name: name, archOutputs: make(map[string]android.Path)}
+				nativeLibs[name] = lib
+			}
+			arch := ccModule.Target().Arch.ArchType.String()
+			lib.archs = append(lib.archs, arch)
+			lib.archOutputs[arch] = ccModule.OutputFile().Path()
+			lib.includeDirs = append(lib.includeDirs, ccModule.ExportedIncludeDirs()...)
+		case javaHeaderLibTag:
+			dep, ok := module.(java.Dependency)
+			if !ok {
+				ctx.PropertyErrorf("java_header_libs", "%q is not a java library", name)
+				return
+			}
+			javaLibs = append(javaLibs, javaLibInfo{name: name, jars: dep.HeaderJars()})
+		case stubLibTag:
+			dep, ok := module.(java.SdkLibraryDependency)
+			if !ok {
+				ctx.PropertyErrorf("stub_libs", "%q is not a java_sdk_library", name)
+				return
+			}
+			javaLibs = append(javaLibs, javaLibInfo{name: name, jars: dep.SdkHeaderJars(ctx, "current")})
+		}
+	})
+
+	if ctx.Failed() {
+		return
+	}
+
+	s.buildSnapshot(ctx, nativeLibs, javaLibs)
+}
+
+// buildSnapshot stages the files of the members into a directory, together with an Android.bp that
+// defines prebuilt modules for them, and zips it into <name>-<version>.zip.
+func (s *sdk) buildSnapshot(ctx android.ModuleContext, nativeLibs map[string]*nativeLibInfo,
+	javaLibs []javaLibInfo) {
+
+	snapshotDir := android.PathForModuleOut(ctx, "snapshot")
+	s.snapshotFile = android.PathForModuleOut(ctx, ctx.ModuleName()+"-"+s.version()+".zip")
+
+	rule := android.NewRuleBuilder()
+
+	rule.Command().Text("rm -rf").Text(snapshotDir.String())
+	rule.Command().Text("mkdir -p").Text(snapshotDir.String())
+
+	stage := func(from android.Path, rel string) {
+		to := snapshotDir.Join(ctx, rel)
+		rule.Command().Text("mkdir -p").Text(filepath.Dir(to.String()))
+		rule.Command().Text("cp -f").Input(from).Output(to)
+	}
+
+	bp := &generatedFile{}
+	bp.printfln("// This is auto-generated by the %s sdk module. DO NOT EDIT.", ctx.ModuleName())
+
+	var nativeLibNames []string
+	for name := range nativeLibs {
+		nativeLibNames = append(nativeLibNames, name)
+	}
+	sort.Strings(nativeLibNames)
+
+	for _, name := range nativeLibNames {
+		lib := nativeLibs[name]
+
+		// The exported headers are packaged with their paths relative to the top of the source tree, so
+		// that the include directories of different libraries can't collide.
+		var includeDirs []string
+		for _, dir := range android.FirstUniquePaths(lib.includeDirs) {
+			snapshotIncludeDir := filepath.Join("include", dir.String())
+			excludes := []string{filepath.Join(dir.String(), "**/Android.bp")}
+			for _, header := range ctx.GlobFiles(filepath.Join(dir.String(), "**/*"), excludes) {
+				if !isSnapshotHeader(header.Base()) {
+					continue
+				}
+				rel, err := filepath.Rel(dir.String(), header.String())
+				if err != nil {
+					panic(err)
+				}
+				stage(header, filepath.Join(snapshotIncludeDir, rel))
+			}
+			includeDirs = append(includeDirs, snapshotIncludeDir)
+		}
+
+		bp.printfln("")
+		bp.printfln("cc_prebuilt_library_shared {")
+		bp.indent()
+		bp.printfln("name: %q,", name)
+		bp.printfln("prefer: true,")
+		if len(includeDirs) > 0 {
+			bp.printfln("export_include_dirs: [%s],", quoteList(includeDirs))
+		}
+		bp.printfln("arch: {")
+		bp.indent()
+		for _, arch := range lib.archs {
+			output := lib.archOutputs[arch]
+			rel := filepath.Join(arch, "lib", output.Base())
+			stage(output, rel)
+
+			bp.printfln("%s: {", arch)
+			bp.indent()
+			bp.printfln("srcs: [%q],", rel)
+			bp.dedent()
+			bp.printfln("},")
+		}
+		bp.dedent()
+		bp.printfln("},")
+		bp.dedent()
+		bp.printfln("}")
+	}
+
+	sort.Slice(javaLibs, func(i, j int) bool { return javaLibs[i].name < javaLibs[j].name })
+
+	for _, lib := range javaLibs {
+		var jars []string
+		for _, jar := range lib.jars {
+			rel := filepath.Join("java", lib.name, jar.Base())
+			stage(jar, rel)
+			jars = append(jars, rel)
+		}
+
+		bp.printfln("")
+		bp.printfln("java_import {")
+		bp.indent()
+		bp.printfln("name: %q,", lib.name)
+		bp.printfln("prefer: true,")
+		bp.printfln("jars: [%s],", quoteList(jars))
+		bp.dedent()
+		bp.printfln("}")
+	}
+
+	bpFile := android.PathForModuleOut(ctx, "snapshot.bp")
+	ctx.Build(pctx, android.BuildParams{
+		Rule:        android.WriteFile,
+		Description: "sdk snapshot Android.bp",
+		Output:      bpFile,
+		Args: map[string]string{
+			"content": android.WriteFileContent(bp.String()),
+		},
+	})
+	stage(bpFile, "Android.bp")
+
+	rule.Command().
+		Tool(ctx.Config().HostToolPath(ctx, "soong_zip")).
+		FlagWithOutput("-o ", s.snapshotFile).
+		FlagWithArg("-C ", snapshotDir.String()).
+		FlagWithArg("-D ", snapshotDir.String())
+
+	rule.Build(pctx, ctx, "sdk_snapshot", "snapshot sdk "+ctx.ModuleName())
+}
+
+func (s *sdk) AndroidMk() android.AndroidMkData {
+	return android.AndroidMkData{
+		Custom: func(w io.Writer, name, prefix, moduleDir string, data android.AndroidMkData) {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, ".PHONY:", name)
+			fmt.Fprintln(w, name+":", s.snapshotFile.String())
+			fmt.Fprintln(w, "$(call dist-for-goals,"+name+","+s.snapshotFile.String()+")")
+		},
+	}
+}
+
+// sdk modules package prebuilt versions of native libraries, java libraries and their headers into a
+// versioned snapshot zip.  The snapshot contains an Android.bp that defines cc_prebuilt_library_shared
+// and java_import modules for them that are preferred over the sources, so that it can be unzipped
+// into another tree to replace the source modules.
+func ModuleFactory() android.Module {
+	module := &sdk{}
+	module.AddProperties(&module.properties)
+	android.InitAndroidArchModule(module, android.DeviceSupported, android.MultilibCommon)
+	return module
+}
+
+// generatedFile builds the content of an indented text file.
+type generatedFile struct {
+	content     strings.Builder
+	indentLevel int
+}
+
+func (f *generatedFile) indent() {
+	f.indentLevel++
+}
+
+func (f *generatedFile) dedent() {
+	f.indentLevel--
+}
+
+func (f *generatedFile) printfln(format string, args ...interface{}) {
+	line := fmt.Sprintf(format, args...)
+	if line != "" {
+		line = strings.Repeat("    ", f.indentLevel) + line
+	}
+	f.content.WriteString(line + "\n")
+}
+
+func (f *generatedFile) String() string {
+	return f.content.String()
+}
+
+// snapshotHeaderExtensions are the extensions of the files in the exported include directories of
+// native libraries that are packaged in the snapshot.  Files without an extension are also packaged,
+// as C++ standard library style headers have none.
+var snapshotHeaderExtensions = []string{".h", ".hh", ".hpp", ".inc"}
+
+// snapshotNonHeaderFiles are files without an extension that are commonly found next to headers and
+// are not packaged in the snapshot.
+var snapshotNonHeaderFiles = []string{"Android.bp", "LICENSE", "METADATA", "Makefile", "NOTICE", "OWNERS"}
+
+func isSnapshotHeader(name string) bool {
+	if android.InList(name, snapshotNonHeaderFiles) || strings.HasPrefix(name, "MODULE_LICENSE_") {
+		return false
+	}
+	ext := filepath.Ext(name)
+	return ext == "" || android.InList(ext, snapshotHeaderExtensions)
+}
+
+func quoteList(list []string) string {
+	quoted := make([]string, len(list))
+	for i, s := range list {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return strings.Join(quoted, ", ")
+}
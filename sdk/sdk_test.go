@@ -0,0 +1,214 @@
+// Copyright (C) 2019 The Android Open Source Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdk
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"android/soong/android"
+	"android/soong/cc"
+	"android/soong/java"
+)
+
+var buildDir string
+
+func setUp() {
+	var err error
+	buildDir, err = ioutil.TempDir("", "soong_sdk_test")
+	if err != nil {
+		panic(err)
+	}
+}
+
+func tearDown() {
+	os.RemoveAll(buildDir)
+}
+
+func TestMain(m *testing.M) {
+	run := func() int {
+		setUp()
+		defer tearDown()
+
+		return m.Run()
+	}
+
+	os.Exit(run())
+}
+
+func testSdk(t *testing.T, bp string) *android.TestContext {
+	t.Helper()
+
+	config := java.TestConfig(buildDir, nil)
+
+	ctx := android.NewTestArchContext()
+	ctx.RegisterModuleType("sdk", android.ModuleFactoryAdaptor(ModuleFactory))
+
+	ctx.RegisterModuleType("android_app", android.ModuleFactoryAdaptor(java.AndroidAppFactory))
+	ctx.RegisterModuleType("java_library", android.ModuleFactoryAdaptor(java.LibraryFactory))
+	ctx.RegisterModuleType("java_system_modules", android.ModuleFactoryAdaptor(java.SystemModulesFactory))
+	ctx.PreArchMutators(android.RegisterDefaultsPreArchMutators)
+
+	ctx.RegisterModuleType("cc_library", android.ModuleFactoryAdaptor(cc.LibraryFactory))
+	ctx.RegisterModuleType("cc_library_shared", android.ModuleFactoryAdaptor(cc.LibrarySharedFactory))
+	ctx.RegisterModuleType("cc_object", android.ModuleFactoryAdaptor(cc.ObjectFactory))
+	ctx.RegisterModuleType("llndk_library", android.ModuleFactoryAdaptor(cc.LlndkLibraryFactory))
+	ctx.RegisterModuleType("toolchain_library", android.ModuleFactoryAdaptor(cc.ToolchainLibraryFactory))
+	ctx.PreDepsMutators(func(ctx android.RegisterMutatorsContext) {
+		ctx.BottomUp("image", cc.ImageMutator).Parallel()
+		ctx.BottomUp("link", cc.LinkageMutator).Parallel()
+		ctx.BottomUp("vndk", cc.VndkMutator).Parallel()
+		ctx.BottomUp("version", cc.VersionMutator).Parallel()
+		ctx.BottomUp("begin", cc.BeginMutator).Parallel()
+	})
+
+	ctx.Register()
+
+	bp += java.GatherRequiredDepsForTest()
+	bp += cc.GatherRequiredDepsForTest(android.Android)
+
+	ctx.MockFileSystem(map[string][]byte{
+		"Android.bp":              []byte(bp),
+		"a.java":                  nil,
+		"mylib.cpp":               nil,
+		"include/mylib.h":         nil,
+		"include/nested/nested.h": nil,
+		"include/nested/cxx.hpp":  nil,
+		"include/noext":           nil,
+		"include/impl.cpp":        nil,
+		"include/Android.bp":      nil,
+		"include/OWNERS":          nil,
+
+		"prebuilts/sdk/current/core/android.jar":    nil,
+		"prebuilts/sdk/current/public/android.jar":  nil,
+		"prebuilts/sdk/tools/core-lambda-stubs.jar": nil,
+
+		// For framework-res, which is an implicit dependency for framework
+		"AndroidManifest.xml":                   nil,
+		"build/target/product/security/testkey": nil,
+
+		"jdk8/jre/lib/jce.jar": nil,
+		"jdk8/jre/lib/rt.jar":  nil,
+		"jdk8/lib/tools.jar":   nil,
+	})
+
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	android.FailIfErrored(t, errs)
+	_, errs = ctx.PrepareBuildActions(config)
+	android.FailIfErrored(t, errs)
+
+	return ctx
+}
+
+func TestSdkSnapshot(t *testing.T) {
+	ctx := testSdk(t, `
+		sdk {
+			name: "mysdk",
+			native_shared_libs: ["mylib"],
+			java_header_libs: ["myjavalib"],
+			version: "1",
+		}
+
+		cc_library_shared {
+			name: "mylib",
+			srcs: ["mylib.cpp"],
+			export_include_dirs: ["include"],
+			system_shared_libs: [],
+			stl: "none",
+		}
+
+		java_library {
+			name: "myjavalib",
+			srcs: ["a.java"],
+		}
+	`)
+
+	mysdk := ctx.ModuleForTests("mysdk", "android_common")
+
+	snapshot := mysdk.Output("mysdk-1.zip")
+	inputs := snapshot.Implicits.Strings()
+
+	expectedInputs := []string{
+		ctx.ModuleForTests("mylib", "android_arm64_armv8-a_core_shared").Module().(*cc.Module).OutputFile().Path().String(),
+		ctx.ModuleForTests("mylib", "android_arm_armv7-a-neon_core_shared").Module().(*cc.Module).OutputFile().Path().String(),
+		"include/mylib.h",
+		"include/nested/nested.h",
+		"include/nested/cxx.hpp",
+		"include/noext",
+	}
+	expectedInputs = append(expectedInputs,
+		ctx.ModuleForTests("myjavalib", "android_common").Module().(java.Dependency).HeaderJars().Strings()...)
+	for _, expected := range expectedInputs {
+		if !android.InList(expected, inputs) {
+			t.Errorf("expected snapshot to contain %q, got %q", expected, inputs)
+		}
+	}
+
+	// Only headers are packaged from the exported include directories, in particular not an Android.bp
+	// file that would redefine the source modules in the tree the snapshot is used in.
+	for _, unexpected := range []string{"include/impl.cpp", "include/Android.bp", "include/OWNERS"} {
+		if android.InList(unexpected, inputs) {
+			t.Errorf("expected snapshot not to contain %q, got %q", unexpected, inputs)
+		}
+	}
+
+	bp := mysdk.Output("snapshot.bp").Args["content"]
+	bp = strings.Replace(bp, `\n`, "\n", -1)
+
+	expectedBp := []string{`
+cc_prebuilt_library_shared {
+    name: "mylib",
+    prefer: true,
+    export_include_dirs: ["include/include"],
+    arch: {
+        arm64: {
+            srcs: ["arm64/lib/mylib.so"],
+        },
+        arm: {
+            srcs: ["arm/lib/mylib.so"],
+        },
+    },
+}
+`, `
+java_import {
+    name: "myjavalib",
+    prefer: true,
+    jars: ["java/myjavalib/myjavalib.jar"],
+}
+`}
+	for _, expected := range expectedBp {
+		if !strings.Contains(bp, expected) {
+			t.Errorf("expected snapshot Android.bp to contain:\n%s\ngot:\n%s", expected, bp)
+		}
+	}
+}
+
+func TestSdkSnapshotDefaultVersion(t *testing.T) {
+	ctx := testSdk(t, `
+		sdk {
+			name: "mysdk",
+			java_header_libs: ["myjavalib"],
+		}
+
+		java_library {
+			name: "myjavalib",
+			srcs: ["a.java"],
+		}
+	`)
+
+	ctx.ModuleForTests("mysdk", "android_common").Output("mysdk-current.zip")
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sh
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"android/soong/android"
+)
+
+var buildDir string
+
+func setUp() {
+	var err error
+	buildDir, err = ioutil.TempDir("", "soong_sh_test")
+	if err != nil {
+		panic(err)
+	}
+}
+
+func tearDown() {
+	os.RemoveAll(buildDir)
+}
+
+func TestMain(m *testing.M) {
+	run := func() int {
+		setUp()
+		defer tearDown()
+
+		return m.Run()
+	}
+
+	os.Exit(run())
+}
+
+func testShBinary(t *testing.T, bp string) *android.TestContext {
+	t.Helper()
+
+	config := android.TestArchConfig(buildDir, nil)
+
+	ctx := android.NewTestArchContext()
+	ctx.RegisterModuleType("sh_test", android.ModuleFactoryAdaptor(ShTestFactory))
+	ctx.RegisterModuleType("sh_test_host", android.ModuleFactoryAdaptor(ShTestHostFactory))
+	ctx.Register()
+	ctx.MockFileSystem(map[string][]byte{
+		"Android.bp":         []byte(bp),
+		"test.sh":            nil,
+		"testdata/data1":     nil,
+		"testdata/sub/data2": nil,
+		"sh_test.xml":        nil,
+	})
+	_, errs := ctx.ParseFileList(".", []string{"Android.bp"})
+	android.FailIfErrored(t, errs)
+	_, errs = ctx.PrepareBuildActions(config)
+	android.FailIfErrored(t, errs)
+
+	return ctx
+}
+
+func androidMkEntries(t *testing.T, data android.AndroidMkData) string {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	for _, extra := range data.Extra {
+		extra(buf, data.OutputFile.Path())
+	}
+	return buf.String()
+}
+
+func TestShTest(t *testing.T) {
+	ctx := testShBinary(t, `
+		sh_test {
+			name: "foo",
+			src: "test.sh",
+			filename: "test.sh",
+			test_suites: ["general-tests"],
+			test_config: "sh_test.xml",
+			data: [
+				"testdata/data1",
+				"testdata/sub/data2",
+			],
+		}
+	`)
+
+	mod := ctx.ModuleForTests("foo", "android_arm64_armv8-a").Module().(*ShTest)
+
+	data := mod.AndroidMk()
+	if data.Class != "NATIVE_TESTS" {
+		t.Errorf("expected class NATIVE_TESTS, got %q", data.Class)
+	}
+
+	entries := androidMkEntries(t, data)
+	for _, want := range []string{
+		"LOCAL_MODULE_STEM := test.sh\n",
+		"LOCAL_COMPATIBILITY_SUITE := general-tests\n",
+		"LOCAL_FULL_TEST_CONFIG := sh_test.xml\n",
+		"LOCAL_TEST_DATA := :testdata/data1 :testdata/sub/data2\n",
+	} {
+		if !strings.Contains(entries, want) {
+			t.Errorf("expected androidmk entries to contain %q, got:\n%s", want, entries)
+		}
+	}
+}
+
+func TestShTestHostAutoGenTestConfig(t *testing.T) {
+	ctx := testShBinary(t, `
+		sh_test_host {
+			name: "foo",
+			src: "test.sh",
+		}
+	`)
+
+	variant := ctx.ModuleForTests("foo", android.BuildOs.String()+"_x86_64")
+	mod := variant.Module().(*ShTest)
+	if !mod.Host() {
+		t.Errorf("host bit is not set for a sh_test_host module.")
+	}
+
+	autogen := variant.Rule("autogenTestConfig")
+	if autogen.Output.String() != mod.testConfig.String() {
+		t.Errorf("expected test config %q, got %q", autogen.Output.String(), mod.testConfig.String())
+	}
+	if template := autogen.Args["template"]; template != "${ShellTestConfigTemplate}" {
+		t.Errorf("expected the shell test config template, got %q", template)
+	}
+}
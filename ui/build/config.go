@@ -31,10 +31,11 @@ type Config struct{ *configImpl }
 
 type configImpl struct {
 	// From the environment
-	arguments []string
-	goma      bool
-	environ   *Environment
-	distDir   string
+	arguments        []string
+	goma             bool
+	environ          *Environment
+	distDir          string
+	buildEventStream string
 
 	// From the arguments
 	parallel   int
@@ -93,6 +94,10 @@ func NewConfig(ctx Context, args ...string) Config {
 		ret.distDir = filepath.Join(ret.OutDir(), "dist")
 	}
 
+	if stream, ok := ret.environ.Get("SOONG_BUILD_EVENT_STREAM"); ok {
+		ret.buildEventStream = stream
+	}
+
 	ret.environ.Unset(
 		// We're already using it
 		"USE_SOONG_UI",
@@ -117,6 +122,9 @@ func NewConfig(ctx Context, args ...string) Config {
 		// This is handled above too, and set for individual commands later
 		"DIST_DIR",
 
+		// Used by soong_ui only
+		"SOONG_BUILD_EVENT_STREAM",
+
 		// Variables that have caused problems in the past
 		"CDPATH",
 		"DISPLAY",
@@ -426,6 +434,13 @@ func (c *configImpl) Checkbuild() bool {
 	return c.checkbuild
 }
 
+// BuildEventStream returns where to write the stream of build events, either
+// the name of a file or "unix:" followed by the path of a unix socket. It is
+// empty if no stream should be written.
+func (c *configImpl) BuildEventStream() string {
+	return c.buildEventStream
+}
+
 func (c *configImpl) Dist() bool {
 	return c.dist
 }
@@ -18,13 +18,17 @@ import (
 	"android/soong/finder"
 	"android/soong/finder/fs"
 	"android/soong/ui/logger"
+	"bufio"
 	"bytes"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"android/soong/ui/metrics"
+	"android/soong/ui/metrics/metrics_proto"
 )
 
 // This file provides an interface to the Finder for use in Soong UI
@@ -64,6 +68,7 @@ func NewSourceFinder(ctx Context, config Config) (f *finder.Finder) {
 			"Android.bp",
 			"Blueprints",
 			"CleanSpec.mk",
+			"neverallow.bp",
 			"OWNERS",
 			"TEST_MAPPING",
 		},
@@ -117,6 +122,12 @@ func FindSources(ctx Context, config Config, f *finder.Finder) {
 		ctx.Fatalf("Could not find TEST_MAPPING: %v", err)
 	}
 
+	neverallowPolicies := f.FindNamedAt(".", "neverallow.bp")
+	err = dumpListToFile(neverallowPolicies, filepath.Join(dumpDir, "neverallow.bp.list"))
+	if err != nil {
+		ctx.Fatalf("Could not find neverallow.bp: %v", err)
+	}
+
 	androidBps := f.FindNamedAt(".", "Android.bp")
 	androidBps = append(androidBps, f.FindNamedAt("build/blueprint", "Blueprints")...)
 	if len(androidBps) == 0 {
@@ -128,6 +139,44 @@ func FindSources(ctx Context, config Config, f *finder.Finder) {
 	}
 }
 
+// makeModuleTypeRe matches the line that defines a module in an Android.mk file, e.g.
+// "include $(BUILD_SHARED_LIBRARY)", and captures the name of the module type.
+var makeModuleTypeRe = regexp.MustCompile(`^\s*-?include\s+\$\((BUILD_[A-Z0-9_]+)\)\s*$`)
+
+// RecordMakeModuleTypes counts the modules of each module type defined in the Android.mk files
+// known to <f>, and records them in the build metrics.  Android.mk files that are not included by
+// the build are counted too.
+func RecordMakeModuleTypes(ctx Context, f *finder.Finder) {
+	if ctx.Metrics == nil {
+		return
+	}
+
+	ctx.BeginTrace(metrics.RunSetupTool, "make module types")
+	defer ctx.EndTrace()
+
+	counts := make(map[string]int)
+	for _, androidMk := range f.FindNamedAt(".", "Android.mk") {
+		file, err := os.Open(androidMk)
+		if err != nil {
+			ctx.Verbosef("Could not read %s: %v", androidMk, err)
+			continue
+		}
+		countMakeModuleTypes(file, counts)
+		file.Close()
+	}
+
+	ctx.Metrics.SetModuleTypes(metrics_proto.ModuleTypeInfo_MAKE, counts)
+}
+
+func countMakeModuleTypes(r io.Reader, counts map[string]int) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if match := makeModuleTypeRe.FindStringSubmatch(scanner.Text()); match != nil {
+			counts[match[1]]++
+		}
+	}
+}
+
 func dumpListToFile(list []string, filePath string) (err error) {
 	desiredText := strings.Join(list, "\n")
 	desiredBytes := []byte(desiredText)
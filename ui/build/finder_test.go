@@ -0,0 +1,55 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCountMakeModuleTypes(t *testing.T) {
+	androidMk := `
+LOCAL_PATH := $(call my-dir)
+
+include $(CLEAR_VARS)
+LOCAL_MODULE := libfoo
+include $(BUILD_SHARED_LIBRARY)
+
+include $(CLEAR_VARS)
+LOCAL_MODULE := libfoo
+  include $(BUILD_STATIC_LIBRARY)
+
+include $(CLEAR_VARS)
+LOCAL_MODULE := foo
+-include $(BUILD_PREBUILT)
+
+# include $(BUILD_EXECUTABLE)
+include $(BUILD_SYSTEM)/base_rules.mk
+include $(call all-makefiles-under,$(LOCAL_PATH))
+`
+
+	counts := map[string]int{"BUILD_SHARED_LIBRARY": 1}
+	countMakeModuleTypes(strings.NewReader(androidMk), counts)
+
+	expected := map[string]int{
+		"BUILD_SHARED_LIBRARY": 2,
+		"BUILD_STATIC_LIBRARY": 1,
+		"BUILD_PREBUILT":       1,
+	}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("expected module types %v, got %v", expected, counts)
+	}
+}
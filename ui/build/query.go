@@ -0,0 +1,327 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"android/soong/ui/metrics"
+)
+
+// DumpModuleGraph runs the product config and Soong, then runs soong_build again with
+// --module_graph_file to write the resolved module graph.  It returns the path to the module graph
+// file, which can be loaded with LoadModuleGraph.
+func DumpModuleGraph(ctx Context, config Config) string {
+	Build(ctx, config, BuildProductConfig|BuildSoong)
+
+	ctx.BeginTrace(metrics.RunSoong, "module graph")
+	defer ctx.EndTrace()
+
+	queryDir := filepath.Join(config.SoongOutDir(), ".query")
+	ensureEmptyDirectoriesExist(ctx, queryDir)
+
+	moduleGraphFile := filepath.Join(queryDir, "module_graph.json")
+
+	// Write the ninja file to a separate directory so that the next build does not see a stale
+	// build.ninja file.
+	cmd := Command(ctx, config, "soong_build",
+		filepath.Join(config.SoongOutDir(), ".bootstrap/bin/soong_build"),
+		"-b", config.SoongOutDir(),
+		"-n", config.OutDir(),
+		"-l", filepath.Join(config.FileListDir(), "Android.bp.list"),
+		"-d", filepath.Join(queryDir, "build.ninja.d"),
+		"-o", filepath.Join(queryDir, "build.ninja"),
+		"--module_graph_file", moduleGraphFile,
+		"Android.bp")
+	cmd.Sandbox = soongSandbox
+	cmd.RunAndPrintOrFatal()
+
+	return moduleGraphFile
+}
+
+// A ModuleGraphNode is a single variant of a module in the module graph written by soong_build.
+type ModuleGraphNode struct {
+	Name      string
+	Namespace string
+	Type      string
+	Blueprint string
+	Line      int
+	Variant   string
+	Os        string
+	Arch      string
+	Deps      []ModuleGraphEdge
+}
+
+// A ModuleGraphEdge is a dependency from a ModuleGraphNode on a variant of another module.
+type ModuleGraphEdge struct {
+	Name      string
+	Namespace string
+	Variant   string
+	Tag       string
+}
+
+type moduleGraphKey struct {
+	name, namespace, variant string
+}
+
+func (n *ModuleGraphNode) key() moduleGraphKey {
+	return moduleGraphKey{n.Name, n.Namespace, n.Variant}
+}
+
+func (e ModuleGraphEdge) key() moduleGraphKey {
+	return moduleGraphKey{e.Name, e.Namespace, e.Variant}
+}
+
+func (n *ModuleGraphNode) String() string {
+	name := n.Name
+	if n.Namespace != "" && n.Namespace != "." {
+		name = "//" + n.Namespace + ":" + n.Name
+	}
+	if n.Variant == "" {
+		return name
+	}
+	return name + " [" + n.Variant + "]"
+}
+
+// ModuleGraph is the module graph written by soong_build, indexed for queries.
+type ModuleGraph struct {
+	nodes  map[moduleGraphKey]*ModuleGraphNode
+	byName map[string][]*ModuleGraphNode
+	rdeps  map[moduleGraphKey][]moduleGraphReverseEdge
+}
+
+type moduleGraphReverseEdge struct {
+	from *ModuleGraphNode
+	tag  string
+}
+
+// LoadModuleGraph reads a module graph file written by soong_build.
+func LoadModuleGraph(file string) (*ModuleGraph, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var nodes []*ModuleGraphNode
+	if err := json.NewDecoder(f).Decode(&nodes); err != nil {
+		return nil, fmt.Errorf("failed to parse module graph %s: %s", file, err)
+	}
+
+	return NewModuleGraph(nodes), nil
+}
+
+// NewModuleGraph indexes a list of module variants for queries.
+func NewModuleGraph(nodes []*ModuleGraphNode) *ModuleGraph {
+	g := &ModuleGraph{
+		nodes:  make(map[moduleGraphKey]*ModuleGraphNode),
+		byName: make(map[string][]*ModuleGraphNode),
+		rdeps:  make(map[moduleGraphKey][]moduleGraphReverseEdge),
+	}
+
+	for _, n := range nodes {
+		g.nodes[n.key()] = n
+		g.byName[n.Name] = append(g.byName[n.Name], n)
+	}
+
+	for _, n := range nodes {
+		for _, dep := range n.Deps {
+			g.rdeps[dep.key()] = append(g.rdeps[dep.key()], moduleGraphReverseEdge{n, dep.Tag})
+		}
+	}
+
+	return g
+}
+
+// QueryFilter restricts the results of a query.  Empty fields match everything.
+type QueryFilter struct {
+	// Only follow dependencies whose tag type contains Tag, e.g. "sharedDepTag" or "cc.".
+	Tag string
+
+	// Only start and end paths at module variants for this os, e.g. "android" or "linux_glibc".
+	Os string
+
+	// Only start and end paths at module variants for this arch, e.g. "arm64".
+	Arch string
+}
+
+func (f QueryFilter) matchesNode(n *ModuleGraphNode) bool {
+	return (f.Os == "" || n.Os == f.Os) && (f.Arch == "" || n.Arch == f.Arch)
+}
+
+func (f QueryFilter) matchesTag(tag string) bool {
+	return f.Tag == "" || strings.Contains(tag, f.Tag)
+}
+
+// A QueryPath is a chain of dependencies between module variants.  Tags[i] is the tag of the
+// dependency from Nodes[i] to Nodes[i+1].
+type QueryPath struct {
+	Nodes []*ModuleGraphNode
+	Tags  []string
+}
+
+func (p QueryPath) String() string {
+	var b strings.Builder
+	for i, n := range p.Nodes {
+		if i == 0 {
+			b.WriteString(n.String())
+		} else {
+			fmt.Fprintf(&b, "\n  -> (%s) %s", p.Tags[i-1], n.String())
+		}
+	}
+	return b.String()
+}
+
+// Variants returns the variants of the named module that match the filter.
+func (g *ModuleGraph) Variants(name string, filter QueryFilter) []*ModuleGraphNode {
+	var ret []*ModuleGraphNode
+	for _, n := range g.byName[name] {
+		if filter.matchesNode(n) {
+			ret = append(ret, n)
+		}
+	}
+	sortModuleGraphNodes(ret)
+	return ret
+}
+
+// ReverseDeps returns a path from every module variant that matches the filter and depends
+// directly or transitively on a matching variant of the named module, ending at that variant.  Each path is one of the shortest.  If
+// depth is greater than 0 only reverse dependencies up to that many steps away are returned.
+func (g *ModuleGraph) ReverseDeps(name string, filter QueryFilter, depth int) []QueryPath {
+	type visit struct {
+		node *ModuleGraphNode
+		next *visit
+		tag  string
+		dist int
+	}
+
+	visited := make(map[moduleGraphKey]*visit)
+	var queue []*visit
+	for _, n := range g.Variants(name, filter) {
+		v := &visit{node: n}
+		visited[n.key()] = v
+		queue = append(queue, v)
+	}
+
+	var found []*visit
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		if depth > 0 && v.dist >= depth {
+			continue
+		}
+		for _, rdep := range g.rdeps[v.node.key()] {
+			if !filter.matchesTag(rdep.tag) {
+				continue
+			}
+			if _, ok := visited[rdep.from.key()]; ok {
+				continue
+			}
+			r := &visit{node: rdep.from, next: v, tag: rdep.tag, dist: v.dist + 1}
+			visited[rdep.from.key()] = r
+			queue = append(queue, r)
+			// Paths may pass through variants that don't match the filter, e.g. a host tool
+			// that depends on a device module, but only start at ones that do.
+			if filter.matchesNode(rdep.from) {
+				found = append(found, r)
+			}
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return lessModuleGraphNode(found[i].node, found[j].node)
+	})
+
+	var paths []QueryPath
+	for _, v := range found {
+		var path QueryPath
+		for ; v != nil; v = v.next {
+			path.Nodes = append(path.Nodes, v.node)
+			if v.next != nil {
+				path.Tags = append(path.Tags, v.tag)
+			}
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// SomePath returns one of the shortest paths from a variant of the module named from to a variant
+// of the module named to, or false if there is no path.
+func (g *ModuleGraph) SomePath(from, to string, filter QueryFilter) (QueryPath, bool) {
+	type visit struct {
+		node *ModuleGraphNode
+		prev *visit
+		tag  string
+	}
+
+	visited := make(map[moduleGraphKey]bool)
+	var queue []*visit
+	for _, n := range g.Variants(from, filter) {
+		visited[n.key()] = true
+		queue = append(queue, &visit{node: n})
+	}
+
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+
+		if v.node.Name == to && filter.matchesNode(v.node) && v.prev != nil {
+			var path QueryPath
+			for ; v != nil; v = v.prev {
+				path.Nodes = append([]*ModuleGraphNode{v.node}, path.Nodes...)
+				if v.prev != nil {
+					path.Tags = append([]string{v.tag}, path.Tags...)
+				}
+			}
+			return path, true
+		}
+
+		for _, dep := range v.node.Deps {
+			if !filter.matchesTag(dep.Tag) || visited[dep.key()] {
+				continue
+			}
+			n := g.nodes[dep.key()]
+			if n == nil {
+				continue
+			}
+			visited[dep.key()] = true
+			queue = append(queue, &visit{node: n, prev: v, tag: dep.Tag})
+		}
+	}
+
+	return QueryPath{}, false
+}
+
+func sortModuleGraphNodes(nodes []*ModuleGraphNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return lessModuleGraphNode(nodes[i], nodes[j])
+	})
+}
+
+func lessModuleGraphNode(a, b *ModuleGraphNode) bool {
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Variant < b.Variant
+}
@@ -0,0 +1,182 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package build
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testModuleGraph() *ModuleGraph {
+	const device = "android_arm64_armv8-a_core_shared"
+	const host = "linux_glibc_x86_64_shared"
+
+	node := func(name, variant string, deps ...ModuleGraphEdge) *ModuleGraphNode {
+		n := &ModuleGraphNode{Name: name, Namespace: ".", Variant: variant, Deps: deps}
+		if variant == device {
+			n.Os, n.Arch = "android", "arm64"
+		} else {
+			n.Os, n.Arch = "linux_glibc", "x86_64"
+		}
+		return n
+	}
+	edge := func(name, variant, tag string) ModuleGraphEdge {
+		return ModuleGraphEdge{Name: name, Namespace: ".", Variant: variant, Tag: tag}
+	}
+
+	return NewModuleGraph([]*ModuleGraphNode{
+		node("bin", device,
+			edge("liba", device, "cc.sharedDepTag"),
+			edge("libc", device, "cc.staticDepTag")),
+		node("liba", device, edge("libc", device, "cc.sharedDepTag")),
+		node("libc", device),
+		node("bin", host, edge("libc", host, "cc.sharedDepTag")),
+		node("libc", host),
+		// A host module that depends on a device variant, e.g. to package it.
+		node("packager", host, edge("bin", device, "android.hostToDeviceTag")),
+	})
+}
+
+func queryPathStrings(paths []QueryPath) []string {
+	var ret []string
+	for _, p := range paths {
+		ret = append(ret, p.String())
+	}
+	return ret
+}
+
+func TestModuleGraphReverseDeps(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter QueryFilter
+		depth  int
+		want   []string
+	}{
+		{
+			name: "all",
+			want: []string{
+				"bin [android_arm64_armv8-a_core_shared]\n" +
+					"  -> (cc.staticDepTag) libc [android_arm64_armv8-a_core_shared]",
+				"bin [linux_glibc_x86_64_shared]\n" +
+					"  -> (cc.sharedDepTag) libc [linux_glibc_x86_64_shared]",
+				"liba [android_arm64_armv8-a_core_shared]\n" +
+					"  -> (cc.sharedDepTag) libc [android_arm64_armv8-a_core_shared]",
+				"packager [linux_glibc_x86_64_shared]\n" +
+					"  -> (android.hostToDeviceTag) bin [android_arm64_armv8-a_core_shared]\n" +
+					"  -> (cc.staticDepTag) libc [android_arm64_armv8-a_core_shared]",
+			},
+		},
+		{
+			name:   "os",
+			filter: QueryFilter{Os: "linux_glibc"},
+			want: []string{
+				"bin [linux_glibc_x86_64_shared]\n" +
+					"  -> (cc.sharedDepTag) libc [linux_glibc_x86_64_shared]",
+			},
+		},
+		{
+			name:   "os start",
+			filter: QueryFilter{Os: "android"},
+			want: []string{
+				"bin [android_arm64_armv8-a_core_shared]\n" +
+					"  -> (cc.staticDepTag) libc [android_arm64_armv8-a_core_shared]",
+				"liba [android_arm64_armv8-a_core_shared]\n" +
+					"  -> (cc.sharedDepTag) libc [android_arm64_armv8-a_core_shared]",
+			},
+		},
+		{
+			name:   "tag",
+			filter: QueryFilter{Os: "android", Tag: "sharedDepTag"},
+			want: []string{
+				"bin [android_arm64_armv8-a_core_shared]\n" +
+					"  -> (cc.sharedDepTag) liba [android_arm64_armv8-a_core_shared]\n" +
+					"  -> (cc.sharedDepTag) libc [android_arm64_armv8-a_core_shared]",
+				"liba [android_arm64_armv8-a_core_shared]\n" +
+					"  -> (cc.sharedDepTag) libc [android_arm64_armv8-a_core_shared]",
+			},
+		},
+		{
+			name:   "depth",
+			filter: QueryFilter{Arch: "arm64", Tag: "sharedDepTag"},
+			depth:  1,
+			want: []string{
+				"liba [android_arm64_armv8-a_core_shared]\n" +
+					"  -> (cc.sharedDepTag) libc [android_arm64_armv8-a_core_shared]",
+			},
+		},
+	}
+
+	g := testModuleGraph()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := queryPathStrings(g.ReverseDeps("libc", tc.filter, tc.depth))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected:\n%q\ngot:\n%q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestModuleGraphSomePath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		from, to string
+		filter   QueryFilter
+		want     string
+	}{
+		{
+			name:   "shortest",
+			from:   "bin",
+			to:     "libc",
+			filter: QueryFilter{Os: "android"},
+			want: "bin [android_arm64_armv8-a_core_shared]\n" +
+				"  -> (cc.staticDepTag) libc [android_arm64_armv8-a_core_shared]",
+		},
+		{
+			name:   "tag",
+			from:   "bin",
+			to:     "libc",
+			filter: QueryFilter{Os: "android", Tag: "sharedDepTag"},
+			want: "bin [android_arm64_armv8-a_core_shared]\n" +
+				"  -> (cc.sharedDepTag) liba [android_arm64_armv8-a_core_shared]\n" +
+				"  -> (cc.sharedDepTag) libc [android_arm64_armv8-a_core_shared]",
+		},
+		{
+			name:   "no path",
+			from:   "liba",
+			to:     "bin",
+			filter: QueryFilter{},
+		},
+	}
+
+	g := testModuleGraph()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path, ok := g.SomePath(tc.from, tc.to, tc.filter)
+			if tc.want == "" {
+				if ok {
+					t.Errorf("expected no path, got:\n%s", path)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("expected path:\n%s\ngot no path", tc.want)
+			}
+			if got := path.String(); got != tc.want {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.want, got)
+			}
+		})
+	}
+}
@@ -122,6 +122,21 @@ func runSoong(ctx Context, config Config) {
 		cmd.RunAndPrintOrFatal()
 	}
 
+	// soong_build only writes its metrics when it runs.  Remove the metrics of a previous run so
+	// that they are not reported again.
+	soongBuildMetrics := filepath.Join(config.SoongOutDir(), "soong_build_metrics.pb")
+	if err := os.Remove(soongBuildMetrics); err != nil && !os.IsNotExist(err) {
+		ctx.Fatalf("Failed to remove %s: %v", soongBuildMetrics, err)
+	}
+
 	ninja("minibootstrap", ".minibootstrap/build.ninja")
 	ninja("bootstrap", ".bootstrap/build.ninja")
+
+	if ctx.Metrics != nil {
+		if _, err := os.Stat(soongBuildMetrics); err == nil {
+			if err := ctx.Metrics.SetSoongBuildMetrics(soongBuildMetrics); err != nil {
+				ctx.Println("Failed to read soong_build metrics:", err)
+			}
+		}
+	}
 }
@@ -17,6 +17,7 @@ package metrics
 import (
 	"io/ioutil"
 	"os"
+	"sort"
 	"strconv"
 
 	"android/soong/ui/metrics/metrics_proto"
@@ -137,6 +138,39 @@ func (m *Metrics) SetBuildDateTime(date_time string) {
 	}
 }
 
+// SetSoongBuildMetrics folds the metrics written by soong_build to file into the build metrics.
+func (m *Metrics) SetSoongBuildMetrics(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	soong := &metrics_proto.MetricsBase{}
+	if err := proto.Unmarshal(data, soong); err != nil {
+		return err
+	}
+
+	proto.Merge(&m.metrics, soong)
+	return nil
+}
+
+// SetModuleTypes records the number of modules of each module type in the build system.
+func (m *Metrics) SetModuleTypes(buildSystem metrics_proto.ModuleTypeInfo_BUILDSYSTEM, counts map[string]int) {
+	var types []string
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	for _, t := range types {
+		m.metrics.ModuleTypes = append(m.metrics.ModuleTypes, &metrics_proto.ModuleTypeInfo{
+			BuildSystem:  buildSystem.Enum(),
+			ModuleType:   proto.String(t),
+			NumOfModules: proto.Uint32(uint32(counts[t])),
+		})
+	}
+}
+
 func (m *Metrics) Serialize() (data []byte, err error) {
 	return proto.Marshal(&m.metrics)
 }
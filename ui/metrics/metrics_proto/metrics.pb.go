@@ -54,7 +54,7 @@ func (x *MetricsBase_BUILDVARIANT) UnmarshalJSON(data []byte) error {
 	return nil
 }
 func (MetricsBase_BUILDVARIANT) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_metrics_9e7b895801991242, []int{0, 0}
+	return fileDescriptor_metrics_49d8b2cdb1ea8c55, []int{0, 0}
 }
 
 type MetricsBase_ARCH int32
@@ -99,7 +99,7 @@ func (x *MetricsBase_ARCH) UnmarshalJSON(data []byte) error {
 	return nil
 }
 func (MetricsBase_ARCH) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_metrics_9e7b895801991242, []int{0, 1}
+	return fileDescriptor_metrics_49d8b2cdb1ea8c55, []int{0, 1}
 }
 
 type ModuleTypeInfo_BUILDSYSTEM int32
@@ -138,7 +138,7 @@ func (x *ModuleTypeInfo_BUILDSYSTEM) UnmarshalJSON(data []byte) error {
 	return nil
 }
 func (ModuleTypeInfo_BUILDSYSTEM) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_metrics_9e7b895801991242, []int{2, 0}
+	return fileDescriptor_metrics_49d8b2cdb1ea8c55, []int{4, 0}
 }
 
 type MetricsBase struct {
@@ -181,17 +181,25 @@ type MetricsBase struct {
 	// The metrics for calling Soong.
 	SoongRuns []*PerfInfo `protobuf:"bytes,19,rep,name=soong_runs,json=soongRuns" json:"soong_runs,omitempty"`
 	// The metrics for calling Ninja.
-	NinjaRuns            []*PerfInfo `protobuf:"bytes,20,rep,name=ninja_runs,json=ninjaRuns" json:"ninja_runs,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
-	XXX_unrecognized     []byte      `json:"-"`
-	XXX_sizecache        int32       `json:"-"`
+	NinjaRuns []*PerfInfo `protobuf:"bytes,20,rep,name=ninja_runs,json=ninjaRuns" json:"ninja_runs,omitempty"`
+	// The actions that used the most CPU time, most expensive first.
+	ExpensiveActions []*ActionInfo `protobuf:"bytes,21,rep,name=expensive_actions,json=expensiveActions" json:"expensive_actions,omitempty"`
+	// The chain of dependent actions that determined the wall time of the build.
+	CriticalPathInfo *CriticalPathInfo `protobuf:"bytes,22,opt,name=critical_path_info,json=criticalPathInfo" json:"critical_path_info,omitempty"`
+	// The number of modules of each module type, in Soong and in Make.
+	ModuleTypes []*ModuleTypeInfo `protobuf:"bytes,23,rep,name=module_types,json=moduleTypes" json:"module_types,omitempty"`
+	// The metrics written by soong_build, if it ran.
+	SoongBuildMetrics    *SoongBuildMetrics `protobuf:"bytes,24,opt,name=soong_build_metrics,json=soongBuildMetrics" json:"soong_build_metrics,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
 }
 
 func (m *MetricsBase) Reset()         { *m = MetricsBase{} }
 func (m *MetricsBase) String() string { return proto.CompactTextString(m) }
 func (*MetricsBase) ProtoMessage()    {}
 func (*MetricsBase) Descriptor() ([]byte, []int) {
-	return fileDescriptor_metrics_9e7b895801991242, []int{0}
+	return fileDescriptor_metrics_49d8b2cdb1ea8c55, []int{0}
 }
 func (m *MetricsBase) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_MetricsBase.Unmarshal(m, b)
@@ -356,6 +364,34 @@ func (m *MetricsBase) GetNinjaRuns() []*PerfInfo {
 	return nil
 }
 
+func (m *MetricsBase) GetExpensiveActions() []*ActionInfo {
+	if m != nil {
+		return m.ExpensiveActions
+	}
+	return nil
+}
+
+func (m *MetricsBase) GetCriticalPathInfo() *CriticalPathInfo {
+	if m != nil {
+		return m.CriticalPathInfo
+	}
+	return nil
+}
+
+func (m *MetricsBase) GetModuleTypes() []*ModuleTypeInfo {
+	if m != nil {
+		return m.ModuleTypes
+	}
+	return nil
+}
+
+func (m *MetricsBase) GetSoongBuildMetrics() *SoongBuildMetrics {
+	if m != nil {
+		return m.SoongBuildMetrics
+	}
+	return nil
+}
+
 type PerfInfo struct {
 	// The description for the phase/action/part while the tool running.
 	Desc *string `protobuf:"bytes,1,opt,name=desc" json:"desc,omitempty"`
@@ -378,7 +414,7 @@ func (m *PerfInfo) Reset()         { *m = PerfInfo{} }
 func (m *PerfInfo) String() string { return proto.CompactTextString(m) }
 func (*PerfInfo) ProtoMessage()    {}
 func (*PerfInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_metrics_9e7b895801991242, []int{1}
+	return fileDescriptor_metrics_49d8b2cdb1ea8c55, []int{1}
 }
 func (m *PerfInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PerfInfo.Unmarshal(m, b)
@@ -433,6 +469,160 @@ func (m *PerfInfo) GetMemoryUse() uint64 {
 	return 0
 }
 
+type ActionInfo struct {
+	// The description of the action, or its command if it has none.
+	Desc *string `protobuf:"bytes,1,opt,name=desc" json:"desc,omitempty"`
+	// The first output of the action.
+	Output *string `protobuf:"bytes,2,opt,name=output" json:"output,omitempty"`
+	// The real running time.
+	// The number of nanoseconds elapsed while the action was running.
+	RealTime *uint64 `protobuf:"varint,3,opt,name=real_time,json=realTime" json:"real_time,omitempty"`
+	// The number of milliseconds spent executing in user mode.
+	UserTime *uint32 `protobuf:"varint,4,opt,name=user_time,json=userTime" json:"user_time,omitempty"`
+	// The number of milliseconds spent executing in kernel mode.
+	SystemTime *uint32 `protobuf:"varint,5,opt,name=system_time,json=systemTime" json:"system_time,omitempty"`
+	// The max resident set size in kB.
+	MaxRssKb *uint64 `protobuf:"varint,6,opt,name=max_rss_kb,json=maxRssKb" json:"max_rss_kb,omitempty"`
+	// The absolute start time.
+	// The number of nanoseconds elapsed since January 1, 1970 UTC.
+	StartTime            *uint64  `protobuf:"varint,7,opt,name=start_time,json=startTime" json:"start_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ActionInfo) Reset()         { *m = ActionInfo{} }
+func (m *ActionInfo) String() string { return proto.CompactTextString(m) }
+func (*ActionInfo) ProtoMessage()    {}
+func (*ActionInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_metrics_49d8b2cdb1ea8c55, []int{2}
+}
+func (m *ActionInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ActionInfo.Unmarshal(m, b)
+}
+func (m *ActionInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ActionInfo.Marshal(b, m, deterministic)
+}
+func (dst *ActionInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActionInfo.Merge(dst, src)
+}
+func (m *ActionInfo) XXX_Size() int {
+	return xxx_messageInfo_ActionInfo.Size(m)
+}
+func (m *ActionInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActionInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActionInfo proto.InternalMessageInfo
+
+func (m *ActionInfo) GetDesc() string {
+	if m != nil && m.Desc != nil {
+		return *m.Desc
+	}
+	return ""
+}
+
+func (m *ActionInfo) GetOutput() string {
+	if m != nil && m.Output != nil {
+		return *m.Output
+	}
+	return ""
+}
+
+func (m *ActionInfo) GetRealTime() uint64 {
+	if m != nil && m.RealTime != nil {
+		return *m.RealTime
+	}
+	return 0
+}
+
+func (m *ActionInfo) GetUserTime() uint32 {
+	if m != nil && m.UserTime != nil {
+		return *m.UserTime
+	}
+	return 0
+}
+
+func (m *ActionInfo) GetSystemTime() uint32 {
+	if m != nil && m.SystemTime != nil {
+		return *m.SystemTime
+	}
+	return 0
+}
+
+func (m *ActionInfo) GetMaxRssKb() uint64 {
+	if m != nil && m.MaxRssKb != nil {
+		return *m.MaxRssKb
+	}
+	return 0
+}
+
+func (m *ActionInfo) GetStartTime() uint64 {
+	if m != nil && m.StartTime != nil {
+		return *m.StartTime
+	}
+	return 0
+}
+
+type CriticalPathInfo struct {
+	// The total running time of the actions on the critical path.
+	// The number of nanoseconds.
+	CriticalPathTime *uint64 `protobuf:"varint,1,opt,name=critical_path_time,json=criticalPathTime" json:"critical_path_time,omitempty"`
+	// The time from the start of the first action to the end of the last action.
+	// The number of nanoseconds.
+	ElapsedTime *uint64 `protobuf:"varint,2,opt,name=elapsed_time,json=elapsedTime" json:"elapsed_time,omitempty"`
+	// The actions on the critical path, in the order they ran.
+	CriticalPath         []*ActionInfo `protobuf:"bytes,3,rep,name=critical_path,json=criticalPath" json:"critical_path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *CriticalPathInfo) Reset()         { *m = CriticalPathInfo{} }
+func (m *CriticalPathInfo) String() string { return proto.CompactTextString(m) }
+func (*CriticalPathInfo) ProtoMessage()    {}
+func (*CriticalPathInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_metrics_49d8b2cdb1ea8c55, []int{3}
+}
+func (m *CriticalPathInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CriticalPathInfo.Unmarshal(m, b)
+}
+func (m *CriticalPathInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CriticalPathInfo.Marshal(b, m, deterministic)
+}
+func (dst *CriticalPathInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CriticalPathInfo.Merge(dst, src)
+}
+func (m *CriticalPathInfo) XXX_Size() int {
+	return xxx_messageInfo_CriticalPathInfo.Size(m)
+}
+func (m *CriticalPathInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_CriticalPathInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CriticalPathInfo proto.InternalMessageInfo
+
+func (m *CriticalPathInfo) GetCriticalPathTime() uint64 {
+	if m != nil && m.CriticalPathTime != nil {
+		return *m.CriticalPathTime
+	}
+	return 0
+}
+
+func (m *CriticalPathInfo) GetElapsedTime() uint64 {
+	if m != nil && m.ElapsedTime != nil {
+		return *m.ElapsedTime
+	}
+	return 0
+}
+
+func (m *CriticalPathInfo) GetCriticalPath() []*ActionInfo {
+	if m != nil {
+		return m.CriticalPath
+	}
+	return nil
+}
+
 type ModuleTypeInfo struct {
 	// The build system, eg. Soong or Make.
 	BuildSystem *ModuleTypeInfo_BUILDSYSTEM `protobuf:"varint,1,opt,name=build_system,json=buildSystem,enum=build_metrics.ModuleTypeInfo_BUILDSYSTEM,def=0" json:"build_system,omitempty"`
@@ -449,7 +639,7 @@ func (m *ModuleTypeInfo) Reset()         { *m = ModuleTypeInfo{} }
 func (m *ModuleTypeInfo) String() string { return proto.CompactTextString(m) }
 func (*ModuleTypeInfo) ProtoMessage()    {}
 func (*ModuleTypeInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_metrics_9e7b895801991242, []int{2}
+	return fileDescriptor_metrics_49d8b2cdb1ea8c55, []int{4}
 }
 func (m *ModuleTypeInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ModuleTypeInfo.Unmarshal(m, b)
@@ -492,66 +682,153 @@ func (m *ModuleTypeInfo) GetNumOfModules() uint32 {
 	return 0
 }
 
+type SoongBuildMetrics struct {
+	// The number of logical modules.
+	Modules *uint32 `protobuf:"varint,1,opt,name=modules" json:"modules,omitempty"`
+	// The number of module variants, eg. one per architecture.
+	Variants *uint32 `protobuf:"varint,2,opt,name=variants" json:"variants,omitempty"`
+	// The max resident set size of soong_build in kB.
+	MaxRssKb *uint64 `protobuf:"varint,3,opt,name=max_rss_kb,json=maxRssKb" json:"max_rss_kb,omitempty"`
+	// The running time of each mutator, in the order they ran.
+	Mutators             []*PerfInfo `protobuf:"bytes,4,rep,name=mutators" json:"mutators,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *SoongBuildMetrics) Reset()         { *m = SoongBuildMetrics{} }
+func (m *SoongBuildMetrics) String() string { return proto.CompactTextString(m) }
+func (*SoongBuildMetrics) ProtoMessage()    {}
+func (*SoongBuildMetrics) Descriptor() ([]byte, []int) {
+	return fileDescriptor_metrics_49d8b2cdb1ea8c55, []int{5}
+}
+func (m *SoongBuildMetrics) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SoongBuildMetrics.Unmarshal(m, b)
+}
+func (m *SoongBuildMetrics) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SoongBuildMetrics.Marshal(b, m, deterministic)
+}
+func (dst *SoongBuildMetrics) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SoongBuildMetrics.Merge(dst, src)
+}
+func (m *SoongBuildMetrics) XXX_Size() int {
+	return xxx_messageInfo_SoongBuildMetrics.Size(m)
+}
+func (m *SoongBuildMetrics) XXX_DiscardUnknown() {
+	xxx_messageInfo_SoongBuildMetrics.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SoongBuildMetrics proto.InternalMessageInfo
+
+func (m *SoongBuildMetrics) GetModules() uint32 {
+	if m != nil && m.Modules != nil {
+		return *m.Modules
+	}
+	return 0
+}
+
+func (m *SoongBuildMetrics) GetVariants() uint32 {
+	if m != nil && m.Variants != nil {
+		return *m.Variants
+	}
+	return 0
+}
+
+func (m *SoongBuildMetrics) GetMaxRssKb() uint64 {
+	if m != nil && m.MaxRssKb != nil {
+		return *m.MaxRssKb
+	}
+	return 0
+}
+
+func (m *SoongBuildMetrics) GetMutators() []*PerfInfo {
+	if m != nil {
+		return m.Mutators
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*MetricsBase)(nil), "build_metrics.MetricsBase")
 	proto.RegisterType((*PerfInfo)(nil), "build_metrics.PerfInfo")
+	proto.RegisterType((*ActionInfo)(nil), "build_metrics.ActionInfo")
+	proto.RegisterType((*CriticalPathInfo)(nil), "build_metrics.CriticalPathInfo")
 	proto.RegisterType((*ModuleTypeInfo)(nil), "build_metrics.ModuleTypeInfo")
+	proto.RegisterType((*SoongBuildMetrics)(nil), "build_metrics.SoongBuildMetrics")
 	proto.RegisterEnum("build_metrics.MetricsBase_BUILDVARIANT", MetricsBase_BUILDVARIANT_name, MetricsBase_BUILDVARIANT_value)
 	proto.RegisterEnum("build_metrics.MetricsBase_ARCH", MetricsBase_ARCH_name, MetricsBase_ARCH_value)
 	proto.RegisterEnum("build_metrics.ModuleTypeInfo_BUILDSYSTEM", ModuleTypeInfo_BUILDSYSTEM_name, ModuleTypeInfo_BUILDSYSTEM_value)
 }
 
-func init() { proto.RegisterFile("metrics.proto", fileDescriptor_metrics_9e7b895801991242) }
-
-var fileDescriptor_metrics_9e7b895801991242 = []byte{
-	// 783 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xdd, 0x6e, 0xdb, 0x36,
-	0x14, 0xae, 0x62, 0x25, 0x96, 0x8e, 0x62, 0x57, 0x61, 0x02, 0x44, 0xc5, 0x50, 0x34, 0x30, 0xf6,
-	0x93, 0x01, 0x9b, 0x57, 0x18, 0x81, 0x11, 0x04, 0xbb, 0xb1, 0x13, 0xa3, 0x35, 0x5a, 0xdb, 0x85,
-	0x6c, 0x67, 0xdd, 0x2e, 0x46, 0x68, 0x12, 0xdd, 0x68, 0xb3, 0x44, 0x81, 0xa4, 0x8a, 0xf9, 0x21,
-	0xf6, 0x8c, 0x7b, 0x91, 0x5d, 0x0c, 0x3c, 0xb4, 0x5c, 0xa5, 0x17, 0x29, 0x72, 0x47, 0x9d, 0xef,
-	0x87, 0xdf, 0x91, 0xc8, 0x23, 0x68, 0x65, 0x4c, 0x89, 0x34, 0x96, 0xdd, 0x42, 0x70, 0xc5, 0x49,
-	0xeb, 0x8f, 0x32, 0x5d, 0x27, 0x74, 0x5b, 0xec, 0xfc, 0xe7, 0x80, 0x37, 0x31, 0xeb, 0x61, 0x24,
-	0x19, 0x79, 0x09, 0x27, 0x86, 0x90, 0x44, 0x8a, 0x51, 0x95, 0x66, 0x4c, 0xaa, 0x28, 0x2b, 0x02,
-	0xeb, 0xcc, 0x3a, 0x6f, 0x84, 0x04, 0xb1, 0x9b, 0x48, 0xb1, 0x45, 0x85, 0x90, 0x67, 0xe0, 0x18,
-	0x45, 0x9a, 0x04, 0x7b, 0x67, 0xd6, 0xb9, 0x1b, 0x36, 0xf1, 0x79, 0x9c, 0x90, 0x2b, 0x78, 0x56,
-	0xac, 0x23, 0xb5, 0xe2, 0x22, 0xa3, 0x1f, 0x99, 0x90, 0x29, 0xcf, 0x69, 0xcc, 0x13, 0x96, 0x47,
-	0x19, 0x0b, 0x1a, 0xc8, 0x3d, 0xad, 0x08, 0xb7, 0x06, 0xbf, 0xde, 0xc2, 0xe4, 0x1b, 0x68, 0xab,
-	0x48, 0x7c, 0x60, 0x8a, 0x16, 0x82, 0x27, 0x65, 0xac, 0x02, 0x1b, 0x05, 0x2d, 0x53, 0x7d, 0x67,
-	0x8a, 0xe4, 0x77, 0x38, 0xd9, 0xd2, 0x4c, 0x88, 0x8f, 0x91, 0x48, 0xa3, 0x5c, 0x05, 0xfb, 0x67,
-	0xd6, 0x79, 0xbb, 0xf7, 0x5d, 0xf7, 0x5e, 0xb7, 0xdd, 0x5a, 0xa7, 0xdd, 0xe1, 0x72, 0xfc, 0xf6,
-	0xe6, 0x76, 0x10, 0x8e, 0x07, 0xd3, 0xc5, 0x55, 0x63, 0x34, 0x7d, 0x15, 0x12, 0xe3, 0x34, 0xd4,
-	0x92, 0x5b, 0xe3, 0x43, 0xc6, 0xe0, 0x6d, 0xfd, 0x23, 0x11, 0xdf, 0x05, 0x07, 0x68, 0xfb, 0xe2,
-	0x01, 0xdb, 0x41, 0x78, 0xfd, 0xfa, 0xaa, 0xb9, 0x9c, 0xbe, 0x99, 0xce, 0x7e, 0x99, 0x86, 0x60,
-	0xc4, 0x03, 0x11, 0xdf, 0x91, 0x2e, 0x1c, 0xd7, 0xac, 0x76, 0x49, 0x9b, 0xd8, 0xd6, 0xd1, 0x27,
-	0x62, 0xb5, 0xf5, 0x0f, 0xb0, 0x0d, 0x44, 0xe3, 0xa2, 0xdc, 0xd1, 0x1d, 0xa4, 0xfb, 0x06, 0xb9,
-	0x2e, 0xca, 0x8a, 0x3d, 0x02, 0xf7, 0x8e, 0xcb, 0x6d, 0x4c, 0xf7, 0x91, 0x31, 0x1d, 0x2d, 0xc5,
-	0x90, 0x6f, 0xa1, 0x85, 0x36, 0xbd, 0x3c, 0x31, 0x56, 0xf0, 0x48, 0x2b, 0x4f, 0xcb, 0x7b, 0x79,
-	0x82, 0x6e, 0xa7, 0xd0, 0x44, 0x37, 0x2e, 0x03, 0x0f, 0x73, 0x1f, 0xe8, 0xc7, 0x99, 0x24, 0x9d,
-	0xed, 0x36, 0x5c, 0x52, 0xf6, 0xb7, 0x12, 0x51, 0x70, 0x88, 0xb0, 0x67, 0xe0, 0x91, 0x2e, 0xed,
-	0x38, 0xb1, 0xe0, 0x52, 0x6a, 0x8b, 0xd6, 0x27, 0xce, 0xb5, 0xae, 0xcd, 0x24, 0xf9, 0x16, 0x9e,
-	0xd6, 0x38, 0x18, 0xb8, 0x6d, 0x8e, 0xc9, 0x8e, 0x85, 0x41, 0x7e, 0x84, 0xe3, 0x1a, 0x6f, 0xd7,
-	0xdc, 0x53, 0xf3, 0x32, 0x77, 0xdc, 0x5a, 0x6e, 0x5e, 0x2a, 0x9a, 0xa4, 0x22, 0xf0, 0x4d, 0x6e,
-	0x5e, 0xaa, 0x9b, 0x54, 0x90, 0x4b, 0xf0, 0x24, 0x53, 0x65, 0x41, 0x15, 0xe7, 0x6b, 0x19, 0x1c,
-	0x9d, 0x35, 0xce, 0xbd, 0xde, 0xe9, 0x67, 0x2f, 0xe7, 0x1d, 0x13, 0xab, 0x71, 0xbe, 0xe2, 0x21,
-	0x20, 0x77, 0xa1, 0xa9, 0xe4, 0x02, 0xdc, 0xbf, 0x22, 0x95, 0x52, 0x51, 0xe6, 0x32, 0x20, 0x0f,
-	0xeb, 0x1c, 0xcd, 0x0c, 0xcb, 0x5c, 0x92, 0x3e, 0x80, 0xe4, 0x3c, 0xff, 0x60, 0x64, 0xc7, 0x0f,
-	0xcb, 0x5c, 0xa4, 0x56, 0xba, 0x3c, 0xcd, 0xff, 0x8c, 0x8c, 0xee, 0xe4, 0x0b, 0x3a, 0xa4, 0x6a,
-	0x5d, 0xe7, 0x25, 0x1c, 0xd6, 0xef, 0x05, 0x71, 0xc0, 0x5e, 0xce, 0x47, 0xa1, 0xff, 0x84, 0xb4,
-	0xc0, 0xd5, 0xab, 0x9b, 0xd1, 0x70, 0xf9, 0xca, 0xb7, 0x48, 0x13, 0xf4, 0x95, 0xf1, 0xf7, 0x3a,
-	0x3f, 0x83, 0xad, 0x0f, 0x00, 0xf1, 0xa0, 0x3a, 0x02, 0xfe, 0x13, 0x8d, 0x0e, 0xc2, 0x89, 0x6f,
-	0x11, 0x17, 0xf6, 0x07, 0xe1, 0xa4, 0x7f, 0xe1, 0xef, 0xe9, 0xda, 0xfb, 0xcb, 0xbe, 0xdf, 0x20,
-	0x00, 0x07, 0xef, 0x2f, 0xfb, 0xb4, 0x7f, 0xe1, 0xdb, 0x9d, 0x7f, 0x2c, 0x70, 0xaa, 0x1c, 0x84,
-	0x80, 0x9d, 0x30, 0x19, 0xe3, 0xac, 0x71, 0x43, 0x5c, 0xeb, 0x1a, 0x4e, 0x0b, 0x33, 0x59, 0x70,
-	0x4d, 0x9e, 0x03, 0x48, 0x15, 0x09, 0x85, 0xe3, 0x09, 0xe7, 0x88, 0x1d, 0xba, 0x58, 0xd1, 0x53,
-	0x89, 0x7c, 0x05, 0xae, 0x60, 0xd1, 0xda, 0xa0, 0x36, 0xa2, 0x8e, 0x2e, 0x20, 0xf8, 0x1c, 0x20,
-	0x63, 0x19, 0x17, 0x1b, 0x5a, 0x4a, 0x86, 0x53, 0xc2, 0x0e, 0x5d, 0x53, 0x59, 0x4a, 0xd6, 0xf9,
-	0xd7, 0x82, 0xf6, 0x84, 0x27, 0xe5, 0x9a, 0x2d, 0x36, 0x05, 0xc3, 0x54, 0x4b, 0x38, 0x34, 0xef,
-	0x4d, 0x6e, 0xa4, 0x62, 0x19, 0xa6, 0x6b, 0xf7, 0xbe, 0xff, 0xfc, 0x42, 0xdc, 0x13, 0x99, 0xe1,
-	0x32, 0xff, 0x75, 0xbe, 0x18, 0x4d, 0x6a, 0x57, 0x03, 0x25, 0x73, 0xb4, 0x21, 0x2f, 0xc0, 0xcb,
-	0x50, 0x43, 0xd5, 0xa6, 0xa8, 0xfa, 0x83, 0x6c, 0x67, 0x43, 0xbe, 0x86, 0x76, 0x5e, 0x66, 0x94,
-	0xaf, 0xa8, 0x29, 0x4a, 0xec, 0xb4, 0x15, 0x1e, 0xe6, 0x65, 0x36, 0x5b, 0x99, 0xfd, 0x64, 0xe7,
-	0x27, 0xf0, 0x6a, 0x7b, 0xdd, 0xff, 0x0a, 0x2e, 0xec, 0xcf, 0x67, 0xb3, 0xa9, 0xfe, 0x5c, 0x0e,
-	0xd8, 0x93, 0xc1, 0x9b, 0x91, 0xbf, 0x37, 0x3c, 0x7a, 0xdd, 0xf8, 0xad, 0xfa, 0x25, 0x50, 0xfc,
-	0x25, 0xfc, 0x1f, 0x00, 0x00, 0xff, 0xff, 0xd4, 0x8d, 0x19, 0x89, 0x22, 0x06, 0x00, 0x00,
+func init() { proto.RegisterFile("metrics.proto", fileDescriptor_metrics_49d8b2cdb1ea8c55) }
+
+var fileDescriptor_metrics_49d8b2cdb1ea8c55 = []byte{
+	// 1067 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x56, 0x5d, 0x6e, 0xdb, 0x46,
+	0x10, 0x0e, 0x2d, 0x39, 0x22, 0x87, 0x92, 0x42, 0xad, 0xd3, 0x98, 0x69, 0x6a, 0x44, 0x15, 0xfa,
+	0xe3, 0x02, 0xad, 0x1b, 0xb8, 0x86, 0x61, 0x18, 0x45, 0x51, 0xd9, 0x56, 0x13, 0xc3, 0x91, 0x64,
+	0x50, 0x96, 0x9b, 0xf6, 0xa1, 0xc4, 0x9a, 0x5c, 0xc5, 0x6c, 0x44, 0x2e, 0xc1, 0x5d, 0x1a, 0xf6,
+	0x21, 0x7a, 0x83, 0xbe, 0xf5, 0xa1, 0xd7, 0xe9, 0x0d, 0x7a, 0x95, 0x62, 0x67, 0x29, 0x59, 0x62,
+	0x0a, 0x1b, 0x79, 0xe3, 0xce, 0x7c, 0xdf, 0x37, 0x1f, 0x97, 0x33, 0x23, 0x41, 0x23, 0x66, 0x32,
+	0x8b, 0x02, 0xb1, 0x95, 0x66, 0x5c, 0x72, 0xd2, 0xb8, 0xc8, 0xa3, 0x69, 0xe8, 0x17, 0xc1, 0xce,
+	0xdf, 0x36, 0xd8, 0x7d, 0xfd, 0x7c, 0x40, 0x05, 0x23, 0x2f, 0xe0, 0xb1, 0x06, 0x84, 0x54, 0x32,
+	0x5f, 0x46, 0x31, 0x13, 0x92, 0xc6, 0xa9, 0x6b, 0xb4, 0x8d, 0xcd, 0x8a, 0x47, 0x30, 0x77, 0x44,
+	0x25, 0x3b, 0x9b, 0x65, 0xc8, 0x53, 0x30, 0x35, 0x23, 0x0a, 0xdd, 0x95, 0xb6, 0xb1, 0x69, 0x79,
+	0x35, 0x3c, 0x1f, 0x87, 0x64, 0x1f, 0x9e, 0xa6, 0x53, 0x2a, 0x27, 0x3c, 0x8b, 0xfd, 0x2b, 0x96,
+	0x89, 0x88, 0x27, 0x7e, 0xc0, 0x43, 0x96, 0xd0, 0x98, 0xb9, 0x15, 0xc4, 0xae, 0xcf, 0x00, 0xe7,
+	0x3a, 0x7f, 0x58, 0xa4, 0xc9, 0xe7, 0xd0, 0x94, 0x34, 0x7b, 0xcb, 0xa4, 0x9f, 0x66, 0x3c, 0xcc,
+	0x03, 0xe9, 0x56, 0x91, 0xd0, 0xd0, 0xd1, 0x53, 0x1d, 0x24, 0xbf, 0xc1, 0xe3, 0x02, 0xa6, 0x4d,
+	0x5c, 0xd1, 0x2c, 0xa2, 0x89, 0x74, 0x57, 0xdb, 0xc6, 0x66, 0x73, 0xfb, 0xcb, 0xad, 0xa5, 0xb7,
+	0xdd, 0x5a, 0x78, 0xd3, 0xad, 0x83, 0xf1, 0xf1, 0xeb, 0xa3, 0xf3, 0xae, 0x77, 0xdc, 0x1d, 0x9c,
+	0xed, 0x57, 0x7a, 0x83, 0x97, 0x1e, 0xd1, 0x4a, 0x07, 0x8a, 0x72, 0xae, 0x75, 0xc8, 0x31, 0xd8,
+	0x85, 0x3e, 0xcd, 0x82, 0x4b, 0xf7, 0x21, 0xca, 0x3e, 0xbf, 0x43, 0xb6, 0xeb, 0x1d, 0xbe, 0xda,
+	0xaf, 0x8d, 0x07, 0x27, 0x83, 0xe1, 0xcf, 0x03, 0x0f, 0x34, 0xb9, 0x9b, 0x05, 0x97, 0x64, 0x0b,
+	0xd6, 0x16, 0xa4, 0xe6, 0x4e, 0x6b, 0xf8, 0x5a, 0xad, 0x5b, 0xe0, 0xac, 0xf4, 0xd7, 0x50, 0x18,
+	0xf2, 0x83, 0x34, 0x9f, 0xc3, 0x4d, 0x84, 0x3b, 0x3a, 0x73, 0x98, 0xe6, 0x33, 0x74, 0x0f, 0xac,
+	0x4b, 0x2e, 0x0a, 0x9b, 0xd6, 0x07, 0xda, 0x34, 0x15, 0x15, 0x4d, 0xbe, 0x86, 0x06, 0xca, 0x6c,
+	0x27, 0xa1, 0x96, 0x82, 0x0f, 0x94, 0xb2, 0x15, 0x7d, 0x3b, 0x09, 0x51, 0x6d, 0x1d, 0x6a, 0xa8,
+	0xc6, 0x85, 0x6b, 0xa3, 0xef, 0x87, 0xea, 0x38, 0x14, 0xa4, 0x53, 0x94, 0xe1, 0xc2, 0x67, 0xd7,
+	0x32, 0xa3, 0x6e, 0x1d, 0xd3, 0xb6, 0x4e, 0xf7, 0x54, 0x68, 0x8e, 0x09, 0x32, 0x2e, 0x84, 0x92,
+	0x68, 0xdc, 0x62, 0x0e, 0x55, 0x6c, 0x28, 0xc8, 0x17, 0xf0, 0x68, 0x01, 0x83, 0x86, 0x9b, 0xba,
+	0x4d, 0xe6, 0x28, 0x34, 0xf2, 0x0d, 0xac, 0x2d, 0xe0, 0xe6, 0x2f, 0xf7, 0x48, 0x5f, 0xe6, 0x1c,
+	0xbb, 0xe0, 0x9b, 0xe7, 0xd2, 0x0f, 0xa3, 0xcc, 0x75, 0xb4, 0x6f, 0x9e, 0xcb, 0xa3, 0x28, 0x23,
+	0x7b, 0x60, 0x0b, 0x26, 0xf3, 0xd4, 0x97, 0x9c, 0x4f, 0x85, 0xdb, 0x6a, 0x57, 0x36, 0xed, 0xed,
+	0xf5, 0xd2, 0xe5, 0x9c, 0xb2, 0x6c, 0x72, 0x9c, 0x4c, 0xb8, 0x07, 0x88, 0x3d, 0x53, 0x50, 0xb2,
+	0x03, 0xd6, 0x3b, 0x2a, 0x23, 0x3f, 0xcb, 0x13, 0xe1, 0x92, 0xbb, 0x79, 0xa6, 0x42, 0x7a, 0x79,
+	0x22, 0xc8, 0x2e, 0x80, 0xe0, 0x3c, 0x79, 0xab, 0x69, 0x6b, 0x77, 0xd3, 0x2c, 0x84, 0xce, 0x78,
+	0x49, 0x94, 0xfc, 0x4e, 0x35, 0xef, 0xf1, 0x3d, 0x3c, 0x84, 0x22, 0xef, 0x27, 0x68, 0xb1, 0xeb,
+	0x94, 0x25, 0x22, 0xba, 0x62, 0x3e, 0x0d, 0x64, 0xc4, 0x13, 0xe1, 0x7e, 0x84, 0xf4, 0xa7, 0x25,
+	0x7a, 0x17, 0xb3, 0x28, 0xe0, 0xcc, 0x39, 0x3a, 0x28, 0x48, 0x1f, 0x48, 0x90, 0x45, 0x32, 0x0a,
+	0xe8, 0xd4, 0x4f, 0xa9, 0xbc, 0xf4, 0xa3, 0x64, 0xc2, 0xdd, 0x27, 0x6d, 0x63, 0xd3, 0x7e, 0xaf,
+	0x97, 0x0e, 0x0b, 0xe0, 0x29, 0x95, 0x97, 0x5a, 0x2e, 0x28, 0x45, 0xc8, 0x8f, 0x50, 0x8f, 0x79,
+	0x98, 0x4f, 0x99, 0x2f, 0x6f, 0x52, 0x26, 0xdc, 0x75, 0x74, 0xb4, 0x51, 0x6e, 0x4a, 0x84, 0x9c,
+	0xdd, 0xa4, 0x0c, 0x65, 0xec, 0x78, 0x7e, 0x16, 0xe4, 0x14, 0xd6, 0xf4, 0x45, 0x2e, 0x51, 0x5c,
+	0x17, 0x1d, 0xb5, 0x4b, 0x42, 0x23, 0x85, 0xc4, 0x35, 0x50, 0xf4, 0xb9, 0xd7, 0x12, 0xe5, 0x50,
+	0xe7, 0x05, 0xd4, 0x17, 0x57, 0x08, 0x31, 0xa1, 0x3a, 0x1e, 0xf5, 0x3c, 0xe7, 0x01, 0x69, 0x80,
+	0xa5, 0x9e, 0x8e, 0x7a, 0x07, 0xe3, 0x97, 0x8e, 0x41, 0x6a, 0xa0, 0xb6, 0x8b, 0xb3, 0xd2, 0xf9,
+	0x1e, 0xaa, 0x6a, 0x56, 0x88, 0x0d, 0xb3, 0x69, 0x71, 0x1e, 0xa8, 0x6c, 0xd7, 0xeb, 0x3b, 0x06,
+	0xb1, 0x60, 0xb5, 0xeb, 0xf5, 0x77, 0x77, 0x9c, 0x15, 0x15, 0x7b, 0xb3, 0xb7, 0xeb, 0x54, 0x08,
+	0xc0, 0xc3, 0x37, 0x7b, 0xbb, 0xfe, 0xee, 0x8e, 0x53, 0xed, 0xfc, 0x61, 0x80, 0x39, 0xfb, 0x64,
+	0x84, 0x40, 0x35, 0x64, 0x22, 0xc0, 0xb5, 0x6c, 0x79, 0xf8, 0xac, 0x62, 0xb8, 0x58, 0xf5, 0x12,
+	0xc6, 0x67, 0xb2, 0x01, 0x20, 0x24, 0xcd, 0x24, 0x6e, 0x72, 0x5c, 0xb9, 0x55, 0xcf, 0xc2, 0x88,
+	0x5a, 0xe0, 0xe4, 0x19, 0x58, 0x19, 0xa3, 0x53, 0x9d, 0xad, 0x62, 0xd6, 0x54, 0x01, 0x4c, 0x6e,
+	0x00, 0xc4, 0x2c, 0xe6, 0xd9, 0x8d, 0x9f, 0x0b, 0x86, 0x0b, 0xb5, 0xea, 0x59, 0x3a, 0x32, 0x16,
+	0xac, 0xf3, 0x8f, 0x01, 0x70, 0xdb, 0x03, 0xff, 0xeb, 0xe8, 0x09, 0xa8, 0xb9, 0x49, 0x73, 0x59,
+	0x78, 0x2a, 0x4e, 0xcb, 0x65, 0x2b, 0xa5, 0xb2, 0xcf, 0xc0, 0xca, 0x05, 0xcb, 0x6e, 0x3d, 0x35,
+	0x3c, 0x53, 0x05, 0x30, 0xf9, 0x1c, 0x6c, 0x71, 0x23, 0x24, 0x8b, 0x75, 0x7a, 0x15, 0xd3, 0xa0,
+	0x43, 0x08, 0xf8, 0x04, 0x20, 0xa6, 0xd7, 0x7e, 0x26, 0x84, 0xff, 0xee, 0x02, 0xd7, 0x75, 0xd5,
+	0x33, 0x63, 0x7a, 0xed, 0x09, 0x71, 0x72, 0x51, 0xba, 0x8e, 0x5a, 0xe9, 0x3a, 0x3a, 0x7f, 0x19,
+	0xe0, 0x94, 0xbb, 0x51, 0xad, 0xe1, 0xe5, 0x56, 0x46, 0xae, 0x81, 0xdc, 0xa5, 0x4e, 0xc5, 0xfa,
+	0x9f, 0x42, 0x9d, 0x4d, 0x69, 0x2a, 0x58, 0xa8, 0x71, 0x2b, 0x88, 0xb3, 0x8b, 0x18, 0x42, 0x7e,
+	0x80, 0xc6, 0x92, 0xa0, 0x5b, 0xb9, 0x6f, 0xbe, 0xea, 0x8b, 0x65, 0x3a, 0xff, 0x1a, 0xd0, 0x5c,
+	0x6e, 0x75, 0x32, 0x86, 0xba, 0x26, 0xeb, 0x9b, 0x40, 0x77, 0xcd, 0xed, 0xaf, 0xee, 0x9c, 0x0f,
+	0xfd, 0x03, 0x38, 0xfa, 0x65, 0x74, 0xd6, 0xeb, 0x2f, 0xac, 0x6f, 0xa4, 0x8c, 0x50, 0x46, 0xdd,
+	0xf6, 0xc2, 0xd8, 0x15, 0x1f, 0x11, 0x6e, 0xc7, 0x8a, 0x7c, 0x06, 0xcd, 0x24, 0x8f, 0x7d, 0x3e,
+	0xf1, 0x75, 0x50, 0xe0, 0xd7, 0x6c, 0x78, 0xf5, 0x24, 0x8f, 0x87, 0x13, 0x5d, 0x4f, 0x74, 0xbe,
+	0x05, 0x7b, 0xa1, 0xd6, 0x72, 0xfb, 0x5b, 0xb0, 0x3a, 0x1a, 0x0e, 0x07, 0x6a, 0x4e, 0x4c, 0xa8,
+	0xf6, 0xbb, 0x27, 0x3d, 0x67, 0xa5, 0xf3, 0xa7, 0x01, 0xad, 0xf7, 0x66, 0x90, 0xb8, 0x50, 0x9b,
+	0x55, 0x31, 0xb0, 0xca, 0xec, 0x48, 0x3e, 0x06, 0xb3, 0xf8, 0x79, 0x14, 0x68, 0xb2, 0xe1, 0xcd,
+	0xcf, 0xa5, 0x86, 0xa8, 0x94, 0x1a, 0xe2, 0x3b, 0x30, 0xe3, 0x5c, 0x52, 0xc9, 0x33, 0xe1, 0x56,
+	0xef, 0x59, 0xca, 0x33, 0xe0, 0x41, 0xeb, 0x55, 0xe5, 0xd7, 0xd9, 0xbf, 0x2a, 0x1f, 0xff, 0x55,
+	0xfd, 0x37, 0x00, 0xbc, 0x22, 0x97, 0xf6, 0x65, 0x09, 0x00, 0x00,
 }
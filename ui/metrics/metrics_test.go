@@ -0,0 +1,105 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"android/soong/ui/metrics/metrics_proto"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func TestSoongBuildMetrics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data, err := proto.Marshal(&metrics_proto.MetricsBase{
+		ModuleTypes: []*metrics_proto.ModuleTypeInfo{
+			{
+				BuildSystem:  metrics_proto.ModuleTypeInfo_SOONG.Enum(),
+				ModuleType:   proto.String("cc_library"),
+				NumOfModules: proto.Uint32(2),
+			},
+		},
+		SoongBuildMetrics: &metrics_proto.SoongBuildMetrics{
+			Modules:  proto.Uint32(2),
+			Variants: proto.Uint32(6),
+			MaxRssKb: proto.Uint64(1024),
+			Mutators: []*metrics_proto.PerfInfo{
+				{Desc: proto.String("arch"), Name: proto.String("mutator")},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "soong_build_metrics.pb")
+	if err := ioutil.WriteFile(file, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New()
+	m.SetModuleTypes(metrics_proto.ModuleTypeInfo_MAKE, map[string]int{
+		"BUILD_SHARED_LIBRARY": 3,
+		"BUILD_PREBUILT":       1,
+	})
+	if err := m.SetSoongBuildMetrics(file); err != nil {
+		t.Fatal(err)
+	}
+
+	type moduleType struct {
+		buildSystem metrics_proto.ModuleTypeInfo_BUILDSYSTEM
+		moduleType  string
+		count       uint32
+	}
+	var got []moduleType
+	for _, info := range m.metrics.GetModuleTypes() {
+		got = append(got, moduleType{info.GetBuildSystem(), info.GetModuleType(), info.GetNumOfModules()})
+	}
+	want := []moduleType{
+		{metrics_proto.ModuleTypeInfo_MAKE, "BUILD_PREBUILT", 1},
+		{metrics_proto.ModuleTypeInfo_MAKE, "BUILD_SHARED_LIBRARY", 3},
+		{metrics_proto.ModuleTypeInfo_SOONG, "cc_library", 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected module types %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected module types %v, got %v", want, got)
+			break
+		}
+	}
+
+	soong := m.metrics.GetSoongBuildMetrics()
+	if soong.GetModules() != 2 || soong.GetVariants() != 6 || soong.GetMaxRssKb() != 1024 ||
+		len(soong.GetMutators()) != 1 {
+		t.Errorf("unexpected soong_build metrics %v", soong)
+	}
+}
+
+func TestSoongBuildMetricsMissing(t *testing.T) {
+	m := New()
+	if err := m.SetSoongBuildMetrics(filepath.Join(os.TempDir(), "missing_soong_build_metrics.pb")); err == nil {
+		t.Error("expected an error for a missing soong_build metrics file")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"android/soong/ui/metrics/metrics_proto"
+	"android/soong/ui/status"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// The number of the most expensive actions recorded in the metrics.
+const maxExpensiveActions = 20
+
+// StatusOutput returns a StatusOutput that records the actions that used the
+// most CPU time in the metrics, as they finish.
+func (m *Metrics) StatusOutput() status.StatusOutput {
+	return &statusOutput{
+		metrics: m,
+		running: map[*status.Action]time.Time{},
+	}
+}
+
+type statusOutput struct {
+	metrics *Metrics
+
+	running map[*status.Action]time.Time
+}
+
+func (s *statusOutput) StartAction(action *status.Action, counts status.Counts) {
+	s.running[action] = time.Now()
+}
+
+func (s *statusOutput) FinishAction(result status.ActionResult, counts status.Counts) {
+	start, ok := s.running[result.Action]
+	if !ok {
+		return
+	}
+	delete(s.running, result.Action)
+
+	// Only actions run by ninja report their resource usage.
+	if result.Stats.UserTime+result.Stats.SystemTime == 0 {
+		return
+	}
+
+	s.metrics.addExpensiveAction(newActionInfo(result.Action, result.Stats, start, time.Since(start)))
+}
+
+func (s *statusOutput) Flush()                                        {}
+func (s *statusOutput) Message(level status.MsgLevel, message string) {}
+
+func newActionInfo(action *status.Action, stats status.ActionResultStats, start time.Time,
+	duration time.Duration) *metrics_proto.ActionInfo {
+
+	desc := action.Description
+	if desc == "" {
+		desc = action.Command
+	}
+	info := &metrics_proto.ActionInfo{
+		Desc:       proto.String(desc),
+		StartTime:  proto.Uint64(uint64(start.UnixNano())),
+		RealTime:   proto.Uint64(uint64(duration.Nanoseconds())),
+		UserTime:   proto.Uint32(stats.UserTime),
+		SystemTime: proto.Uint32(stats.SystemTime),
+		MaxRssKb:   proto.Uint64(stats.MaxRssKB),
+	}
+	if len(action.Outputs) > 0 {
+		info.Output = proto.String(action.Outputs[0])
+	}
+	return info
+}
+
+func cpuTime(action *metrics_proto.ActionInfo) uint64 {
+	return uint64(action.GetUserTime()) + uint64(action.GetSystemTime())
+}
+
+// addExpensiveAction adds action to the most expensive actions if it used
+// more CPU time than the least expensive of them.
+func (m *Metrics) addExpensiveAction(action *metrics_proto.ActionInfo) {
+	actions := m.metrics.ExpensiveActions
+	i := sort.Search(len(actions), func(i int) bool {
+		return cpuTime(actions[i]) < cpuTime(action)
+	})
+	if i >= maxExpensiveActions {
+		return
+	}
+
+	actions = append(actions, nil)
+	copy(actions[i+1:], actions[i:])
+	actions[i] = action
+	if len(actions) > maxExpensiveActions {
+		actions = actions[:maxExpensiveActions]
+	}
+	m.metrics.ExpensiveActions = actions
+}
+
+// SetCriticalPath records the critical path of the build, as found by
+// status.CriticalPath, in the metrics.
+func (m *Metrics) SetCriticalPath(path []status.CriticalPathEntry, elapsed time.Duration) {
+	if len(path) == 0 {
+		return
+	}
+
+	info := &metrics_proto.CriticalPathInfo{
+		CriticalPathTime: proto.Uint64(uint64(path[len(path)-1].Cumulative.Nanoseconds())),
+		ElapsedTime:      proto.Uint64(uint64(elapsed.Nanoseconds())),
+	}
+	for _, entry := range path {
+		info.CriticalPath = append(info.CriticalPath,
+			newActionInfo(entry.Action, entry.Stats, entry.Start, entry.Duration))
+	}
+	m.metrics.CriticalPathInfo = info
+}
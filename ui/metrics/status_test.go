@@ -0,0 +1,75 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"android/soong/ui/status"
+)
+
+func TestExpensiveActions(t *testing.T) {
+	m := New()
+	s := &status.Status{}
+	s.AddOutput(m.StatusOutput())
+	tool := s.StartTool()
+
+	finish := func(name string, userTime, systemTime uint32) {
+		action := &status.Action{Description: name, Outputs: []string{"out/" + name}}
+		tool.StartAction(action)
+		tool.FinishAction(status.ActionResult{
+			Action: action,
+			Stats: status.ActionResultStats{
+				UserTime:   userTime,
+				SystemTime: systemTime,
+				MaxRssKB:   1024,
+			},
+		})
+	}
+
+	finish("cheap", 1, 1)
+	finish("expensive", 100, 50)
+	finish("unknown", 0, 0)
+	for i := 0; i < maxExpensiveActions; i++ {
+		finish(fmt.Sprintf("medium%d", i), 10, uint32(i))
+	}
+
+	actions := m.metrics.GetExpensiveActions()
+	if len(actions) != maxExpensiveActions {
+		t.Fatalf("expected %d actions, got %d", maxExpensiveActions, len(actions))
+	}
+
+	var names []string
+	for _, action := range actions[:3] {
+		names = append(names, action.GetDesc())
+	}
+	if g, w := names, []string{"expensive", "medium19", "medium18"}; !reflect.DeepEqual(g, w) {
+		t.Errorf("expected most expensive actions %q, got %q", w, g)
+	}
+
+	first := actions[0]
+	if first.GetOutput() != "out/expensive" || first.GetUserTime() != 100 ||
+		first.GetSystemTime() != 50 || first.GetMaxRssKb() != 1024 {
+		t.Errorf("unexpected action info %v", first)
+	}
+
+	for _, action := range actions {
+		if action.GetDesc() == "cheap" || action.GetDesc() == "unknown" {
+			t.Errorf("unexpected action %q in most expensive actions", action.GetDesc())
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"android/soong/ui/logger"
+	"android/soong/ui/status/build_event"
+)
+
+type buildEventStream struct {
+	log logger.Logger
+	w   io.WriteCloser
+
+	nextId  uint32
+	running map[*Action]runningAction
+}
+
+type runningAction struct {
+	id    uint32
+	start time.Time
+}
+
+// NewBuildEventStream returns a StatusOutput that writes every tool, action
+// and message event to target, as a stream of build_event.Event messages that
+// are each preceded by their size encoded as a varint. The target is either
+// the name of a file, or "unix:" followed by the path of a unix socket to
+// connect to.
+func NewBuildEventStream(log logger.Logger, target string) StatusOutput {
+	var w io.WriteCloser
+	var err error
+	if socket := strings.TrimPrefix(target, "unix:"); socket != target {
+		w, err = net.Dial("unix", socket)
+	} else {
+		w, err = os.Create(target)
+	}
+	if err != nil {
+		log.Println("Failed to open build event stream:", err)
+		return nil
+	}
+
+	return &buildEventStream{
+		log:     log,
+		w:       w,
+		running: make(map[*Action]runningAction),
+	}
+}
+
+var _ ToolStatusOutput = (*buildEventStream)(nil)
+
+func (b *buildEventStream) StartTool(counts Counts) {
+	b.write(&build_event.Event{
+		Counts:      eventCounts(counts),
+		ToolStarted: &build_event.Event_ToolStarted{},
+	})
+}
+
+func (b *buildEventStream) FinishTool(counts Counts) {
+	b.write(&build_event.Event{
+		Counts:       eventCounts(counts),
+		ToolFinished: &build_event.Event_ToolFinished{},
+	})
+}
+
+func (b *buildEventStream) StartAction(action *Action, counts Counts) {
+	id := b.nextId
+	b.nextId++
+	b.running[action] = runningAction{
+		id:    id,
+		start: time.Now(),
+	}
+
+	b.write(&build_event.Event{
+		Counts: eventCounts(counts),
+		ActionStarted: &build_event.Event_ActionStarted{
+			Id:          proto.Uint32(id),
+			Description: proto.String(action.Description),
+			Outputs:     action.Outputs,
+			Command:     proto.String(action.Command),
+		},
+	})
+}
+
+func (b *buildEventStream) FinishAction(result ActionResult, counts Counts) {
+	started, ok := b.running[result.Action]
+	if !ok {
+		return
+	}
+	delete(b.running, result.Action)
+
+	finished := &build_event.Event_ActionFinished{
+		Id:          proto.Uint32(started.id),
+		Description: proto.String(result.Description),
+		Outputs:     result.Outputs,
+		Command:     proto.String(result.Command),
+		Duration:    proto.Uint64(uint64(time.Since(started.start) / time.Millisecond)),
+		ExitStatus:  proto.Int32(0),
+		Output:      proto.String(result.Output),
+	}
+	if result.Error != nil {
+		exitStatus := 1
+		if e, ok := result.Error.(interface{ ExitCode() int }); ok {
+			exitStatus = e.ExitCode()
+		}
+		finished.ExitStatus = proto.Int32(int32(exitStatus))
+		finished.Error = proto.String(result.Error.Error())
+	}
+
+	b.write(&build_event.Event{
+		Counts:         eventCounts(counts),
+		ActionFinished: finished,
+	})
+}
+
+func (b *buildEventStream) Message(level MsgLevel, message string) {
+	b.write(&build_event.Event{
+		Message: &build_event.Event_Message{
+			Level:   build_event.Event_Message_Level(level).Enum(),
+			Message: proto.String(message),
+		},
+	})
+}
+
+func (b *buildEventStream) Flush() {
+	if b.w != nil {
+		b.w.Close()
+		b.w = nil
+	}
+}
+
+func (b *buildEventStream) write(event *build_event.Event) {
+	if b.w == nil {
+		return
+	}
+
+	event.Time = proto.Uint64(uint64(time.Now().UnixNano() / int64(time.Millisecond)))
+
+	data, err := proto.Marshal(event)
+	if err != nil {
+		b.log.Println("Failed to marshal build event:", err)
+		return
+	}
+
+	buf := append(proto.EncodeVarint(uint64(len(data))), data...)
+	if _, err := b.w.Write(buf); err != nil {
+		// The reader has probably gone away, stop writing events.
+		b.log.Println("Failed to write build event stream:", err)
+		b.Flush()
+	}
+}
+
+func eventCounts(counts Counts) *build_event.Event_Counts {
+	return &build_event.Event_Counts{
+		TotalActions:    proto.Uint32(uint32(counts.TotalActions)),
+		RunningActions:  proto.Uint32(uint32(counts.RunningActions)),
+		StartedActions:  proto.Uint32(uint32(counts.StartedActions)),
+		FinishedActions: proto.Uint32(uint32(counts.FinishedActions)),
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"android/soong/ui/logger"
+	"android/soong/ui/status/build_event"
+)
+
+func readBuildEvents(t *testing.T, filename string) []*build_event.Event {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var events []*build_event.Event
+	for {
+		size, err := readVarInt(r)
+		if err == io.EOF {
+			return events
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			t.Fatal(err)
+		}
+
+		event := &build_event.Event{}
+		if err := proto.Unmarshal(buf, event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+}
+
+func TestBuildEventStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "build_event_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "build_events")
+	output := NewBuildEventStream(logger.New(ioutil.Discard), filename)
+	if output == nil {
+		t.Fatal("failed to create build event stream")
+	}
+
+	status := &Status{}
+	status.AddOutput(output)
+
+	tool := status.StartTool()
+	tool.SetTotalActions(2)
+
+	a := &Action{Description: "a", Outputs: []string{"out/a"}, Command: "touch out/a"}
+	tool.StartAction(a)
+	tool.FinishAction(ActionResult{Action: a})
+
+	b := &Action{Description: "b"}
+	tool.StartAction(b)
+	tool.Print("message")
+	tool.FinishAction(ActionResult{Action: b, Output: "output", Error: exitCodeError(2)})
+
+	tool.Finish()
+	status.Finish()
+
+	events := readBuildEvents(t, filename)
+
+	var kinds []string
+	for _, event := range events {
+		if event.GetTime() == 0 {
+			t.Errorf("expected time to be set in %v", event)
+		}
+		switch {
+		case event.ToolStarted != nil:
+			kinds = append(kinds, "tool_started")
+		case event.ToolFinished != nil:
+			kinds = append(kinds, "tool_finished")
+		case event.ActionStarted != nil:
+			kinds = append(kinds, "action_started")
+		case event.ActionFinished != nil:
+			kinds = append(kinds, "action_finished")
+		case event.Message != nil:
+			kinds = append(kinds, "message")
+		}
+	}
+	expectedKinds := []string{
+		"tool_started",
+		"action_started",
+		"action_finished",
+		"action_started",
+		"message",
+		"action_finished",
+		"tool_finished",
+	}
+	if !reflect.DeepEqual(kinds, expectedKinds) {
+		t.Fatalf("expected events %q, got %q", expectedKinds, kinds)
+	}
+
+	startedA := events[1].ActionStarted
+	if startedA.GetId() != 0 || startedA.GetDescription() != "a" ||
+		!reflect.DeepEqual(startedA.GetOutputs(), []string{"out/a"}) || startedA.GetCommand() != "touch out/a" {
+		t.Errorf("unexpected action started event %v", startedA)
+	}
+	if g, w := events[1].Counts.GetStartedActions(), uint32(1); g != w {
+		t.Errorf("expected %d started actions, got %d", w, g)
+	}
+
+	finishedA := events[2].ActionFinished
+	if finishedA.GetId() != 0 || finishedA.GetExitStatus() != 0 || finishedA.Error != nil {
+		t.Errorf("unexpected action finished event %v", finishedA)
+	}
+
+	message := events[4].Message
+	if message.GetLevel() != build_event.Event_Message_PRINT || message.GetMessage() != "message" {
+		t.Errorf("unexpected message event %v", message)
+	}
+
+	finishedB := events[5].ActionFinished
+	if finishedB.GetId() != 1 || finishedB.GetExitStatus() != 2 ||
+		finishedB.GetError() != "exited with code: 2" || finishedB.GetOutput() != "output" {
+		t.Errorf("unexpected action finished event %v", finishedB)
+	}
+	if g, w := events[5].Counts.GetFinishedActions(), uint32(2); g != w {
+		t.Errorf("expected %d finished actions, got %d", w, g)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"sync"
+	"time"
+)
+
+// CriticalPath is a StatusOutput that finds the critical path of the build:
+// the chain of dependent actions with the longest total duration, which
+// determines the wall time of the build no matter how many actions run in
+// parallel. An action depends on the actions that ran in the same build and
+// produced one of its inputs.
+type CriticalPath struct {
+	// The finished actions, keyed by their outputs.
+	nodes   map[string]*criticalPathNode
+	running map[*Action]time.Time
+
+	// The finished action with the longest cumulative duration, which is
+	// the last action on the critical path.
+	last *criticalPathNode
+
+	// The start of the first action, and the end of the last action.
+	start, end time.Time
+
+	now func() time.Time
+
+	// Protects the above, since the critical path may be read while actions
+	// are still finishing.
+	lock sync.Mutex
+}
+
+type criticalPathNode struct {
+	action *Action
+	stats  ActionResultStats
+
+	start      time.Time
+	duration   time.Duration
+	cumulative time.Duration
+
+	// The input of the action on the critical path, if any.
+	input *criticalPathNode
+}
+
+// CriticalPathEntry is an action on the critical path of the build.
+type CriticalPathEntry struct {
+	Action *Action
+	Stats  ActionResultStats
+
+	// Start is when the action started, and Duration is how long it ran.
+	Start    time.Time
+	Duration time.Duration
+
+	// Cumulative is the total duration of the actions on the critical path
+	// up to and including this one.
+	Cumulative time.Duration
+}
+
+// NewCriticalPath returns a CriticalPath that has not seen any actions.
+func NewCriticalPath() *CriticalPath {
+	return &CriticalPath{
+		nodes:   make(map[string]*criticalPathNode),
+		running: make(map[*Action]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (cp *CriticalPath) StartAction(action *Action, counts Counts) {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+
+	start := cp.now()
+	if cp.start.IsZero() {
+		cp.start = start
+	}
+	cp.running[action] = start
+}
+
+func (cp *CriticalPath) FinishAction(result ActionResult, counts Counts) {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+
+	start, ok := cp.running[result.Action]
+	if !ok {
+		return
+	}
+	delete(cp.running, result.Action)
+
+	// The action could only start once all of its inputs were built, so it
+	// extends the path of the input with the longest cumulative duration.
+	var input *criticalPathNode
+	for _, in := range result.Action.Inputs {
+		if n := cp.nodes[in]; n != nil && (input == nil || n.cumulative > input.cumulative) {
+			input = n
+		}
+	}
+
+	end := cp.now()
+	node := &criticalPathNode{
+		action:     result.Action,
+		stats:      result.Stats,
+		start:      start,
+		duration:   end.Sub(start),
+		cumulative: end.Sub(start),
+		input:      input,
+	}
+	if input != nil {
+		node.cumulative += input.cumulative
+	}
+
+	for _, out := range result.Action.Outputs {
+		cp.nodes[out] = node
+	}
+	if cp.last == nil || node.cumulative > cp.last.cumulative {
+		cp.last = node
+	}
+	cp.end = end
+}
+
+func (cp *CriticalPath) Message(level MsgLevel, message string) {}
+
+func (cp *CriticalPath) Flush() {}
+
+// Path returns the actions on the critical path of the build, in the order
+// that they ran.
+func (cp *CriticalPath) Path() []CriticalPathEntry {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+
+	var path []CriticalPathEntry
+	for n := cp.last; n != nil; n = n.input {
+		path = append(path, CriticalPathEntry{
+			Action:     n.action,
+			Stats:      n.stats,
+			Start:      n.start,
+			Duration:   n.duration,
+			Cumulative: n.cumulative,
+		})
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Elapsed returns the wall time from the start of the first action to the end
+// of the last action that finished.
+func (cp *CriticalPath) Elapsed() time.Duration {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+
+	return cp.end.Sub(cp.start)
+}
@@ -0,0 +1,120 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testCriticalPath struct {
+	*CriticalPath
+	clock   time.Time
+	counts  Counts
+	actions map[string]*Action
+}
+
+func (t *testCriticalPath) start(output string, inputs ...string) {
+	t.actions[output] = &Action{
+		Description: output,
+		Outputs:     []string{output},
+		Inputs:      inputs,
+	}
+	t.StartAction(t.actions[output], t.counts)
+}
+
+func (t *testCriticalPath) finish(output string) {
+	t.FinishAction(ActionResult{Action: t.actions[output]}, t.counts)
+}
+
+func (t *testCriticalPath) advance(d time.Duration) {
+	t.clock = t.clock.Add(d)
+}
+
+func TestCriticalPath(t *testing.T) {
+	cp := &testCriticalPath{
+		CriticalPath: NewCriticalPath(),
+		clock:        time.Unix(1000, 0),
+		actions:      make(map[string]*Action),
+	}
+	cp.now = func() time.Time { return cp.clock }
+
+	// c (5s) depends on a (10s) and b (12s), e (1s) depends on c, and
+	// d (20s) runs in parallel with all of them.
+	cp.start("a")
+	cp.start("b")
+	cp.start("d")
+	cp.advance(10 * time.Second)
+	cp.finish("a")
+	cp.advance(2 * time.Second)
+	cp.finish("b")
+	cp.start("c", "a", "b", "unknown")
+	cp.advance(5 * time.Second)
+	cp.finish("c")
+	cp.start("e", "c")
+	cp.advance(time.Second)
+	cp.finish("e")
+	cp.advance(2 * time.Second)
+	cp.finish("d")
+
+	type entry struct {
+		action               string
+		duration, cumulative time.Duration
+	}
+	var got []entry
+	for _, e := range cp.Path() {
+		got = append(got, entry{e.Action.Description, e.Duration, e.Cumulative})
+	}
+
+	// d on its own took longer than b, c and e together (18s).
+	want := []entry{
+		{"d", 20 * time.Second, 20 * time.Second},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want critical path %v, got %v", want, got)
+	}
+
+	// f (3s) extends the path through e to 21s. It goes through b rather
+	// than a, since b took longer.
+	cp.start("f", "e")
+	cp.advance(3 * time.Second)
+	cp.finish("f")
+
+	got = nil
+	for _, e := range cp.Path() {
+		got = append(got, entry{e.Action.Description, e.Duration, e.Cumulative})
+	}
+	want = []entry{
+		{"b", 12 * time.Second, 12 * time.Second},
+		{"c", 5 * time.Second, 17 * time.Second},
+		{"e", 1 * time.Second, 18 * time.Second},
+		{"f", 3 * time.Second, 21 * time.Second},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want critical path %v, got %v", want, got)
+	}
+
+	if g, w := cp.Elapsed(), 23*time.Second; g != w {
+		t.Errorf("want elapsed time %s, got %s", w, g)
+	}
+}
+
+func TestCriticalPathEmpty(t *testing.T) {
+	cp := NewCriticalPath()
+	if path := cp.Path(); len(path) != 0 {
+		t.Errorf("expected empty critical path, got %v", path)
+	}
+}
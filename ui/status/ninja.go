@@ -86,6 +86,7 @@ func ninjaReader(status ToolStatus, fifo string) {
 			action := &Action{
 				Description: msg.EdgeStarted.GetDesc(),
 				Outputs:     msg.EdgeStarted.Outputs,
+				Inputs:      msg.EdgeStarted.Inputs,
 				Command:     msg.EdgeStarted.GetCommand(),
 			}
 			status.StartAction(action)
@@ -98,13 +99,18 @@ func ninjaReader(status ToolStatus, fifo string) {
 				var err error
 				exitCode := int(msg.EdgeFinished.GetStatus())
 				if exitCode != 0 {
-					err = fmt.Errorf("exited with code: %d", exitCode)
+					err = exitCodeError(exitCode)
 				}
 
 				status.FinishAction(ActionResult{
 					Action: started,
 					Output: msg.EdgeFinished.GetOutput(),
 					Error:  err,
+					Stats: ActionResultStats{
+						UserTime:   msg.EdgeFinished.GetUserTime(),
+						SystemTime: msg.EdgeFinished.GetSystemTime(),
+						MaxRssKB:   msg.EdgeFinished.GetMaxRssKb(),
+					},
 				})
 			}
 		}
@@ -127,6 +133,18 @@ func ninjaReader(status ToolStatus, fifo string) {
 	}
 }
 
+// exitCodeError is the error of an action that exited with a non-zero code.
+type exitCodeError int
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exited with code: %d", int(e))
+}
+
+// ExitCode returns the exit code of the action.
+func (e exitCodeError) ExitCode() int {
+	return int(e)
+}
+
 func readVarInt(r *bufio.Reader) (int, error) {
 	ret := 0
 	shift := uint(0)
@@ -54,7 +54,7 @@ func (x *Status_Message_Level) UnmarshalJSON(data []byte) error {
 	return nil
 }
 func (Status_Message_Level) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_frontend_5a49d9b15a642005, []int{0, 5, 0}
+	return fileDescriptor_frontend_acde741fa4788f45, []int{0, 5, 0}
 }
 
 type Status struct {
@@ -73,7 +73,7 @@ func (m *Status) Reset()         { *m = Status{} }
 func (m *Status) String() string { return proto.CompactTextString(m) }
 func (*Status) ProtoMessage()    {}
 func (*Status) Descriptor() ([]byte, []int) {
-	return fileDescriptor_frontend_5a49d9b15a642005, []int{0}
+	return fileDescriptor_frontend_acde741fa4788f45, []int{0}
 }
 func (m *Status) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Status.Unmarshal(m, b)
@@ -147,7 +147,7 @@ func (m *Status_TotalEdges) Reset()         { *m = Status_TotalEdges{} }
 func (m *Status_TotalEdges) String() string { return proto.CompactTextString(m) }
 func (*Status_TotalEdges) ProtoMessage()    {}
 func (*Status_TotalEdges) Descriptor() ([]byte, []int) {
-	return fileDescriptor_frontend_5a49d9b15a642005, []int{0, 0}
+	return fileDescriptor_frontend_acde741fa4788f45, []int{0, 0}
 }
 func (m *Status_TotalEdges) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Status_TotalEdges.Unmarshal(m, b)
@@ -188,7 +188,7 @@ func (m *Status_BuildStarted) Reset()         { *m = Status_BuildStarted{} }
 func (m *Status_BuildStarted) String() string { return proto.CompactTextString(m) }
 func (*Status_BuildStarted) ProtoMessage()    {}
 func (*Status_BuildStarted) Descriptor() ([]byte, []int) {
-	return fileDescriptor_frontend_5a49d9b15a642005, []int{0, 1}
+	return fileDescriptor_frontend_acde741fa4788f45, []int{0, 1}
 }
 func (m *Status_BuildStarted) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Status_BuildStarted.Unmarshal(m, b)
@@ -232,7 +232,7 @@ func (m *Status_BuildFinished) Reset()         { *m = Status_BuildFinished{} }
 func (m *Status_BuildFinished) String() string { return proto.CompactTextString(m) }
 func (*Status_BuildFinished) ProtoMessage()    {}
 func (*Status_BuildFinished) Descriptor() ([]byte, []int) {
-	return fileDescriptor_frontend_5a49d9b15a642005, []int{0, 2}
+	return fileDescriptor_frontend_acde741fa4788f45, []int{0, 2}
 }
 func (m *Status_BuildFinished) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Status_BuildFinished.Unmarshal(m, b)
@@ -276,7 +276,7 @@ func (m *Status_EdgeStarted) Reset()         { *m = Status_EdgeStarted{} }
 func (m *Status_EdgeStarted) String() string { return proto.CompactTextString(m) }
 func (*Status_EdgeStarted) ProtoMessage()    {}
 func (*Status_EdgeStarted) Descriptor() ([]byte, []int) {
-	return fileDescriptor_frontend_5a49d9b15a642005, []int{0, 3}
+	return fileDescriptor_frontend_acde741fa4788f45, []int{0, 3}
 }
 func (m *Status_EdgeStarted) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Status_EdgeStarted.Unmarshal(m, b)
@@ -353,7 +353,13 @@ type Status_EdgeFinished struct {
 	// Exit status (0 for success).
 	Status *int32 `protobuf:"zigzag32,3,opt,name=status" json:"status,omitempty"`
 	// Edge output, may contain ANSI codes.
-	Output               *string  `protobuf:"bytes,4,opt,name=output" json:"output,omitempty"`
+	Output *string `protobuf:"bytes,4,opt,name=output" json:"output,omitempty"`
+	// Number of milliseconds spent executing in user mode
+	UserTime *uint32 `protobuf:"varint,5,opt,name=user_time,json=userTime" json:"user_time,omitempty"`
+	// Number of milliseconds spent executing in kernel mode
+	SystemTime *uint32 `protobuf:"varint,6,opt,name=system_time,json=systemTime" json:"system_time,omitempty"`
+	// Max resident set size in kB
+	MaxRssKb             *uint64  `protobuf:"varint,7,opt,name=max_rss_kb,json=maxRssKb" json:"max_rss_kb,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -363,7 +369,7 @@ func (m *Status_EdgeFinished) Reset()         { *m = Status_EdgeFinished{} }
 func (m *Status_EdgeFinished) String() string { return proto.CompactTextString(m) }
 func (*Status_EdgeFinished) ProtoMessage()    {}
 func (*Status_EdgeFinished) Descriptor() ([]byte, []int) {
-	return fileDescriptor_frontend_5a49d9b15a642005, []int{0, 4}
+	return fileDescriptor_frontend_acde741fa4788f45, []int{0, 4}
 }
 func (m *Status_EdgeFinished) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Status_EdgeFinished.Unmarshal(m, b)
@@ -411,6 +417,27 @@ func (m *Status_EdgeFinished) GetOutput() string {
 	return ""
 }
 
+func (m *Status_EdgeFinished) GetUserTime() uint32 {
+	if m != nil && m.UserTime != nil {
+		return *m.UserTime
+	}
+	return 0
+}
+
+func (m *Status_EdgeFinished) GetSystemTime() uint32 {
+	if m != nil && m.SystemTime != nil {
+		return *m.SystemTime
+	}
+	return 0
+}
+
+func (m *Status_EdgeFinished) GetMaxRssKb() uint64 {
+	if m != nil && m.MaxRssKb != nil {
+		return *m.MaxRssKb
+	}
+	return 0
+}
+
 type Status_Message struct {
 	// Message priority level (INFO, WARNING, or ERROR).
 	Level *Status_Message_Level `protobuf:"varint,1,opt,name=level,enum=ninja.Status_Message_Level,def=0" json:"level,omitempty"`
@@ -425,7 +452,7 @@ func (m *Status_Message) Reset()         { *m = Status_Message{} }
 func (m *Status_Message) String() string { return proto.CompactTextString(m) }
 func (*Status_Message) ProtoMessage()    {}
 func (*Status_Message) Descriptor() ([]byte, []int) {
-	return fileDescriptor_frontend_5a49d9b15a642005, []int{0, 5}
+	return fileDescriptor_frontend_acde741fa4788f45, []int{0, 5}
 }
 func (m *Status_Message) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Status_Message.Unmarshal(m, b)
@@ -472,39 +499,43 @@ func init() {
 	proto.RegisterEnum("ninja.Status_Message_Level", Status_Message_Level_name, Status_Message_Level_value)
 }
 
-func init() { proto.RegisterFile("frontend.proto", fileDescriptor_frontend_5a49d9b15a642005) }
-
-var fileDescriptor_frontend_5a49d9b15a642005 = []byte{
-	// 496 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0xd1, 0x6e, 0xd3, 0x30,
-	0x14, 0xa5, 0x69, 0xd3, 0x34, 0x37, 0x6d, 0x28, 0x96, 0x40, 0x59, 0x10, 0xa2, 0xda, 0xd3, 0x78,
-	0x20, 0x48, 0xbc, 0x20, 0x10, 0x12, 0xa2, 0xd2, 0x06, 0x43, 0xd0, 0x49, 0xde, 0x24, 0x24, 0x5e,
-	0xaa, 0x74, 0xf6, 0x86, 0x51, 0xe2, 0x54, 0xb1, 0xbb, 0x5f, 0xe0, 0x7f, 0x78, 0xe0, 0xfb, 0x90,
-	0xaf, 0xed, 0x2c, 0x65, 0x7b, 0xcb, 0xf1, 0x3d, 0xe7, 0xde, 0x73, 0x8f, 0x1d, 0x48, 0xaf, 0xda,
-	0x46, 0x6a, 0x2e, 0x59, 0xb1, 0x6d, 0x1b, 0xdd, 0x90, 0x50, 0x0a, 0xf9, 0xab, 0x3c, 0xfc, 0x13,
-	0xc1, 0xf8, 0x5c, 0x97, 0x7a, 0xa7, 0xc8, 0x5b, 0x48, 0x74, 0xa3, 0xcb, 0x6a, 0xcd, 0xd9, 0x35,
-	0x57, 0xd9, 0x60, 0x31, 0x38, 0x4a, 0x5e, 0x67, 0x05, 0xf2, 0x0a, 0xcb, 0x29, 0x2e, 0x0c, 0xe1,
-	0xd8, 0xd4, 0x29, 0xe8, 0xee, 0x9b, 0x7c, 0x80, 0xd9, 0x66, 0x27, 0x2a, 0xb6, 0x56, 0xba, 0x6c,
-	0x35, 0x67, 0x59, 0x80, 0xe2, 0x7c, 0x5f, 0xbc, 0x34, 0x94, 0x73, 0xcb, 0xa0, 0xd3, 0x4d, 0x0f,
-	0x91, 0x25, 0xa4, 0xb6, 0xc1, 0x95, 0x90, 0x42, 0xfd, 0xe4, 0x2c, 0x1b, 0x62, 0x87, 0xa7, 0xf7,
-	0x74, 0x38, 0x71, 0x14, 0x6a, 0x67, 0x7a, 0x48, 0xde, 0xc3, 0xd4, 0x38, 0xef, 0x3c, 0x8c, 0xb0,
-	0xc3, 0xc1, 0x7e, 0x07, 0xe3, 0xd7, 0x5b, 0x48, 0xf8, 0x2d, 0x30, 0x2b, 0xa0, 0xba, 0x33, 0x10,
-	0xde, 0xb7, 0x82, 0x91, 0x77, 0xf3, 0x71, 0x5c, 0x37, 0xfe, 0x15, 0x44, 0x35, 0x57, 0xaa, 0xbc,
-	0xe6, 0xd9, 0x18, 0xa5, 0x8f, 0xf7, 0xa5, 0xdf, 0x6c, 0x91, 0x7a, 0x56, 0xfe, 0x12, 0xe0, 0x36,
-	0x4e, 0xf2, 0xfc, 0x6e, 0xfa, 0xb3, 0x7e, 0xc6, 0xf9, 0x17, 0x98, 0xf6, 0x03, 0x24, 0x0b, 0x48,
-	0xb6, 0x65, 0x5b, 0x56, 0x15, 0xaf, 0x84, 0xaa, 0x9d, 0xa0, 0x7f, 0x44, 0x32, 0x88, 0x6e, 0x78,
-	0xbb, 0x69, 0x14, 0xc7, 0xfb, 0x98, 0x50, 0x0f, 0xf3, 0x87, 0x30, 0xdb, 0x8b, 0x32, 0xff, 0x3b,
-	0x80, 0xa4, 0x17, 0x0d, 0x49, 0x21, 0x10, 0xcc, 0xf5, 0x0c, 0x04, 0x23, 0xcf, 0x00, 0x30, 0xd6,
-	0xb5, 0x16, 0xb5, 0xed, 0x36, 0xa3, 0x31, 0x9e, 0x5c, 0x88, 0x9a, 0x93, 0x27, 0x30, 0x16, 0x72,
-	0xbb, 0xd3, 0x2a, 0x1b, 0x2e, 0x86, 0x47, 0x31, 0x75, 0xc8, 0x38, 0x68, 0x76, 0x1a, 0x0b, 0x23,
-	0x2c, 0x78, 0x48, 0x08, 0x8c, 0x18, 0x57, 0x97, 0x98, 0x72, 0x4c, 0xf1, 0xdb, 0xb0, 0x2f, 0x9b,
-	0xba, 0x2e, 0x25, 0xc3, 0x04, 0x63, 0xea, 0xa1, 0xad, 0x48, 0xd5, 0x54, 0x3c, 0x8b, 0xec, 0x26,
-	0x0e, 0xe6, 0x02, 0xa6, 0xfd, 0x3b, 0xb9, 0x63, 0xfc, 0x00, 0x26, 0x5c, 0xb2, 0xbe, 0xed, 0x88,
-	0x4b, 0xe6, 0x4d, 0x2b, 0xbc, 0x1a, 0x7c, 0x6b, 0x8f, 0xa8, 0x43, 0xe6, 0xdc, 0xba, 0xc4, 0x17,
-	0x14, 0x53, 0x87, 0xf2, 0xdf, 0x03, 0x88, 0xdc, 0x25, 0x92, 0x37, 0x10, 0x56, 0xfc, 0x86, 0x57,
-	0x38, 0x29, 0xfd, 0xff, 0x99, 0x3a, 0x56, 0xf1, 0xd5, 0x50, 0xde, 0x8d, 0x4e, 0x57, 0x27, 0x67,
-	0xd4, 0xf2, 0xcd, 0x26, 0xfe, 0x95, 0x04, 0x76, 0x47, 0x07, 0x0f, 0x5f, 0x40, 0x88, 0x7c, 0x32,
-	0x01, 0x54, 0xcc, 0x1f, 0x90, 0x04, 0xa2, 0xef, 0x1f, 0xe9, 0xea, 0x74, 0xf5, 0x69, 0x3e, 0x20,
-	0x31, 0x84, 0xc7, 0x94, 0x9e, 0xd1, 0x79, 0xb0, 0x24, 0x9f, 0x87, 0x3f, 0x52, 0x9c, 0xb8, 0xf6,
-	0x7f, 0xf5, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x2e, 0x8c, 0xef, 0xcb, 0xe0, 0x03, 0x00, 0x00,
+func init() { proto.RegisterFile("frontend.proto", fileDescriptor_frontend_acde741fa4788f45) }
+
+var fileDescriptor_frontend_acde741fa4788f45 = []byte{
+	// 549 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x54, 0xdd, 0x6e, 0xd3, 0x4c,
+	0x10, 0xfd, 0x9c, 0xc4, 0xb1, 0x3d, 0x4e, 0xf2, 0x85, 0x95, 0x40, 0xae, 0x0b, 0x6a, 0xd4, 0xab,
+	0x72, 0x41, 0x90, 0xb8, 0x41, 0x20, 0x24, 0x44, 0xa4, 0x16, 0xca, 0x4f, 0x2a, 0x6d, 0x2b, 0x21,
+	0x71, 0x63, 0xd9, 0xdd, 0x69, 0x31, 0xf8, 0x27, 0xf2, 0x6e, 0xaa, 0xf2, 0x04, 0x5c, 0xf2, 0x36,
+	0xbc, 0x01, 0xef, 0x85, 0x76, 0x76, 0x9d, 0x3a, 0xb4, 0x77, 0x3e, 0x33, 0x67, 0xce, 0x9e, 0x39,
+	0xbb, 0x09, 0x4c, 0x2e, 0x9a, 0xba, 0x52, 0x58, 0x89, 0xf9, 0xaa, 0xa9, 0x55, 0xcd, 0xdc, 0x2a,
+	0xaf, 0xbe, 0xa5, 0xfb, 0xbf, 0x7c, 0x18, 0x9e, 0xaa, 0x54, 0xad, 0x25, 0x7b, 0x01, 0xa1, 0xaa,
+	0x55, 0x5a, 0x24, 0x28, 0x2e, 0x51, 0x46, 0xce, 0xcc, 0x39, 0x08, 0x9f, 0x45, 0x73, 0xe2, 0xcd,
+	0x0d, 0x67, 0x7e, 0xa6, 0x09, 0x87, 0xba, 0xcf, 0x41, 0x6d, 0xbe, 0xd9, 0x6b, 0x18, 0x67, 0xeb,
+	0xbc, 0x10, 0x89, 0x54, 0x69, 0xa3, 0x50, 0x44, 0x3d, 0x1a, 0x8e, 0xb7, 0x87, 0x17, 0x9a, 0x72,
+	0x6a, 0x18, 0x7c, 0x94, 0x75, 0x10, 0x5b, 0xc0, 0xc4, 0x08, 0x5c, 0xe4, 0x55, 0x2e, 0xbf, 0xa2,
+	0x88, 0xfa, 0xa4, 0xb0, 0x7b, 0x87, 0xc2, 0x91, 0xa5, 0xf0, 0x71, 0xd6, 0x85, 0xec, 0x15, 0x8c,
+	0xb4, 0xf3, 0x8d, 0x87, 0x01, 0x29, 0xec, 0x6c, 0x2b, 0x68, 0xbf, 0xad, 0x85, 0x10, 0x6f, 0x80,
+	0x5e, 0x81, 0xa6, 0x37, 0x06, 0xdc, 0xbb, 0x56, 0xd0, 0xe3, 0x9b, 0xf3, 0x47, 0xd8, 0x41, 0xec,
+	0x29, 0x78, 0x25, 0x4a, 0x99, 0x5e, 0x62, 0x34, 0xa4, 0xd1, 0xfb, 0xdb, 0xa3, 0x9f, 0x4c, 0x93,
+	0xb7, 0xac, 0xf8, 0x09, 0xc0, 0x4d, 0x9c, 0x6c, 0xef, 0x76, 0xfa, 0xe3, 0x6e, 0xc6, 0xf1, 0x7b,
+	0x18, 0x75, 0x03, 0x64, 0x33, 0x08, 0x57, 0x69, 0x93, 0x16, 0x05, 0x16, 0xb9, 0x2c, 0xed, 0x40,
+	0xb7, 0xc4, 0x22, 0xf0, 0xae, 0xb0, 0xc9, 0x6a, 0x89, 0x74, 0x1f, 0x3e, 0x6f, 0x61, 0xfc, 0x3f,
+	0x8c, 0xb7, 0xa2, 0x8c, 0x7f, 0x3b, 0x10, 0x76, 0xa2, 0x61, 0x13, 0xe8, 0xe5, 0xc2, 0x6a, 0xf6,
+	0x72, 0xc1, 0x1e, 0x01, 0x50, 0xac, 0x89, 0xca, 0x4b, 0xa3, 0x36, 0xe6, 0x01, 0x55, 0xce, 0xf2,
+	0x12, 0xd9, 0x03, 0x18, 0xe6, 0xd5, 0x6a, 0xad, 0x64, 0xd4, 0x9f, 0xf5, 0x0f, 0x02, 0x6e, 0x91,
+	0x76, 0x50, 0xaf, 0x15, 0x35, 0x06, 0xd4, 0x68, 0x21, 0x63, 0x30, 0x10, 0x28, 0xcf, 0x29, 0xe5,
+	0x80, 0xd3, 0xb7, 0x66, 0x9f, 0xd7, 0x65, 0x99, 0x56, 0x82, 0x12, 0x0c, 0x78, 0x0b, 0x4d, 0xa7,
+	0x92, 0x75, 0x81, 0x91, 0x67, 0x36, 0xb1, 0x30, 0xfe, 0xe3, 0xc0, 0xa8, 0x7b, 0x29, 0xb7, 0x9c,
+	0xef, 0x80, 0x8f, 0x95, 0xe8, 0xfa, 0xf6, 0xb0, 0x12, 0xad, 0x6b, 0x49, 0x77, 0x43, 0x8f, 0xed,
+	0x1e, 0xb7, 0x48, 0xd7, 0x8d, 0x4d, 0x7a, 0x42, 0x01, 0xb7, 0x88, 0xed, 0x42, 0xb0, 0x96, 0xd8,
+	0x18, 0x2d, 0x97, 0xb4, 0x7c, 0x5d, 0x20, 0xb1, 0x3d, 0x08, 0xe5, 0x0f, 0xa9, 0xb0, 0x34, 0xed,
+	0xa1, 0xb9, 0x3f, 0x53, 0x22, 0xc2, 0x43, 0x80, 0x32, 0xbd, 0x4e, 0x1a, 0x29, 0x93, 0xef, 0x19,
+	0xad, 0x31, 0xe0, 0x7e, 0x99, 0x5e, 0x73, 0x29, 0x3f, 0x64, 0xf1, 0x4f, 0x07, 0x3c, 0xfb, 0x42,
+	0xd8, 0x73, 0x70, 0x0b, 0xbc, 0xc2, 0x82, 0xb6, 0x98, 0xfc, 0xfb, 0x1b, 0xb0, 0xac, 0xf9, 0x47,
+	0x4d, 0x79, 0x39, 0x38, 0x5e, 0x1e, 0x9d, 0x70, 0xc3, 0xd7, 0x31, 0xb5, 0x4f, 0xb0, 0x67, 0x02,
+	0xb4, 0x70, 0xff, 0x31, 0xb8, 0xc4, 0x67, 0x3e, 0xd0, 0xc4, 0xf4, 0x3f, 0x16, 0x82, 0xf7, 0xf9,
+	0x0d, 0x5f, 0x1e, 0x2f, 0xdf, 0x4e, 0x1d, 0x16, 0x80, 0x7b, 0xc8, 0xf9, 0x09, 0x9f, 0xf6, 0x16,
+	0xec, 0x5d, 0xff, 0xcb, 0x84, 0x4e, 0x4c, 0xda, 0xbf, 0x8c, 0xbf, 0x03, 0x00, 0x4c, 0x4b, 0x77,
+	0x61, 0x3d, 0x04, 0x00, 0x00,
 }
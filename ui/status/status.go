@@ -32,6 +32,10 @@ type Action struct {
 	// but they can be any string.
 	Outputs []string
 
+	// Inputs is the (optional) list of inputs. Usually these are files,
+	// but they can be any string.
+	Inputs []string
+
 	// Command is the actual command line executed to perform the action.
 	// It's optional, but one of either Description or Command should be
 	// set.
@@ -50,6 +54,22 @@ type ActionResult struct {
 	// Error is nil if the Action succeeded, or set to an error if it
 	// failed.
 	Error error
+
+	// Stats is the resource usage of the Action, if it is known.
+	Stats ActionResultStats
+}
+
+// ActionResultStats describes the resources used to run an Action. The
+// values are zero if they are not known.
+type ActionResultStats struct {
+	// Number of milliseconds spent executing in user mode
+	UserTime uint32
+
+	// Number of milliseconds spent executing in kernel mode
+	SystemTime uint32
+
+	// Max resident set size in kB
+	MaxRssKB uint64
 }
 
 // Counts describes the number of actions in each state
@@ -175,6 +195,21 @@ type StatusOutput interface {
 	Flush()
 }
 
+// ToolStatusOutput is implemented by StatusOutputs that also want to know when
+// a tool starts and finishes reporting status through a ToolStatus. Like the
+// StatusOutput functions, these are called while holding the internal lock of
+// the Status.
+type ToolStatusOutput interface {
+	StatusOutput
+
+	// StartTool will be called once every time Status.StartTool is called.
+	StartTool(counts Counts)
+
+	// FinishTool will be called once every time ToolStatus.Finish is
+	// called, after the counts have been updated.
+	FinishTool(counts Counts)
+}
+
 // Status is the multiplexer / accumulator between ToolStatus instances (via
 // StartTool) and StatusOutputs (via AddOutput). There's generally one of these
 // per build process (though tools like multiproduct_kati may have multiple
@@ -203,6 +238,15 @@ func (s *Status) AddOutput(output StatusOutput) {
 
 // StartTool returns a new ToolStatus instance to report the status of a tool.
 func (s *Status) StartTool() ToolStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, o := range s.outputs {
+		if t, ok := o.(ToolStatusOutput); ok {
+			t.StartTool(s.counts)
+		}
+	}
+
 	return &toolStatus{
 		status: s,
 	}
@@ -251,6 +295,17 @@ func (s *Status) finishAction(result ActionResult) {
 	}
 }
 
+func (s *Status) finishTool() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, o := range s.outputs {
+		if t, ok := o.(ToolStatusOutput); ok {
+			t.FinishTool(s.counts)
+		}
+	}
+}
+
 func (s *Status) message(level MsgLevel, msg string) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -341,4 +396,6 @@ func (d *toolStatus) Finish() {
 	// TODO: update status to correct running/finished edges?
 	d.counts.RunningActions = 0
 	d.counts.TotalActions = d.counts.StartedActions
+
+	d.status.finishTool()
 }
@@ -28,6 +28,17 @@ type statusOutput struct {
 
 	start time.Time
 	quiet bool
+
+	// The number of running actions to list below the status line on
+	// smart terminals, and the actions that are currently running.
+	tableHeight    int
+	runningActions []runningAction
+	status         string
+}
+
+type runningAction struct {
+	action *status.Action
+	start  time.Time
 }
 
 // NewStatusOutput returns a StatusOutput that represents the
@@ -36,23 +47,31 @@ type statusOutput struct {
 //
 // statusFormat takes nearly all the same options as NINJA_STATUS.
 // %c is currently unsupported.
-func NewStatusOutput(w Writer, statusFormat string, quietBuild bool) status.StatusOutput {
+//
+// On smart terminals, up to tableHeight of the longest running actions are
+// listed below the status line along with how long they have been running.
+// A tableHeight of 0 only prints the status line.
+func NewStatusOutput(w Writer, statusFormat string, quietBuild bool, tableHeight int) status.StatusOutput {
 	return &statusOutput{
 		writer: w,
 		format: statusFormat,
 
 		start: time.Now(),
 		quiet: quietBuild,
+
+		tableHeight: tableHeight,
 	}
 }
 
 func (s *statusOutput) Message(level status.MsgLevel, message string) {
 	if level >= status.ErrorLvl {
 		s.writer.Print(fmt.Sprintf("FAILED: %s", message))
+		s.redrawTable()
 	} else if level > status.StatusLvl {
 		s.writer.Print(fmt.Sprintf("%s%s", level.Prefix(), message))
+		s.redrawTable()
 	} else if level == status.StatusLvl {
-		s.writer.StatusLine(message)
+		s.updateStatus(message)
 	}
 }
 
@@ -61,15 +80,29 @@ func (s *statusOutput) StartAction(action *status.Action, counts status.Counts)
 		return
 	}
 
+	if s.tableHeight > 0 {
+		s.runningActions = append(s.runningActions, runningAction{
+			action: action,
+			start:  time.Now(),
+		})
+	}
+
 	str := action.Description
 	if str == "" {
 		str = action.Command
 	}
 
-	s.writer.StatusLine(s.progress(counts) + str)
+	s.updateStatus(s.progress(counts) + str)
 }
 
 func (s *statusOutput) FinishAction(result status.ActionResult, counts status.Counts) {
+	for i, running := range s.runningActions {
+		if running.action == result.Action {
+			s.runningActions = append(s.runningActions[:i], s.runningActions[i+1:]...)
+			break
+		}
+	}
+
 	str := result.Description
 	if str == "" {
 		str = result.Command
@@ -84,14 +117,65 @@ func (s *statusOutput) FinishAction(result status.ActionResult, counts status.Co
 		} else {
 			s.writer.StatusAndMessage(progress, fmt.Sprintf("FAILED: %s\n%s\n%s", targets, result.Command, result.Output))
 		}
+		s.status = progress
+		s.redrawTable()
 	} else if result.Output != "" {
 		s.writer.StatusAndMessage(progress, result.Output)
+		s.status = progress
+		s.redrawTable()
+	} else {
+		s.updateStatus(progress)
+	}
+}
+
+func (s *statusOutput) Flush() {
+	// Collapse the table back into the status line, so that it doesn't
+	// remain on the screen after the build.
+	if len(s.runningActions) > 0 {
+		s.runningActions = nil
+		s.writer.StatusLine(s.status)
+	}
+}
+
+// updateStatus replaces the status line, and the table of running actions
+// below it.
+func (s *statusOutput) updateStatus(str string) {
+	s.status = str
+	if s.tableHeight > 0 && s.writer.isSmartTerminal() {
+		s.writer.StatusTable(str, s.table(time.Now()))
 	} else {
-		s.writer.StatusLine(progress)
+		s.writer.StatusLine(str)
 	}
 }
 
-func (s *statusOutput) Flush() {}
+// redrawTable prints the status line and table of running actions again
+// after a message has been printed below the previous one.
+func (s *statusOutput) redrawTable() {
+	if len(s.runningActions) > 0 && s.writer.isSmartTerminal() {
+		s.writer.StatusTable(s.status, s.table(time.Now()))
+	}
+}
+
+// table returns the lines listing the longest running actions, along with
+// how long they have been running at now.
+func (s *statusOutput) table(now time.Time) []string {
+	// runningActions is in the order that the actions were started, so the
+	// longest running actions are at the front.
+	running := s.runningActions
+	if len(running) > s.tableHeight {
+		running = running[:s.tableHeight]
+	}
+
+	lines := make([]string, len(running))
+	for i, r := range running {
+		str := r.action.Description
+		if str == "" {
+			str = r.action.Command
+		}
+		lines[i] = fmt.Sprintf("%6.1fs %s", now.Sub(r.start).Seconds(), str)
+	}
+	return lines
+}
 
 func (s *statusOutput) progress(counts status.Counts) string {
 	if s.format == "" {
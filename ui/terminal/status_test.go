@@ -0,0 +1,150 @@
+// Copyright 2019 Google Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terminal
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"android/soong/ui/status"
+)
+
+func newTestWriter(smartTerminal bool) (*writerImpl, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	w := &writerImpl{
+		stdio:         NewCustomStdio(nil, stdout, stdout),
+		haveBlankLine: true,
+		smartTerminal: smartTerminal,
+	}
+	return w, stdout
+}
+
+func TestStatusTable(t *testing.T) {
+	w, stdout := newTestWriter(true)
+
+	steps := []struct {
+		name   string
+		do     func()
+		output string
+	}{
+		{
+			name: "table",
+			do:   func() { w.StatusTable("status 1", []string{"a", "b"}) },
+			output: "\rstatus 1\x1b[K" +
+				"\na\x1b[K" +
+				"\nb\x1b[K",
+		},
+		{
+			name: "shorter table",
+			do:   func() { w.StatusTable("status 2", []string{"c"}) },
+			output: "\x1b[2A\rstatus 2\x1b[K" +
+				"\nc\x1b[K" +
+				"\x1b[J",
+		},
+		{
+			name:   "print",
+			do:     func() { w.Print("message") },
+			output: "\x1b[1A\r\x1b[Jmessage\n",
+		},
+		{
+			name:   "table after print",
+			do:     func() { w.StatusTable("status 3", []string{"d\nignored"}) },
+			output: "\rstatus 3\x1b[K\nd\x1b[K",
+		},
+		{
+			name:   "status and message",
+			do:     func() { w.StatusAndMessage("status 4", "message") },
+			output: "\x1b[1A\rstatus 4\x1b[K\x1b[J\nmessage\n",
+		},
+		{
+			name:   "status line",
+			do:     func() { w.StatusLine("status 5") },
+			output: "\rstatus 5\x1b[K",
+		},
+	}
+
+	for _, step := range steps {
+		stdout.Reset()
+		step.do()
+		if g, e := stdout.String(), step.output; g != e {
+			t.Errorf("%s: want output %q, got %q", step.name, e, g)
+		}
+	}
+}
+
+func TestStatusTableDumbTerminal(t *testing.T) {
+	w, stdout := newTestWriter(false)
+
+	w.StatusTable("status", []string{"a", "b"})
+	w.Print("message")
+
+	if g, e := stdout.String(), "status\nmessage\n"; g != e {
+		t.Errorf("want output %q, got %q", e, g)
+	}
+}
+
+func TestStatusOutputTable(t *testing.T) {
+	w, _ := newTestWriter(true)
+	s := NewStatusOutput(w, "", false, 2).(*statusOutput)
+
+	now := time.Now()
+	counts := status.Counts{TotalActions: 4}
+	actions := []*status.Action{
+		{Description: "action 1"},
+		{Command: "action 2"},
+		{Description: "action 3"},
+		{Description: "action 4"},
+	}
+	for _, action := range actions {
+		counts.StartedActions++
+		counts.RunningActions++
+		s.StartAction(action, counts)
+	}
+	for i := range s.runningActions {
+		s.runningActions[i].start = now.Add(-time.Duration(10-i) * time.Second)
+	}
+
+	if g, e := s.table(now), []string{"  10.0s action 1", "   9.0s action 2"}; !reflect.DeepEqual(g, e) {
+		t.Errorf("want table %q, got %q", e, g)
+	}
+
+	counts.RunningActions--
+	counts.FinishedActions++
+	s.FinishAction(status.ActionResult{Action: actions[0]}, counts)
+
+	if g, e := s.table(now), []string{"   9.0s action 2", "   8.0s action 3"}; !reflect.DeepEqual(g, e) {
+		t.Errorf("want table after finishing an action %q, got %q", e, g)
+	}
+
+	s.Flush()
+	if len(s.runningActions) != 0 || w.tableHeight != 0 {
+		t.Errorf("expected table to be cleared by Flush, got %d running actions and %d table lines",
+			len(s.runningActions), w.tableHeight)
+	}
+}
+
+func TestStatusOutputNoTable(t *testing.T) {
+	w, stdout := newTestWriter(true)
+	s := NewStatusOutput(w, "", false, 0)
+
+	action := &status.Action{Description: "action"}
+	s.StartAction(action, status.Counts{TotalActions: 1, StartedActions: 1, RunningActions: 1})
+
+	if g, e := stdout.String(), "\r[  0% 0/1] action\x1b[K"; g != e {
+		t.Errorf("want output %q, got %q", e, g)
+	}
+}
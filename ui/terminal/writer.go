@@ -47,6 +47,17 @@ type Writer interface {
 	// entire first line of the string will be printed.
 	StatusLine(str string)
 
+	// StatusTable prints the first line of status to the terminal like
+	// StatusLine(), followed by the first line of each string in table on
+	// the lines below it. The status line and table replace any previous
+	// status line and table, and are cleared before the next message is
+	// printed. Strings longer than the width of the terminal will be cut
+	// off, and the table is cut off to fit on the terminal.
+	//
+	// On a dumb terminal, the table is not printed and this behaves like
+	// StatusLine().
+	StatusTable(status string, table []string)
+
 	// StatusAndMessage prints the first line of status to the terminal,
 	// similarly to StatusLine(), then prints the full msg below that. The
 	// status line is retained.
@@ -93,6 +104,11 @@ type writerImpl struct {
 
 	haveBlankLine bool
 
+	// The current status line, and the number of lines of the table
+	// printed below it.
+	status      string
+	tableHeight int
+
 	// Protecting the above, we assume that smartTerminal and stripEscapes
 	// does not change after initial setup.
 	lock sync.Mutex
@@ -107,6 +123,10 @@ func (w *writerImpl) isSmartTerminal() bool {
 
 func (w *writerImpl) requestLine() {
 	if !w.haveBlankLine {
+		// Only keep the status line, not the table below it.
+		if w.tableHeight > 0 {
+			w.statusTable(w.status, nil)
+		}
 		fmt.Fprintln(w.stdio.Stdout())
 		w.haveBlankLine = true
 	}
@@ -124,7 +144,13 @@ func (w *writerImpl) Print(str string) {
 
 func (w *writerImpl) print(str string) {
 	if !w.haveBlankLine {
-		fmt.Fprint(w.stdio.Stdout(), "\r", "\x1b[K")
+		if w.tableHeight > 0 {
+			// Move up to the status line, and clear everything below it.
+			fmt.Fprintf(w.stdio.Stdout(), "\x1b[%dA\r\x1b[J", w.tableHeight)
+			w.tableHeight = 0
+		} else {
+			fmt.Fprint(w.stdio.Stdout(), "\r", "\x1b[K")
+		}
 		w.haveBlankLine = true
 	}
 	fmt.Fprint(w.stdio.Stdout(), str)
@@ -141,33 +167,74 @@ func (w *writerImpl) StatusLine(str string) {
 }
 
 func (w *writerImpl) statusLine(str string) {
+	w.statusTable(str, nil)
+}
+
+func (w *writerImpl) StatusTable(status string, table []string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.statusTable(status, table)
+}
+
+func (w *writerImpl) statusTable(status string, table []string) {
 	if !w.smartTerminal {
-		fmt.Fprintln(w.stdio.Stdout(), str)
+		fmt.Fprintln(w.stdio.Stdout(), status)
 		return
 	}
 
-	idx := strings.IndexRune(str, '\n')
-	if idx != -1 {
-		str = str[0:idx]
-	}
-
 	// Limit line width to the terminal width, otherwise we'll wrap onto
-	// another line and we won't delete the previous line.
+	// another line and we won't delete the previous line. Likewise, limit
+	// the table to the terminal height, so that we can move back up to the
+	// status line.
 	//
 	// Run this on every line in case the window has been resized while
 	// we're printing. This could be optimized to only re-run when we get
 	// SIGWINCH if it ever becomes too time consuming.
-	if max, ok := termWidth(w.stdio.Stdout()); ok {
-		if len(str) > max {
+	width, height, haveSize := termSize(w.stdio.Stdout())
+	if haveSize && len(table) >= height {
+		if height > 0 {
+			table = table[:height-1]
+		} else {
+			table = nil
+		}
+	}
+
+	fitLine := func(str string) string {
+		idx := strings.IndexRune(str, '\n')
+		if idx != -1 {
+			str = str[0:idx]
+		}
+		if haveSize && len(str) > width {
 			// TODO: Just do a max. Ninja elides the middle, but that's
 			// more complicated and these lines aren't that important.
-			str = str[:max]
+			str = str[:width]
 		}
+		return str
+	}
+
+	buf := &strings.Builder{}
+
+	// Move up from the last line of the previous table to the status line.
+	if !w.haveBlankLine && w.tableHeight > 0 {
+		fmt.Fprintf(buf, "\x1b[%dA", w.tableHeight)
 	}
 
 	// Move to the beginning on the line, print the output, then clear
 	// the rest of the line.
-	fmt.Fprint(w.stdio.Stdout(), "\r", str, "\x1b[K")
+	fmt.Fprint(buf, "\r", fitLine(status), "\x1b[K")
+	for _, line := range table {
+		fmt.Fprint(buf, "\n", fitLine(line), "\x1b[K")
+	}
+
+	// Clear the rest of the previous table if it was taller.
+	if !w.haveBlankLine && len(table) < w.tableHeight {
+		fmt.Fprint(buf, "\x1b[J")
+	}
+
+	fmt.Fprint(w.stdio.Stdout(), buf.String())
+	w.status = status
+	w.tableHeight = len(table)
 	w.haveBlankLine = false
 }
 
@@ -73,6 +73,15 @@ func (s *statusOutput) FinishAction(result status.ActionResult, counts status.Co
 		str = result.Action.Outputs[0]
 	}
 
+	var args interface{}
+	if result.Stats != (status.ActionResultStats{}) {
+		args = &statsArg{
+			UserTime:   result.Stats.UserTime,
+			SystemTime: result.Stats.SystemTime,
+			MaxRssKB:   result.Stats.MaxRssKB,
+		}
+	}
+
 	s.tracer.writeEvent(&viewerEvent{
 		Name:  str,
 		Phase: "X",
@@ -80,8 +89,68 @@ func (s *statusOutput) FinishAction(result status.ActionResult, counts status.Co
 		Dur:   uint64(time.Since(start.start).Nanoseconds()) / 1000,
 		Pid:   1,
 		Tid:   uint64(start.cpu),
+		Arg:   args,
 	})
 }
 
+type statsArg struct {
+	UserTime   uint32 `json:"user_time"`
+	SystemTime uint32 `json:"system_time"`
+	MaxRssKB   uint64 `json:"max_rss_kb"`
+}
+
 func (s *statusOutput) Flush()                                        {}
 func (s *statusOutput) Message(level status.MsgLevel, message string) {}
+
+// The process that the critical path is written to, separate from the actions
+// (pid 1) so that it gets a track of its own.
+const criticalPathPid = 2
+
+type sortIndexArg struct {
+	SortIndex int `json:"sort_index"`
+}
+
+// CriticalPath writes the actions on the critical path of the build, as found
+// by status.CriticalPath, to a highlighted track at the top of the trace.
+func (t *tracerImpl) CriticalPath(path []status.CriticalPathEntry) {
+	if len(path) == 0 {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.writeEventLocked(&viewerEvent{
+		Name:  "process_name",
+		Phase: "M",
+		Pid:   criticalPathPid,
+		Arg: &nameArg{
+			Name: "critical path",
+		},
+	})
+	t.writeEventLocked(&viewerEvent{
+		Name:  "process_sort_index",
+		Phase: "M",
+		Pid:   criticalPathPid,
+		Arg: &sortIndexArg{
+			SortIndex: -1,
+		},
+	})
+
+	for _, entry := range path {
+		str := entry.Action.Description
+		if len(entry.Action.Outputs) > 0 {
+			str = entry.Action.Outputs[0]
+		}
+
+		t.writeEventLocked(&viewerEvent{
+			Name:  str,
+			Phase: "X",
+			Time:  uint64(entry.Start.UnixNano()) / 1000,
+			Dur:   uint64(entry.Duration.Nanoseconds()) / 1000,
+			Pid:   criticalPathPid,
+			// Highlight the critical path in red.
+			Color: "terrible",
+		})
+	}
+}
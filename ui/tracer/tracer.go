@@ -49,6 +49,7 @@ type Tracer interface {
 	ImportMicrofactoryLog(filename string)
 
 	StatusTracer() status.StatusOutput
+	CriticalPath(path []status.CriticalPathEntry)
 
 	NewThread(name string) Thread
 }
@@ -76,6 +77,7 @@ type viewerEvent struct {
 	Pid   uint64      `json:"pid"`
 	Tid   uint64      `json:"tid"`
 	ID    uint64      `json:"id,omitempty"`
+	Color string      `json:"cname,omitempty"`
 	Arg   interface{} `json:"args,omitempty"`
 }
 